@@ -0,0 +1,245 @@
+package ast
+
+import "boomerang/tokens"
+
+/*
+A typed abstract syntax tree. Each construct has its own struct, so code that works with the tree is checked by the
+compiler instead of looking up parameters by name at runtime (see "indexMap" in the node package).
+
+The evaluator runs this tree, and tools that analyze code (e.g., the resolver, type checker and linter) use it as well.
+The parser builds "node.Node" trees, which "FromNodes" converts to this tree (see "parser.ParseTyped"). Values created
+while a program runs (e.g., numbers, lists and functions) are still "node.Node" trees.
+*/
+
+// Node is implemented by every node in the tree.
+type Node interface {
+	Line() int
+}
+
+// Statement is implemented by the nodes that can be used as statements. Every expression can be used as a statement.
+type Statement interface {
+	Node
+	statementNode()
+}
+
+// Expression is implemented by the nodes that produce values.
+type Expression interface {
+	Statement
+	expressionNode()
+}
+
+// StringPart is implemented by the parts of an interpolated string: text and interpolated expressions.
+type StringPart interface {
+	Node
+	stringPartNode()
+}
+
+type Position struct {
+	LineNum int
+}
+
+func (p Position) Line() int {
+	return p.LineNum
+}
+
+// Expressions
+type (
+	Number struct {
+		Position
+		Value string // Decimal value (e.g., "0xff" in the source is "255")
+	}
+
+	Boolean struct {
+		Position
+		Value bool
+	}
+
+	// A string without interpolation, or the text in an interpolated string
+	String struct {
+		Position
+		Value string
+	}
+
+	InterpolatedString struct {
+		Position
+		Parts []StringPart
+	}
+
+	// An expression in an interpolated string. The format specifier (e.g., ">8" or ".2f") may be empty.
+	Interpolation struct {
+		Position
+		Expression Expression
+		FormatSpec string
+	}
+
+	Identifier struct {
+		Position
+		Name string
+	}
+
+	BuiltinVariable struct {
+		Position
+		Name string
+	}
+
+	BuiltinFunction struct {
+		Position
+		Name string
+	}
+
+	List struct {
+		Position
+		Elements []Expression
+	}
+
+	UnaryExpression struct {
+		Position
+		Operator   tokens.Token
+		Expression Expression
+	}
+
+	// Binary operators, including function calls ("<-"), indexing ("@") and member access (".")
+	BinaryExpression struct {
+		Position
+		Left     Expression
+		Operator tokens.Token
+		Right    Expression
+	}
+
+	// Assigns a value to an identifier, or the values in a list to a list of identifiers
+	Assignment struct {
+		Position
+		Target Expression
+		Type   *TypeAnnotation // nil for assignments without a type annotation
+		Value  Expression
+	}
+
+	Function struct {
+		Position
+		Name       string // Empty for function literals
+		Parameters []*Parameter
+		ReturnType *TypeAnnotation // nil for functions without a return type
+		Body       *BlockStatements
+	}
+
+	Parameter struct {
+		Position
+		Name    *Identifier
+		Type    *TypeAnnotation // nil for parameters without a type annotation
+		Default Expression      // nil for parameters without a default value
+	}
+
+	// Calls created with "node.CreateFunctionCall". The parser creates calls as binary expressions with "<-".
+	FunctionCall struct {
+		Position
+		Function  Expression
+		Arguments []Expression
+	}
+
+	When struct {
+		Position
+		Value Expression // "true" for "when { ... }" and "false" for "when not { ... }"
+		Cases []*Case
+		Else  *BlockStatements // Empty when there is no "else"
+	}
+
+	Case struct {
+		Position
+		Value Expression
+		Body  *BlockStatements
+	}
+
+	ForLoop struct {
+		Position
+		Variables Expression
+		Values    Expression
+		Body      *BlockStatements
+	}
+)
+
+// The type in an assignment, parameter or return type (e.g., "List[Number]"). Element is nil when it is not given.
+type TypeAnnotation struct {
+	Position
+	Name    string
+	Element *TypeAnnotation
+}
+
+// Statements
+type (
+	BlockStatements struct {
+		Position
+		Statements []Statement
+	}
+
+	WhileLoop struct {
+		Position
+		Condition Expression
+		Body      *BlockStatements
+	}
+
+	Break struct {
+		Position
+	}
+
+	Continue struct {
+		Position
+	}
+
+	Return struct {
+		Position
+		Value Expression
+	}
+
+	Import struct {
+		Position
+		Path  string
+		Alias *Identifier
+	}
+
+	// Exports a named function, identifier or assignment
+	Export struct {
+		Position
+		Statement Statement
+	}
+)
+
+func (*Number) statementNode()             {}
+func (*Boolean) statementNode()            {}
+func (*String) statementNode()             {}
+func (*InterpolatedString) statementNode() {}
+func (*Identifier) statementNode()         {}
+func (*BuiltinVariable) statementNode()    {}
+func (*BuiltinFunction) statementNode()    {}
+func (*List) statementNode()               {}
+func (*UnaryExpression) statementNode()    {}
+func (*BinaryExpression) statementNode()   {}
+func (*Assignment) statementNode()         {}
+func (*Function) statementNode()           {}
+func (*FunctionCall) statementNode()       {}
+func (*When) statementNode()               {}
+func (*ForLoop) statementNode()            {}
+func (*WhileLoop) statementNode()          {}
+func (*Break) statementNode()              {}
+func (*Continue) statementNode()           {}
+func (*Return) statementNode()             {}
+func (*Import) statementNode()             {}
+func (*Export) statementNode()             {}
+
+func (*Number) expressionNode()             {}
+func (*Boolean) expressionNode()            {}
+func (*String) expressionNode()             {}
+func (*InterpolatedString) expressionNode() {}
+func (*Identifier) expressionNode()         {}
+func (*BuiltinVariable) expressionNode()    {}
+func (*BuiltinFunction) expressionNode()    {}
+func (*List) expressionNode()               {}
+func (*UnaryExpression) expressionNode()    {}
+func (*BinaryExpression) expressionNode()   {}
+func (*Assignment) expressionNode()         {}
+func (*Function) expressionNode()           {}
+func (*FunctionCall) expressionNode()       {}
+func (*When) expressionNode()               {}
+func (*ForLoop) expressionNode()            {}
+
+func (*String) stringPartNode()        {}
+func (*Interpolation) stringPartNode() {}
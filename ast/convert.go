@@ -0,0 +1,461 @@
+package ast
+
+import (
+	"boomerang/node"
+	"boomerang/tokens"
+	"fmt"
+)
+
+/*
+Conversions between this tree and "node.Node" trees. Converting a tree from the parser and converting it back creates
+the same "node.Node" tree. Runtime values that cannot be written in source code (e.g., monads and maps) are not part
+of the AST and cannot be converted.
+*/
+
+// FromNodes converts the statements created by the parser.
+func FromNodes(nodes []node.Node) ([]Statement, error) {
+	statements := []Statement{}
+	for _, n := range nodes {
+		statement, err := FromNode(n)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+// FromNode converts a statement or expression created by the parser.
+func FromNode(n node.Node) (Statement, error) {
+	position := Position{LineNum: n.LineNum}
+
+	switch n.Type {
+	case node.NUMBER:
+		return &Number{Position: position, Value: n.Value}, nil
+
+	case node.BOOLEAN:
+		return &Boolean{Position: position, Value: n.Value == tokens.TRUE_TOKEN.Literal}, nil
+
+	case node.STRING:
+		if len(n.Params) == 0 {
+			return &String{Position: position, Value: n.Value}, nil
+		}
+
+		parts := []StringPart{}
+		for _, param := range n.Params {
+			part, err := stringPartFromNode(param)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
+		}
+		return &InterpolatedString{Position: position, Parts: parts}, nil
+
+	case node.IDENTIFIER:
+		return &Identifier{Position: position, Name: n.Value}, nil
+
+	case node.BUILTIN_VARIABLE:
+		return &BuiltinVariable{Position: position, Name: n.Value}, nil
+
+	case node.BUILTIN_FUNCTION:
+		return &BuiltinFunction{Position: position, Name: n.Value}, nil
+
+	case node.LIST:
+		elements, err := expressionsFromNodes(n.Params)
+		if err != nil {
+			return nil, err
+		}
+		return &List{Position: position, Elements: elements}, nil
+
+	case node.UNARY_EXPR:
+		expression, err := expressionFromNode(n.GetParam(node.EXPR))
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpression{Position: position, Operator: tokenFromNode(n.GetParam(node.OPERATOR)), Expression: expression}, nil
+
+	case node.BIN_EXPR:
+		left, err := expressionFromNode(n.GetParam(node.LEFT))
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := expressionFromNode(n.GetParam(node.RIGHT))
+		if err != nil {
+			return nil, err
+		}
+		return &BinaryExpression{Position: position, Left: left, Operator: tokenFromNode(n.GetParam(node.OPERATOR)), Right: right}, nil
+
+	case node.ASSIGN_STMT:
+		target, value, err := assignmentFromNode(n)
+		if err != nil {
+			return nil, err
+		}
+		identifier := n.GetParam(node.ASSIGN_STMT_IDENTIFIER)
+		annotation := typeAnnotationFromNode(identifier.GetTypeAnnotation())
+		return &Assignment{Position: position, Target: target, Type: annotation, Value: value}, nil
+
+	case node.FUNCTION:
+		return functionFromNode(n)
+
+	case node.FUNCTION_CALL:
+		function, err := expressionFromNode(n.GetParam(node.FUNCTION))
+		if err != nil {
+			return nil, err
+		}
+
+		arguments, err := expressionsFromNodes(n.GetParam(node.CALL_PARAMS).Params)
+		if err != nil {
+			return nil, err
+		}
+		return &FunctionCall{Position: position, Function: function, Arguments: arguments}, nil
+
+	case node.WHEN:
+		return whenFromNode(n)
+
+	case node.FOR_LOOP:
+		variables, values, err := assignmentFromNode(n.GetParam(node.FOR_LOOP_ELEM_ASSIGN))
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := blockFromNode(n.GetParam(node.BLOCK_STATEMENTS))
+		if err != nil {
+			return nil, err
+		}
+		return &ForLoop{Position: position, Variables: variables, Values: values, Body: body}, nil
+
+	case node.BLOCK_STATEMENTS:
+		return nil, fmt.Errorf("block statements can only be converted as part of another node")
+
+	case node.WHILE_LOOP:
+		condition, err := expressionFromNode(n.GetParam(node.WHILE_LOOP_CONDITION))
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := blockFromNode(n.GetParam(node.WHILE_LOOP_STATEMENTS))
+		if err != nil {
+			return nil, err
+		}
+		return &WhileLoop{Position: position, Condition: condition, Body: body}, nil
+
+	case node.BREAK:
+		return &Break{Position: position}, nil
+
+	case node.CONTINUE:
+		return &Continue{Position: position}, nil
+
+	case node.RETURN:
+		value, err := expressionFromNode(n.GetParam(node.EXPR))
+		if err != nil {
+			return nil, err
+		}
+		return &Return{Position: position, Value: value}, nil
+
+	case node.IMPORT:
+		alias := n.GetParam(node.IDENTIFIER)
+		return &Import{Position: position, Path: n.Value, Alias: identifierFromNode(alias)}, nil
+
+	case node.EXPORT:
+		statement, err := FromNode(n.GetParam(node.EXPR))
+		if err != nil {
+			return nil, err
+		}
+		return &Export{Position: position, Statement: statement}, nil
+	}
+
+	return nil, fmt.Errorf("cannot convert %s to an AST node", n.Type)
+}
+
+func expressionFromNode(n node.Node) (Expression, error) {
+	statement, err := FromNode(n)
+	if err != nil {
+		return nil, err
+	}
+
+	expression, ok := statement.(Expression)
+	if !ok {
+		return nil, fmt.Errorf("expected an expression, got %s", n.Type)
+	}
+	return expression, nil
+}
+
+func expressionsFromNodes(nodes []node.Node) ([]Expression, error) {
+	expressions := []Expression{}
+	for _, n := range nodes {
+		expression, err := expressionFromNode(n)
+		if err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, expression)
+	}
+	return expressions, nil
+}
+
+func stringPartFromNode(n node.Node) (StringPart, error) {
+	if n.Type == node.STRING && len(n.Params) == 0 {
+		return &String{Position: Position{LineNum: n.LineNum}, Value: n.Value}, nil
+	}
+
+	if n.Type == node.INTERPOLATION {
+		expression, err := expressionFromNode(n.GetParam(node.EXPR))
+		if err != nil {
+			return nil, err
+		}
+		return &Interpolation{Position: Position{LineNum: n.LineNum}, Expression: expression, FormatSpec: n.Value}, nil
+	}
+
+	return nil, fmt.Errorf("invalid part of interpolated string: %s", n.Type)
+}
+
+func tokenFromNode(n node.Node) tokens.Token {
+	// Operators are stored as nodes created from their tokens (see "node.CreateTokenNode")
+	return tokens.Token{Type: n.Type, Literal: n.Value, LineNumber: n.LineNum}
+}
+
+func identifierFromNode(n node.Node) *Identifier {
+	return &Identifier{Position: Position{LineNum: n.LineNum}, Name: n.Value}
+}
+
+func typeAnnotationFromNode(n *node.Node) *TypeAnnotation {
+	// Returns nil for nodes without a type annotation
+	if n == nil {
+		return nil
+	}
+
+	var element *node.Node
+	if len(n.Params) > 0 {
+		element = &n.Params[0]
+	}
+	return &TypeAnnotation{Position: Position{LineNum: n.LineNum}, Name: n.Value, Element: typeAnnotationFromNode(element)}
+}
+
+func assignmentFromNode(n node.Node) (Expression, Expression, error) {
+	target, err := expressionFromNode(n.GetParam(node.ASSIGN_STMT_IDENTIFIER))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	value, err := expressionFromNode(n.GetParam(node.EXPR))
+	if err != nil {
+		return nil, nil, err
+	}
+	return target, value, nil
+}
+
+func blockFromNode(n node.Node) (*BlockStatements, error) {
+	if n.Type != node.BLOCK_STATEMENTS {
+		return nil, fmt.Errorf("expected block statements, got %s", n.Type)
+	}
+
+	statements, err := FromNodes(n.Params)
+	if err != nil {
+		return nil, err
+	}
+	return &BlockStatements{Position: Position{LineNum: n.LineNum}, Statements: statements}, nil
+}
+
+func functionFromNode(n node.Node) (*Function, error) {
+	// Parameters are identifiers, or assignments for parameters with default values
+	parameters := []*Parameter{}
+	for _, param := range n.GetParam(node.LIST).Params {
+		parameter := &Parameter{Position: Position{LineNum: param.LineNum}}
+
+		switch param.Type {
+		case node.IDENTIFIER:
+			parameter.Name = identifierFromNode(param)
+			parameter.Type = typeAnnotationFromNode(param.GetTypeAnnotation())
+
+		case node.ASSIGN_STMT:
+			defaultValue, err := expressionFromNode(param.GetParam(node.EXPR))
+			if err != nil {
+				return nil, err
+			}
+			identifier := param.GetParam(node.ASSIGN_STMT_IDENTIFIER)
+			parameter.Name = identifierFromNode(identifier)
+			parameter.Type = typeAnnotationFromNode(identifier.GetTypeAnnotation())
+			parameter.Default = defaultValue
+
+		default:
+			return nil, fmt.Errorf("invalid function parameter: %s", param.Type)
+		}
+		parameters = append(parameters, parameter)
+	}
+
+	body, err := blockFromNode(n.GetParam(node.STMTS))
+	if err != nil {
+		return nil, err
+	}
+	return &Function{
+		Position:   Position{LineNum: n.LineNum},
+		Name:       n.Value,
+		Parameters: parameters,
+		ReturnType: typeAnnotationFromNode(n.GetTypeAnnotation()),
+		Body:       body,
+	}, nil
+}
+
+func whenFromNode(n node.Node) (*When, error) {
+	value, err := expressionFromNode(n.GetParam(node.WHEN_VALUE))
+	if err != nil {
+		return nil, err
+	}
+
+	cases := []*Case{}
+	for _, caseNode := range n.GetParam(node.WHEN_CASES).Params {
+		caseValue, err := expressionFromNode(caseNode.GetParam(node.CASE_VALUE))
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := blockFromNode(caseNode.GetParam(node.CASE_STMTS))
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, &Case{Position: Position{LineNum: caseNode.LineNum}, Value: caseValue, Body: body})
+	}
+
+	elseBody, err := blockFromNode(n.GetParam(node.WHEN_CASES_DEFAULT))
+	if err != nil {
+		return nil, err
+	}
+	return &When{Position: Position{LineNum: n.LineNum}, Value: value, Cases: cases, Else: elseBody}, nil
+}
+
+// ToNodes converts statements to "node.Node" trees (e.g., to print them or save them as JSON).
+func ToNodes(statements []Statement) []node.Node {
+	nodes := []node.Node{}
+	for _, statement := range statements {
+		nodes = append(nodes, ToNode(statement))
+	}
+	return nodes
+}
+
+// ToNode converts a node to a "node.Node" tree.
+func ToNode(n Node) node.Node {
+	switch n := n.(type) {
+	case *Number:
+		return node.CreateNumber(n.LineNum, n.Value)
+
+	case *Boolean:
+		if n.Value {
+			return node.CreateBooleanTrue(n.LineNum)
+		}
+		return node.CreateBooleanFalse(n.LineNum)
+
+	case *String:
+		return node.CreateRawString(n.LineNum, n.Value)
+
+	case *InterpolatedString:
+		parts := []node.Node{}
+		for _, part := range n.Parts {
+			parts = append(parts, ToNode(part))
+		}
+		return node.CreateInterpolatedString(n.LineNum, parts)
+
+	case *Interpolation:
+		return node.CreateInterpolation(n.LineNum, ToNode(n.Expression), n.FormatSpec)
+
+	case *Identifier:
+		return node.CreateIdentifier(n.LineNum, n.Name)
+
+	case *BuiltinVariable:
+		return node.CreateBuiltinVariableIdentifier(n.LineNum, n.Name)
+
+	case *BuiltinFunction:
+		return node.CreateBuiltinFunctionIdentifier(n.LineNum, n.Name)
+
+	case *List:
+		return node.CreateList(n.LineNum, expressionsToNodes(n.Elements))
+
+	case *UnaryExpression:
+		return node.CreateUnaryExpression(n.Operator, ToNode(n.Expression))
+
+	case *BinaryExpression:
+		return node.CreateBinaryExpression(ToNode(n.Left), n.Operator, ToNode(n.Right))
+
+	case *Assignment:
+		return node.CreateAssignmentNode(annotatedToNode(n.Target, n.Type), ToNode(n.Value))
+
+	case *Function:
+		parameters := []node.Node{}
+		for _, parameter := range n.Parameters {
+			parameters = append(parameters, ToNode(parameter))
+		}
+		function := node.CreateNamedFunction(n.LineNum, n.Name, parameters, ToNode(n.Body))
+		if n.ReturnType != nil {
+			function = node.CreateAnnotatedFunction(function, ToNode(n.ReturnType))
+		}
+		return function
+
+	case *Parameter:
+		if n.Default == nil {
+			return annotatedToNode(n.Name, n.Type)
+		}
+		return node.CreateAssignmentNode(annotatedToNode(n.Name, n.Type), ToNode(n.Default))
+
+	case *TypeAnnotation:
+		if n.Element == nil {
+			return node.CreateTypeAnnotation(n.LineNum, n.Name, nil)
+		}
+		return node.CreateTypeAnnotation(n.LineNum, n.Name, ToNode(n.Element).Ptr())
+
+	case *FunctionCall:
+		return node.CreateFunctionCall(n.LineNum, ToNode(n.Function), expressionsToNodes(n.Arguments))
+
+	case *When:
+		cases := []node.Node{}
+		for _, whenCase := range n.Cases {
+			cases = append(cases, ToNode(whenCase))
+		}
+		return node.CreateWhenNode(n.LineNum, ToNode(n.Value), cases, ToNode(n.Else))
+
+	case *Case:
+		return node.CreateCaseNode(n.LineNum, ToNode(n.Value), ToNode(n.Body))
+
+	case *ForLoop:
+		assignment := node.CreateAssignmentNode(ToNode(n.Variables), ToNode(n.Values))
+		return node.CreateForLoop(n.LineNum, assignment, ToNode(n.Body))
+
+	case *BlockStatements:
+		block := node.CreateBlockStatements(ToNodes(n.Statements))
+		block.LineNum = n.LineNum
+		return block
+
+	case *WhileLoop:
+		return node.CreateWhileLoop(n.LineNum, ToNode(n.Condition), ToNode(n.Body))
+
+	case *Break:
+		return node.CreateBreakStatement(n.LineNum)
+
+	case *Continue:
+		return node.CreateContinueStatement(n.LineNum)
+
+	case *Return:
+		return node.CreateReturnStatement(n.LineNum, ToNode(n.Value))
+
+	case *Import:
+		return node.CreateImportStatement(n.LineNum, n.Path, ToNode(n.Alias))
+
+	case *Export:
+		return node.CreateExportStatement(n.LineNum, ToNode(n.Statement))
+	}
+
+	panic(fmt.Sprintf("invalid AST node: %T", n))
+}
+
+func annotatedToNode(target Expression, annotation *TypeAnnotation) node.Node {
+	if annotation == nil {
+		return ToNode(target)
+	}
+	return node.CreateAnnotatedIdentifier(ToNode(target), ToNode(annotation))
+}
+
+func expressionsToNodes(expressions []Expression) []node.Node {
+	nodes := []node.Node{}
+	for _, expression := range expressions {
+		nodes = append(nodes, ToNode(expression))
+	}
+	return nodes
+}
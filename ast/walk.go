@@ -0,0 +1,132 @@
+package ast
+
+/*
+A Visitor's Visit method is called for each node found by Walk. If the returned visitor is not nil, Walk visits each
+of the node's children with it, followed by a call of Visit(nil).
+*/
+type Visitor interface {
+	Visit(n Node) Visitor
+}
+
+// Walk traverses a tree in depth-first order, visiting children in the order they appear in the source.
+func Walk(v Visitor, n Node) {
+	if v = v.Visit(n); v == nil {
+		return
+	}
+
+	for _, child := range Children(n) {
+		Walk(v, child)
+	}
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(n Node) Visitor {
+	if f(n) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses a tree like Walk, calling f for each node and f(nil) after a node's children. The children of a
+// node are skipped if f returns false.
+func Inspect(n Node, f func(Node) bool) {
+	Walk(inspector(f), n)
+}
+
+// Children returns the direct children of a node, in the order they appear in the source.
+func Children(n Node) []Node {
+	children := []Node{}
+	add := func(nodes ...Node) {
+		children = append(children, nodes...)
+	}
+
+	switch n := n.(type) {
+	case *InterpolatedString:
+		for _, part := range n.Parts {
+			add(part)
+		}
+
+	case *Interpolation:
+		add(n.Expression)
+
+	case *List:
+		for _, element := range n.Elements {
+			add(element)
+		}
+
+	case *UnaryExpression:
+		add(n.Expression)
+
+	case *BinaryExpression:
+		add(n.Left, n.Right)
+
+	case *Assignment:
+		add(n.Target)
+		if n.Type != nil {
+			add(n.Type)
+		}
+		add(n.Value)
+
+	case *Function:
+		for _, parameter := range n.Parameters {
+			add(parameter)
+		}
+		if n.ReturnType != nil {
+			add(n.ReturnType)
+		}
+		add(n.Body)
+
+	case *Parameter:
+		add(n.Name)
+		if n.Type != nil {
+			add(n.Type)
+		}
+		if n.Default != nil {
+			add(n.Default)
+		}
+
+	case *TypeAnnotation:
+		if n.Element != nil {
+			add(n.Element)
+		}
+
+	case *FunctionCall:
+		add(n.Function)
+		for _, argument := range n.Arguments {
+			add(argument)
+		}
+
+	case *When:
+		add(n.Value)
+		for _, whenCase := range n.Cases {
+			add(whenCase)
+		}
+		add(n.Else)
+
+	case *Case:
+		add(n.Value, n.Body)
+
+	case *ForLoop:
+		add(n.Variables, n.Values, n.Body)
+
+	case *BlockStatements:
+		for _, statement := range n.Statements {
+			add(statement)
+		}
+
+	case *WhileLoop:
+		add(n.Condition, n.Body)
+
+	case *Return:
+		add(n.Value)
+
+	case *Import:
+		add(n.Alias)
+
+	case *Export:
+		add(n.Statement)
+	}
+	return children
+}
@@ -0,0 +1,261 @@
+package cst
+
+import (
+	"boomerang/node"
+	"boomerang/parser"
+	"boomerang/tokens"
+	"sort"
+	"strings"
+)
+
+/*
+A concrete syntax tree (CST) keeps every character of the source code, so tools can change code without losing its
+layout or comments. Whitespace and comments are stored as trivia on the tokens next to them, and the text of the
+tree is the original source, byte for byte.
+
+Tokens are the leaves of the tree. Other nodes are the statements and expressions recognized by the parser, and their
+kinds are the types of the AST nodes they create (e.g., "BinaryExpression"), "Statement" for a statement and its
+semicolon, "GroupedExpression" for an expression between parentheses, or "Program" for the root.
+*/
+const (
+	PROGRAM = "Program"
+	TOKEN   = "Token"
+
+	// Trivia kinds
+	WHITESPACE     = "Whitespace" // Spaces, tabs and carriage returns
+	NEWLINE        = "Newline"
+	INLINE_COMMENT = "InlineComment"
+	BLOCK_COMMENT  = "BlockComment"
+)
+
+type Trivia struct {
+	Kind string
+	Text string
+}
+
+/*
+Token is a token with its source text and the trivia around it. Trailing trivia is everything after the token on the
+same line. Leading trivia is everything else before the token, starting on the line after the previous token.
+*/
+type Token struct {
+	tokens.Token
+	Text     string // Text of the token in the source (e.g., a string token includes its quotes)
+	Leading  []Trivia
+	Trailing []Trivia
+}
+
+type Node struct {
+	Kind     string
+	Token    *Token // Only set for tokens
+	Children []*Node
+}
+
+// Parse creates a concrete syntax tree from source code. The last token in the tree is the EOF token.
+func Parse(source string) (*Node, error) {
+	tokenizer := tokens.NewTokenizer(source)
+	allTokens, err := tokenizer.All()
+	if err != nil {
+		return nil, err
+	}
+
+	parserObj, err := parser.NewParserFromTokens(&tokenList{tokens: allTokens})
+	if err != nil {
+		return nil, err
+	}
+
+	_, spans, err := parserObj.ParseSyntax()
+	if err != nil {
+		return nil, err
+	}
+
+	return buildTree(createTokens(source, allTokens), spans), nil
+}
+
+func createTokens(source string, allTokens []tokens.Token) []*Token {
+	// Split the text between each pair of tokens into the trailing trivia of the first and leading trivia of the second
+	cstTokens := []*Token{}
+	previousEnd := 0
+
+	for i, token := range allTokens {
+		trivia := splitTrivia(source[previousEnd:token.Offset])
+
+		leading := trivia
+		if i > 0 {
+			previous := cstTokens[i-1]
+			for len(leading) > 0 && leading[0].Kind != NEWLINE {
+				previous.Trailing = append(previous.Trailing, leading[0])
+				leading = leading[1:]
+			}
+		}
+
+		cstTokens = append(cstTokens, &Token{Token: token, Text: source[token.Offset:token.End], Leading: leading})
+		previousEnd = token.End
+	}
+	return cstTokens
+}
+
+func splitTrivia(text string) []Trivia {
+	// Comments are found the same way as in the tokenizer, where "##" starts a block comment and "#" an inline comment
+	trivia := []Trivia{}
+	for len(text) > 0 {
+		var kind string
+		var length int
+
+		switch {
+		case text[0] == '\n':
+			kind, length = NEWLINE, 1
+
+		case strings.HasPrefix(text, "##"):
+			kind, length = BLOCK_COMMENT, len(text)
+			if end := strings.Index(text[2:], "##"); end != -1 {
+				length = end + 4
+			}
+
+		case text[0] == '#':
+			kind, length = INLINE_COMMENT, len(text)
+			if end := strings.IndexByte(text, '\n'); end != -1 {
+				length = end
+			}
+
+		default:
+			kind = WHITESPACE
+			for length < len(text) && strings.IndexByte(" \t\r", text[length]) != -1 {
+				length += 1
+			}
+			if length == 0 {
+				// The tokenizer only skips whitespace and comments, so this is not expected, but avoids an infinite loop
+				length = 1
+			}
+		}
+
+		trivia = append(trivia, Trivia{Kind: kind, Text: text[:length]})
+		text = text[length:]
+	}
+	return trivia
+}
+
+func buildTree(allTokens []*Token, spans []parser.SyntaxSpan) *Node {
+	/*
+		Spans are sorted so each span comes after the spans containing it. When two spans cover the same tokens, the
+		span recorded last contains the other one (the parser records a span when it finishes parsing it).
+	*/
+	order := make([]int, len(spans))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := spans[order[i]], spans[order[j]]
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		if a.End != b.End {
+			return a.End > b.End
+		}
+		return order[i] > order[j]
+	})
+
+	root := &Node{Kind: PROGRAM}
+	openNodes := []*Node{root}
+	openEnds := []int{len(allTokens)}
+	position := 0
+
+	// Close the nodes that end before the current token. The root contains every token, so it is never closed.
+	closeNodes := func() {
+		for len(openNodes) > 1 && openEnds[len(openEnds)-1] <= position {
+			openNodes, openEnds = openNodes[:len(openNodes)-1], openEnds[:len(openEnds)-1]
+		}
+	}
+
+	// Add tokens to the innermost node containing them
+	addTokensUntil := func(end int) {
+		for ; position < end; position++ {
+			closeNodes()
+			parent := openNodes[len(openNodes)-1]
+			parent.Children = append(parent.Children, &Node{Kind: TOKEN, Token: allTokens[position]})
+		}
+		closeNodes()
+	}
+
+	for _, i := range order {
+		span := spans[i]
+		addTokensUntil(span.Start)
+
+		syntaxNode := &Node{Kind: span.Kind}
+		parent := openNodes[len(openNodes)-1]
+		parent.Children = append(parent.Children, syntaxNode)
+		openNodes, openEnds = append(openNodes, syntaxNode), append(openEnds, span.End)
+	}
+	addTokensUntil(len(allTokens))
+
+	return root
+}
+
+// Text returns the source code of the node, including all of its trivia.
+func (n *Node) Text() string {
+	var builder strings.Builder
+	for _, token := range n.Tokens() {
+		for _, trivia := range token.Leading {
+			builder.WriteString(trivia.Text)
+		}
+		builder.WriteString(token.Text)
+		for _, trivia := range token.Trailing {
+			builder.WriteString(trivia.Text)
+		}
+	}
+	return builder.String()
+}
+
+// Tokens returns the tokens in the node, in the order they appear in the source.
+func (n *Node) Tokens() []*Token {
+	if n.Token != nil {
+		return []*Token{n.Token}
+	}
+
+	nodeTokens := []*Token{}
+	for _, child := range n.Children {
+		nodeTokens = append(nodeTokens, child.Tokens()...)
+	}
+	return nodeTokens
+}
+
+/*
+Lower creates the AST for a tree by parsing its tokens, so it can be evaluated. Trivia and the "Text" of tokens are
+ignored, so a token that is changed must have its "Literal" and "Type" changed as well.
+*/
+func Lower(root *Node) ([]node.Node, error) {
+	allTokens := []tokens.Token{}
+	for _, token := range root.Tokens() {
+		allTokens = append(allTokens, token.Token)
+	}
+
+	parserObj, err := parser.NewParserFromTokens(&tokenList{tokens: allTokens})
+	if err != nil {
+		return nil, err
+	}
+
+	statements, err := parserObj.Parse()
+	if err != nil {
+		return nil, err
+	}
+	return *statements, nil
+}
+
+// A token source for the parser that returns the tokens in a list, followed by EOF tokens
+type tokenList struct {
+	tokens   []tokens.Token
+	position int
+}
+
+func (l *tokenList) Next() (*tokens.Token, error) {
+	if l.position >= len(l.tokens) {
+		eof := tokens.EOF_TOKEN
+		if len(l.tokens) > 0 {
+			eof.LineNumber = l.tokens[len(l.tokens)-1].LineNumber
+		}
+		return &eof, nil
+	}
+
+	token := l.tokens[l.position]
+	l.position += 1
+	return &token, nil
+}
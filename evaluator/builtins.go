@@ -5,7 +5,6 @@ import (
 	"boomerang/utils"
 	"fmt"
 	"math"
-	"math/rand"
 )
 
 const (
@@ -15,7 +14,6 @@ const (
 	BUILTIN_SLICE      = "slice"
 	BUILTIN_UNWRAP_ALL = "unwrap_all"
 	BUILTIN_RANGE      = "range"
-	BUILTIN_RANDOM     = "random"
 	BUILTIN_PRINT      = "print"
 	BUILTIN_INPUT      = "input"
 	BUILTIN_SUCCESS    = "is_success"
@@ -49,7 +47,6 @@ func init() {
 		BUILTIN_UNWRAP_ALL: {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinUnwrapAll},
 		BUILTIN_SLICE:      {Type: node.BUILTIN_FUNCTION, NumArgs: 3, Function: evaluateBuiltinSlice},
 		BUILTIN_RANGE:      {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinRange},
-		BUILTIN_RANDOM:     {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinRandom},
 		BUILTIN_PRINT:      {Type: node.BUILTIN_FUNCTION, NumArgs: nArgsValue, Function: evaluateBuiltinPrint},
 		BUILTIN_INPUT:      {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinInput},
 		BUILTIN_SUCCESS:    {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinSuccess},
@@ -58,6 +55,46 @@ func init() {
 		// Variables
 		BUILTIN_PI: {Type: node.BUILTIN_VARIABLE, NumArgs: 0, Function: evaluateBuiltinPi},
 	}
+
+	for name, builtin := range getStringBuiltins() {
+		builtins[name] = builtin
+	}
+
+	for name, builtin := range getListBuiltins() {
+		builtins[name] = builtin
+	}
+
+	for name, builtin := range getMathBuiltins() {
+		builtins[name] = builtin
+	}
+
+	for name, builtin := range getRandomBuiltins() {
+		builtins[name] = builtin
+	}
+
+	for name, builtin := range getMapBuiltins() {
+		builtins[name] = builtin
+	}
+
+	for name, builtin := range getJSONBuiltins() {
+		builtins[name] = builtin
+	}
+
+	for name, builtin := range getFileBuiltins() {
+		builtins[name] = builtin
+	}
+
+	for name, builtin := range getProcessBuiltins() {
+		builtins[name] = builtin
+	}
+
+	for name, builtin := range getTimeBuiltins() {
+		builtins[name] = builtin
+	}
+
+	for name, builtin := range getRegexBuiltins() {
+		builtins[name] = builtin
+	}
 }
 
 func IsBuiltinOfType(builtinType string, value string) bool {
@@ -85,6 +122,13 @@ func GetBuiltinNames() []string {
 	return builtinNames
 }
 
+func createBoolean(lineNum int, value bool) node.Node {
+	if value {
+		return node.CreateBooleanTrue(lineNum)
+	}
+	return node.CreateBooleanFalse(lineNum)
+}
+
 /* * * * * * * * * * *
  * BUILTIN VARIABLES *
  * * * * * * * * * * */
@@ -123,10 +167,7 @@ func evaluateBuiltinFunction(name string, eval *evaluator, lineNum int, callPara
 
 func evaluateBuiltinSlice(eval *evaluator, lineNum int, callParam []node.Node) (*node.Node, error) {
 
-	collection, err := eval.evaluateExpression(callParam[0])
-	if err != nil {
-		return nil, err
-	}
+	collection := callParam[0]
 
 	// Get the length of the collection based on the type. This is for verifying the indices are not out of range
 	var collectionLength int
@@ -134,13 +175,14 @@ func evaluateBuiltinSlice(eval *evaluator, lineNum int, callParam []node.Node) (
 	case node.LIST:
 		collectionLength = len(collection.Params)
 	case node.STRING:
-		collectionLength = len(collection.Value)
+		// Strings are sliced by character, not byte, so multi-byte characters are not split
+		collectionLength = len([]rune(collection.Value))
 	default:
 		return nil, utils.CreateError(collection.LineNum, "invalid type for slice: %s", collection.ErrorDisplay())
 	}
 
 	// Start Index
-	startIndex, err := eval.evaluateAndCheckType(callParam[1], node.NUMBER)
+	startIndex, err := checkType(callParam[1], node.NUMBER)
 	if err != nil {
 		return nil, err
 	}
@@ -156,7 +198,7 @@ func evaluateBuiltinSlice(eval *evaluator, lineNum int, callParam []node.Node) (
 	}
 
 	// End Index
-	endIndex, err := eval.evaluateAndCheckType(callParam[2], node.NUMBER)
+	endIndex, err := checkType(callParam[2], node.NUMBER)
 	if err != nil {
 		return nil, err
 	}
@@ -189,8 +231,8 @@ func evaluateBuiltinSlice(eval *evaluator, lineNum int, callParam []node.Node) (
 		returnNode = node.CreateList(collection.LineNum, slicedList)
 
 	case node.STRING:
-		listValues := collection.Value
-		slicedString := listValues[startLiteral : endLiteral+1]
+		characters := []rune(collection.Value)
+		slicedString := string(characters[startLiteral : endLiteral+1])
 		returnNode = node.CreateRawString(collection.LineNum, slicedString)
 	}
 
@@ -206,10 +248,7 @@ func evaluateBuiltinUnwrap(eval *evaluator, lineNum int, callParameters []node.N
 		callParameters[0] contains the monad returned by the function ("Monad{<VALUE>}" or "Monad{}")
 		callParameters[1] contains the default value, if the function returns "(false)"
 	*/
-	returnValueList, err := eval.evaluateExpression(callParameters[0])
-	if err != nil {
-		return nil, err
-	}
+	returnValueList := callParameters[0]
 
 	// Check that the first value passed to "unwrap" is a monad
 	if err := utils.CheckTypeError(lineNum, returnValueList.Type, node.MONAD); err != nil {
@@ -218,11 +257,11 @@ func evaluateBuiltinUnwrap(eval *evaluator, lineNum int, callParameters []node.N
 
 	// If the monad contains a value, return that value
 	if len(returnValueList.Params) == 1 {
-		return eval.evaluateExpression(returnValueList.Params[0])
+		return returnValueList.Params[0].Ptr(), nil
 	}
 
 	// if the monad contains no value, return the default value given to "unwrap".
-	return eval.evaluateExpression(callParameters[1])
+	return callParameters[1].Ptr(), nil
 }
 
 func evaluateBuiltinUnwrapAll(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
@@ -243,16 +282,13 @@ func evaluateBuiltinUnwrapAll(eval *evaluator, lineNum int, callParameters []nod
 		However, in the example above, because "unwrap" always returns a valid value, the return value would always be
 		"(true, newList)". So, I decided "unwrap_all" should be a builtin method that just returns the list of values.
 	*/
-	list, err := eval.evaluateExpression(callParameters[0])
-	if err != nil {
-		return nil, err
-	}
+	list := callParameters[0]
 
 	if err := utils.CheckTypeError(lineNum, list.Type, node.LIST); err != nil {
 		return nil, err
 	}
 
-	defaultValue := callParameters[1] // will be evaluated in "evaluateBuiltinUnwrap"
+	defaultValue := callParameters[1]
 
 	unwrappedList := []node.Node{}
 
@@ -275,19 +311,12 @@ func evaluateBuiltinUnwrapAll(eval *evaluator, lineNum int, callParameters []nod
 
 func evaluateBuiltinLen(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
 
-	value, err := eval.evaluateExpression(callParameters[0])
-	if err != nil {
-		return nil, err
-	}
-	return value.Length()
+	return callParameters[0].Length()
 }
 
 func evaluateBuiltinRange(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
 
-	startNumber, err := eval.evaluateExpression(callParameters[0])
-	if err != nil {
-		return nil, err
-	}
+	startNumber := callParameters[0]
 
 	if err := utils.CheckTypeError(lineNum, startNumber.Type, node.NUMBER); err != nil {
 		return nil, err
@@ -298,10 +327,7 @@ func evaluateBuiltinRange(eval *evaluator, lineNum int, callParameters []node.No
 		return nil, utils.CreateError(lineNum, "start value must be an integer")
 	}
 
-	endNumber, err := eval.evaluateExpression(callParameters[1])
-	if err != nil {
-		return nil, err
-	}
+	endNumber := callParameters[1]
 
 	if err := utils.CheckTypeError(lineNum, endNumber.Type, node.NUMBER); err != nil {
 		return nil, err
@@ -334,60 +360,12 @@ func evaluateBuiltinRange(eval *evaluator, lineNum int, callParameters []node.No
 	return node.CreateList(lineNum, numbersNodeValues).Ptr(), nil
 }
 
-func evaluateBuiltinRandom(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
-	minNumber, err := eval.evaluateExpression(callParameters[0])
-	if err != nil {
-		return nil, err
-	}
-
-	if err := utils.CheckTypeError(lineNum, minNumber.Type, node.NUMBER); err != nil {
-		return nil, err
-	}
-
-	minValue := utils.ConvertStringToInteger(minNumber.Value)
-	if minValue == nil {
-		return nil, utils.CreateError(lineNum, "min value must be an integer")
-	}
-
-	maxNumber, err := eval.evaluateExpression(callParameters[1])
-	if err != nil {
-		return nil, err
-	}
-
-	if err := utils.CheckTypeError(lineNum, maxNumber.Type, node.NUMBER); err != nil {
-		return nil, err
-	}
-
-	maxValue := utils.ConvertStringToInteger(maxNumber.Value)
-	if maxValue == nil {
-		return nil, utils.CreateError(lineNum, "max value must be an integer")
-	}
-
-	if *minValue > *maxValue {
-		return nil, utils.CreateError(
-			minNumber.LineNum,
-			"the minimum number, %d, cannot be greater than the maximum number, %d",
-			*minValue,
-			*maxValue,
-		)
-	}
-
-	// "+ 1" ensures the generated number includes the maximum value
-	randomValue := rand.Intn(*maxValue-*minValue+1) + *minValue
-	return node.CreateNumber(minNumber.LineNum, utils.IntToString(randomValue)).Ptr(), nil
-}
-
 func evaluateBuiltinPrint(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
 	for i, value := range callParameters {
-		evaluatedParam, err := eval.evaluateExpression(value)
-		if err != nil {
-			return nil, err
-		}
-
 		if i < len(callParameters)-1 {
-			fmt.Printf("%s ", evaluatedParam.String())
+			fmt.Printf("%s ", value.String())
 		} else {
-			fmt.Println(evaluatedParam.String())
+			fmt.Println(value.String())
 		}
 	}
 	return node.CreateBlockStatementReturnValue(lineNum, nil).Ptr(), nil
@@ -395,10 +373,7 @@ func evaluateBuiltinPrint(eval *evaluator, lineNum int, callParameters []node.No
 
 func evaluateBuiltinInput(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
 
-	prompt, err := eval.evaluateExpression(callParameters[0])
-	if err != nil {
-		return nil, err
-	}
+	prompt := callParameters[0]
 
 	if err := utils.CheckTypeError(lineNum, prompt.Type, node.STRING); err != nil {
 		return nil, err
@@ -0,0 +1,318 @@
+package evaluator
+
+import (
+	"boomerang/node"
+	"boomerang/utils"
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/*
+Filesystem builtins. Files can only be accessed in the evaluator's root directory (see "SetFileRoot") and its
+subdirectories. Filesystem access is disabled until a root directory is set.
+
+Relative paths are relative to the root directory. Paths outside the root directory, including paths that leave it
+through symbolic links, cause an error. Removing a link only removes the link, so its target can be outside the root
+directory. Other failures (e.g., a file that does not exist) return an empty monad, so they can be checked with
+"is_success".
+*/
+const (
+	BUILTIN_READ_FILE   = "read_file"
+	BUILTIN_READ_LINES  = "read_lines"
+	BUILTIN_WRITE_FILE  = "write_file"
+	BUILTIN_APPEND_FILE = "append_file"
+	BUILTIN_LIST_DIR    = "list_dir"
+	BUILTIN_FILE_EXISTS = "file_exists"
+	BUILTIN_REMOVE_FILE = "remove_file"
+)
+
+const (
+	maxLineLength = 16 * 1024 * 1024 // Longest line "read_lines" can read
+	maxSymlinks   = 255              // Most links followed when resolving a path, so links that form a loop are not followed forever
+)
+
+func getFileBuiltins() map[string]Builtin {
+	return map[string]Builtin{
+		BUILTIN_READ_FILE:   {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinReadFile},
+		BUILTIN_READ_LINES:  {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinReadLines},
+		BUILTIN_WRITE_FILE:  {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinWriteFile},
+		BUILTIN_APPEND_FILE: {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinAppendFile},
+		BUILTIN_LIST_DIR:    {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinListDir},
+		BUILTIN_FILE_EXISTS: {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinFileExists},
+		BUILTIN_REMOVE_FILE: {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinRemoveFile},
+	}
+}
+
+// SetFileRoot allows the filesystem builtins to access files in "root" and its subdirectories.
+func (e *evaluator) SetFileRoot(root string) error {
+	absoluteRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+
+	// Symbolic links are resolved so paths can be compared to the root after their links are resolved
+	resolvedRoot, err := filepath.EvalSymlinks(absoluteRoot)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(resolvedRoot)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("file root %#v is not a directory", root)
+	}
+
+	e.fileRoot = resolvedRoot
+	return nil
+}
+
+func (e *evaluator) evaluatePath(lineNum int, param node.Node) (string, error) {
+	// Evaluate a path argument and return its absolute path, checking that it is in the root directory
+	path, err := e.evaluateAbsolutePath(lineNum, param)
+	if err != nil {
+		return "", err
+	}
+	return e.checkPath(lineNum, param.Value, resolveSymlinks(path))
+}
+
+func (e *evaluator) evaluateLinkPath(lineNum int, param node.Node) (string, error) {
+	/*
+		Evaluate a path argument like "evaluatePath", but only resolve symbolic links in the directories containing the
+		file. If the file is a link, the path of the link is returned instead of the path of its target, so the link
+		itself can be removed.
+	*/
+	path, err := e.evaluateAbsolutePath(lineNum, param)
+	if err != nil {
+		return "", err
+	}
+
+	// The root directory has no parent in the root directory
+	if path == e.fileRoot {
+		return path, nil
+	}
+
+	directory, err := e.checkPath(lineNum, param.Value, resolveSymlinks(filepath.Dir(path)))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(directory, filepath.Base(path)), nil
+}
+
+func (e *evaluator) evaluateAbsolutePath(lineNum int, param node.Node) (string, error) {
+	// Return the absolute path of a path argument, without resolving symbolic links
+	path, err := checkType(param, node.STRING)
+	if err != nil {
+		return "", err
+	}
+
+	if e.fileRoot == "" {
+		return "", utils.CreateError(lineNum, "filesystem access is disabled")
+	}
+
+	fullPath := path.Value
+	if !filepath.IsAbs(fullPath) {
+		fullPath = filepath.Join(e.fileRoot, fullPath)
+	}
+	return filepath.Clean(fullPath), nil
+}
+
+func (e *evaluator) checkPath(lineNum int, path string, resolvedPath string) (string, error) {
+	// Check that a path is in the root directory after its symbolic links are resolved
+	relativePath, err := filepath.Rel(e.fileRoot, resolvedPath)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return "", utils.CreateError(lineNum, "path %#v is outside the allowed directory", path)
+	}
+	return resolvedPath, nil
+}
+
+func resolveSymlinks(path string) string {
+	/*
+		Resolve symbolic links in the part of the path that exists. Files that are about to be created do not exist yet,
+		so the rest of the path is added back after the links are resolved. Links whose targets do not exist are
+		replaced with their targets, because creating a file through the link creates the target.
+	*/
+	missing := []string{}
+	for links := 0; ; {
+		if resolvedPath, err := filepath.EvalSymlinks(path); err == nil {
+			return filepath.Join(append([]string{resolvedPath}, missing...)...)
+		}
+
+		if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 && links < maxSymlinks {
+			if target, err := os.Readlink(path); err == nil {
+				if !filepath.IsAbs(target) {
+					target = filepath.Join(filepath.Dir(path), target)
+				}
+				path = filepath.Clean(target)
+				links += 1
+				continue
+			}
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(append([]string{path}, missing...)...)
+		}
+		missing = append([]string{filepath.Base(path)}, missing...)
+		path = parent
+	}
+}
+
+func evaluateBuiltinReadFile(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	path, err := eval.evaluatePath(lineNum, callParameters[0])
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return node.CreateMonad(lineNum, nil).Ptr(), nil
+	}
+	return node.CreateMonad(lineNum, node.CreateRawString(lineNum, string(contents)).Ptr()).Ptr(), nil
+}
+
+func evaluateBuiltinReadLines(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	/*
+		Call a function with each line in a file, without its line ending. Lines are read one at a time as the function
+		is called, so a large file is never held in memory. The function returns "true" to read the next line or "false"
+		to stop reading. Returns a monad containing the number of lines passed to the function.
+	*/
+	path, err := eval.evaluatePath(lineNum, callParameters[0])
+	if err != nil {
+		return nil, err
+	}
+
+	function, err := checkFunctionArgument(callParameters[1])
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return node.CreateMonad(lineNum, nil).Ptr(), nil
+	}
+	defer file.Close()
+
+	numLines := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxLineLength)
+	for scanner.Scan() {
+		numLines += 1
+
+		line := node.CreateRawString(lineNum, scanner.Text())
+		keepReading, err := eval.callPredicate(lineNum, BUILTIN_READ_LINES, *function, line)
+		if err != nil {
+			return nil, err
+		}
+		if !keepReading {
+			break
+		}
+	}
+
+	if err := scanner.Err(); errors.Is(err, bufio.ErrTooLong) {
+		return nil, utils.CreateError(lineNum, "lines read with %#v cannot be longer than %d bytes", BUILTIN_READ_LINES, maxLineLength)
+	} else if err != nil {
+		return node.CreateMonad(lineNum, nil).Ptr(), nil
+	}
+	return node.CreateMonad(lineNum, node.CreateNumber(lineNum, utils.IntToString(numLines)).Ptr()).Ptr(), nil
+}
+
+func evaluateBuiltinWriteFile(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Create or replace a file
+	return eval.writeFile(lineNum, callParameters, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+}
+
+func evaluateBuiltinAppendFile(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Add text to the end of a file, creating the file if it does not exist
+	return eval.writeFile(lineNum, callParameters, os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+}
+
+func (e *evaluator) writeFile(lineNum int, callParameters []node.Node, flags int) (*node.Node, error) {
+	// Return a monad containing the number of bytes written, or an empty monad if the file could not be written
+	path, err := e.evaluatePath(lineNum, callParameters[0])
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := checkType(callParameters[1], node.STRING)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return node.CreateMonad(lineNum, nil).Ptr(), nil
+	}
+	defer file.Close()
+
+	numBytes, err := file.WriteString(text.Value)
+	if err != nil {
+		return node.CreateMonad(lineNum, nil).Ptr(), nil
+	}
+	return node.CreateMonad(lineNum, node.CreateNumber(lineNum, utils.IntToString(numBytes)).Ptr()).Ptr(), nil
+}
+
+func evaluateBuiltinListDir(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Return a monad containing the sorted names of the files and directories in a directory
+	path, err := eval.evaluatePath(lineNum, callParameters[0])
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return node.CreateMonad(lineNum, nil).Ptr(), nil
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	nameNodes := []node.Node{}
+	for _, name := range names {
+		nameNodes = append(nameNodes, node.CreateRawString(lineNum, name))
+	}
+	return node.CreateMonad(lineNum, node.CreateList(lineNum, nameNodes).Ptr()).Ptr(), nil
+}
+
+func evaluateBuiltinFileExists(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// True for both files and directories
+	path, err := eval.evaluatePath(lineNum, callParameters[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return node.CreateBooleanFalse(lineNum).Ptr(), nil
+	}
+	return node.CreateBooleanTrue(lineNum).Ptr(), nil
+}
+
+func evaluateBuiltinRemoveFile(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	/*
+		Return a monad containing "true" if the file was removed. Directories cannot be removed. Symbolic links are
+		removed instead of their targets, so removing a link to a file outside the root directory is allowed.
+	*/
+	path, err := eval.evaluateLinkPath(lineNum, callParameters[0])
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil || info.IsDir() {
+		return node.CreateMonad(lineNum, nil).Ptr(), nil
+	}
+
+	if err := os.Remove(path); err != nil {
+		return node.CreateMonad(lineNum, nil).Ptr(), nil
+	}
+	return node.CreateMonad(lineNum, node.CreateBooleanTrue(lineNum).Ptr()).Ptr(), nil
+}
@@ -0,0 +1,246 @@
+package evaluator
+
+import (
+	"boomerang/node"
+	"boomerang/utils"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strings"
+)
+
+/*
+JSON builtins. JSON values are converted to and from Boomerang values as follows:
+  - object: Map
+  - array: List
+  - number: Number
+  - string: String
+  - true/false: Boolean
+  - null: Monad{}
+
+When converting to JSON, a monad with a value is converted to its value.
+*/
+const (
+	BUILTIN_JSON_PARSE     = "json_parse"
+	BUILTIN_JSON_STRINGIFY = "json_stringify"
+
+	// Larger indents would make the output mostly spaces, so they are errors
+	MAX_JSON_INDENT = 16
+)
+
+func getJSONBuiltins() map[string]Builtin {
+	return map[string]Builtin{
+		BUILTIN_JSON_PARSE:     {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinJSONParse},
+		BUILTIN_JSON_STRINGIFY: {Type: node.BUILTIN_FUNCTION, NumArgs: nArgsValue, Function: evaluateBuiltinJSONStringify},
+	}
+}
+
+func evaluateBuiltinJSONParse(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	source, err := checkType(callParameters[0], node.STRING)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(source.Value))
+	decoder.UseNumber()
+
+	value, err := parseJSONValue(lineNum, source.Value, decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	// The source must contain exactly one JSON value
+	offset := decoder.InputOffset()
+	if _, err := decoder.Token(); err != io.EOF {
+		if err != nil {
+			return nil, createJSONError(lineNum, source.Value, decoder, err)
+		}
+		offset += int64(len(source.Value[offset:]) - len(strings.TrimLeft(source.Value[offset:], " \t\r\n")))
+		return nil, utils.CreateError(lineNum, "invalid JSON at offset %d: unexpected data after JSON value", offset)
+	}
+	return value, nil
+}
+
+func parseJSONValue(lineNum int, source string, decoder *json.Decoder) (*node.Node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, createJSONError(lineNum, source, decoder, err)
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		if value == '{' {
+			jsonMap := node.CreateMap(lineNum, []node.Node{})
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, createJSONError(lineNum, source, decoder, err)
+				}
+
+				element, err := parseJSONValue(lineNum, source, decoder)
+				if err != nil {
+					return nil, err
+				}
+
+				// If a key appears more than once, the last value is used
+				jsonMap = jsonMap.SetMapValue(key.(string), *element)
+			}
+
+			// Closing brace
+			if _, err := decoder.Token(); err != nil {
+				return nil, createJSONError(lineNum, source, decoder, err)
+			}
+			return &jsonMap, nil
+		}
+
+		list := []node.Node{}
+		for decoder.More() {
+			element, err := parseJSONValue(lineNum, source, decoder)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, *element)
+		}
+
+		// Closing bracket
+		if _, err := decoder.Token(); err != nil {
+			return nil, createJSONError(lineNum, source, decoder, err)
+		}
+		return node.CreateList(lineNum, list).Ptr(), nil
+
+	case json.Number:
+		number := utils.ConvertStringToFloat(value.String())
+		if number == nil {
+			return nil, utils.CreateError(lineNum, "invalid JSON at offset %d: number %s is out of range", decoder.InputOffset(), value)
+		}
+		return createNumber(lineNum, *number).Ptr(), nil
+
+	case string:
+		return node.CreateRawString(lineNum, value).Ptr(), nil
+
+	case bool:
+		if value {
+			return node.CreateBooleanTrue(lineNum).Ptr(), nil
+		}
+		return node.CreateBooleanFalse(lineNum).Ptr(), nil
+	}
+
+	// null
+	return node.CreateMonad(lineNum, nil).Ptr(), nil
+}
+
+func createJSONError(lineNum int, source string, decoder *json.Decoder, err error) error {
+	// Include the byte offset in the JSON source where the error occurred
+	var syntaxError *json.SyntaxError
+	if errors.As(err, &syntaxError) {
+		return utils.CreateError(lineNum, "invalid JSON at offset %d: %s", syntaxError.Offset, syntaxError.Error())
+	}
+
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return utils.CreateError(lineNum, "invalid JSON at offset %d: unexpected end of JSON input", len(source))
+	}
+	return utils.CreateError(lineNum, "invalid JSON at offset %d: %s", decoder.InputOffset(), err.Error())
+}
+
+func evaluateBuiltinJSONStringify(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// The optional second argument is the number of spaces to indent nested values by. 0 (the default) is compact.
+	if err := checkNumArgsInRange(lineNum, callParameters, 1, 2); err != nil {
+		return nil, err
+	}
+
+	value := callParameters[0]
+
+	indent := 0
+	if len(callParameters) == 2 {
+		values, err := eval.evaluateIntegers(lineNum, callParameters[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		indent = values[0]
+		if indent < 0 || indent > MAX_JSON_INDENT {
+			return nil, utils.CreateError(lineNum, "indent must be between 0 and %d, got %d", MAX_JSON_INDENT, indent)
+		}
+	}
+
+	buffer := bytes.Buffer{}
+	if err := writeJSONValue(&buffer, value); err != nil {
+		return nil, err
+	}
+
+	if indent > 0 {
+		indented := bytes.Buffer{}
+		if err := json.Indent(&indented, buffer.Bytes(), "", strings.Repeat(" ", indent)); err != nil {
+			return nil, utils.CreateError(lineNum, err.Error())
+		}
+		buffer = indented
+	}
+	return node.CreateRawString(lineNum, buffer.String()).Ptr(), nil
+}
+
+func writeJSONValue(buffer *bytes.Buffer, value node.Node) error {
+	switch value.Type {
+	case node.MAP:
+		buffer.WriteString("{")
+		for i, entry := range value.Params {
+			if i > 0 {
+				buffer.WriteString(",")
+			}
+			writeJSONString(buffer, entry.GetParam(node.MAP_KEY).Value)
+			buffer.WriteString(":")
+			if err := writeJSONValue(buffer, entry.GetParam(node.MAP_VALUE)); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString("}")
+
+	case node.LIST:
+		buffer.WriteString("[")
+		for i, element := range value.Params {
+			if i > 0 {
+				buffer.WriteString(",")
+			}
+			if err := writeJSONValue(buffer, element); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString("]")
+
+	case node.NUMBER:
+		// JSON does not support NaN or infinity
+		number := utils.ConvertStringToFloat(value.Value)
+		if number == nil || math.IsNaN(*number) || math.IsInf(*number, 0) {
+			return utils.CreateError(value.LineNum, "cannot convert %s to JSON", value.ErrorDisplay())
+		}
+		buffer.WriteString(value.Value)
+
+	case node.STRING:
+		writeJSONString(buffer, value.Value)
+
+	case node.BOOLEAN:
+		buffer.WriteString(value.Value)
+
+	case node.MONAD:
+		if len(value.Params) == 0 {
+			buffer.WriteString("null")
+			return nil
+		}
+		return writeJSONValue(buffer, value.GetParam(node.MONAD_VALUE))
+
+	default:
+		return utils.CreateError(value.LineNum, "cannot convert %s to JSON", value.ErrorDisplay())
+	}
+	return nil
+}
+
+func writeJSONString(buffer *bytes.Buffer, value string) {
+	// Characters like "<" and "&" are not escaped because the output is not meant to be embedded in HTML
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+
+	// "Encode" adds a newline after the value
+	buffer.Truncate(buffer.Len() - 1)
+}
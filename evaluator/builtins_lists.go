@@ -0,0 +1,605 @@
+package evaluator
+
+import (
+	"boomerang/node"
+	"boomerang/tokens"
+	"boomerang/utils"
+	"sort"
+	"strings"
+)
+
+/*
+List builtins. Builtins that take a function accept both user-defined functions and builtin functions. User-defined
+functions return a monad, so their return values are unwrapped before they are used (e.g., "lists.map" returns a list
+of values, not a list of monads). User-defined functions that do not return a value cause an error.
+
+Like "math", the value of "lists" is a module. List functions are stored in "builtins" with the "lists." prefix (e.g.,
+"lists.map"), so common names like "sum" and "find" can still be used as identifiers.
+*/
+const (
+	BUILTIN_LISTS = "lists"
+
+	LISTS_MAP      = "map"
+	LISTS_FILTER   = "filter"
+	LISTS_REDUCE   = "reduce"
+	LISTS_FOLD     = "fold"
+	LISTS_SORT     = "sort"
+	LISTS_REVERSE  = "reverse"
+	LISTS_ZIP      = "zip"
+	LISTS_FLATTEN  = "flatten"
+	LISTS_UNIQUE   = "unique"
+	LISTS_ANY      = "any"
+	LISTS_ALL      = "all"
+	LISTS_SUM      = "sum"
+	LISTS_MIN      = "min"
+	LISTS_MAX      = "max"
+	LISTS_FIND     = "find"
+	LISTS_GROUP_BY = "group_by"
+)
+
+func getListFunctions() map[string]Builtin {
+	return map[string]Builtin{
+		LISTS_MAP:      {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinMap},
+		LISTS_FILTER:   {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinFilter},
+		LISTS_REDUCE:   {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinReduce},
+		LISTS_FOLD:     {Type: node.BUILTIN_FUNCTION, NumArgs: 3, Function: evaluateBuiltinFold},
+		LISTS_SORT:     {Type: node.BUILTIN_FUNCTION, NumArgs: nArgsValue, Function: evaluateBuiltinSort},
+		LISTS_REVERSE:  {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinReverse},
+		LISTS_ZIP:      {Type: node.BUILTIN_FUNCTION, NumArgs: nArgsValue, Function: evaluateBuiltinZip},
+		LISTS_FLATTEN:  {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinFlatten},
+		LISTS_UNIQUE:   {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinUnique},
+		LISTS_ANY:      {Type: node.BUILTIN_FUNCTION, NumArgs: nArgsValue, Function: evaluateBuiltinAny},
+		LISTS_ALL:      {Type: node.BUILTIN_FUNCTION, NumArgs: nArgsValue, Function: evaluateBuiltinAll},
+		LISTS_SUM:      {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinSum},
+		LISTS_MIN:      {Type: node.BUILTIN_FUNCTION, NumArgs: nArgsValue, Function: evaluateBuiltinMin},
+		LISTS_MAX:      {Type: node.BUILTIN_FUNCTION, NumArgs: nArgsValue, Function: evaluateBuiltinMax},
+		LISTS_FIND:     {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinFind},
+		LISTS_GROUP_BY: {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinGroupBy},
+	}
+}
+
+func getListBuiltins() map[string]Builtin {
+	listBuiltins := map[string]Builtin{
+		BUILTIN_LISTS: {Type: node.BUILTIN_VARIABLE, NumArgs: 0, Function: evaluateBuiltinLists},
+	}
+
+	for name, builtin := range getListFunctions() {
+		listBuiltins[getListBuiltinName(name)] = builtin
+	}
+	return listBuiltins
+}
+
+func getListBuiltinName(name string) string {
+	return BUILTIN_LISTS + "." + name
+}
+
+func evaluateBuiltinLists(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// The members are sorted by name so the module is the same every time it is created
+	names := []string{}
+	for name := range getListFunctions() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	members := []node.Node{}
+	for _, name := range names {
+		value := node.CreateBuiltinFunctionIdentifier(lineNum, getListBuiltinName(name))
+		members = append(members, node.CreateAssignmentNode(node.CreateIdentifier(lineNum, name), value))
+	}
+
+	return node.CreateModule(lineNum, BUILTIN_LISTS, members).Ptr(), nil
+}
+
+func checkNumArgsInRange(lineNum int, callParameters []node.Node, minArgs int, maxArgs int) error {
+	// For builtins with optional arguments, which have "nArgsValue" as their number of arguments
+	if len(callParameters) < minArgs || len(callParameters) > maxArgs {
+		return utils.CreateError(
+			lineNum,
+			"incorrect number of arguments. expected %d to %d, got %d",
+			minArgs,
+			maxArgs,
+			len(callParameters),
+		)
+	}
+	return nil
+}
+
+func checkFunctionArgument(function node.Node) (*node.Node, error) {
+	// Check that a call parameter is a user-defined function or a builtin function
+	if function.Type != node.FUNCTION && function.Type != node.BUILTIN_FUNCTION {
+		return nil, utils.CreateError(
+			function.LineNum,
+			"expected %s or %s, got %s",
+			node.FUNCTION,
+			node.BUILTIN_FUNCTION,
+			function.Type,
+		)
+	}
+	return &function, nil
+}
+
+func (e *evaluator) callFunction(lineNum int, builtinName string, function node.Node, args []node.Node) (*node.Node, error) {
+	/*
+		Call a function passed to a builtin with already-evaluated arguments. The monad returned by user-defined
+		functions is unwrapped, so the return value can be used directly.
+	*/
+	if function.Type == node.BUILTIN_FUNCTION {
+		return evaluateBuiltinFunction(function.Value, e, lineNum, args)
+	}
+
+	returnValue, err := e.evaluateCall(function, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(returnValue.Params) == 0 {
+		return nil, utils.CreateError(lineNum, "function passed to %#v did not return a value", builtinName)
+	}
+	return &returnValue.Params[0], nil
+}
+
+func (e *evaluator) callPredicate(lineNum int, builtinName string, function node.Node, value node.Node) (bool, error) {
+	// Call a function that must return a boolean
+	result, err := e.callFunction(lineNum, builtinName, function, []node.Node{value})
+	if err != nil {
+		return false, err
+	}
+
+	if err := utils.CheckTypeError(lineNum, result.Type, node.BOOLEAN); err != nil {
+		return false, err
+	}
+	return result.Value == node.CreateBooleanTrue(lineNum).Value, nil
+}
+
+func (e *evaluator) evaluateListAndFunction(callParameters []node.Node) (*node.Node, *node.Node, error) {
+	// Most list builtins take a list followed by a function
+	list, err := checkType(callParameters[0], node.LIST)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	function, err := checkFunctionArgument(callParameters[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	return list, function, nil
+}
+
+func compareValues(lineNum int, left node.Node, right node.Node) (int, error) {
+	/*
+		Return a negative number if "left" comes before "right", 0 if they are equal, and a positive number if "left"
+		comes after "right". Numbers, strings and booleans (false before true) can be compared, but only with values
+		of the same type.
+	*/
+	if left.Type != right.Type {
+		return 0, utils.CreateError(lineNum, "cannot compare types %s and %s", left.Type, right.Type)
+	}
+
+	switch left.Type {
+	case node.NUMBER:
+		leftNumber := *utils.ConvertStringToFloat(left.Value)
+		rightNumber := *utils.ConvertStringToFloat(right.Value)
+		if leftNumber < rightNumber {
+			return -1, nil
+		} else if leftNumber > rightNumber {
+			return 1, nil
+		}
+		return 0, nil
+
+	case node.STRING, node.BOOLEAN:
+		// "false" comes before "true" alphabetically
+		return strings.Compare(left.Value, right.Value), nil
+	}
+
+	return 0, utils.CreateError(lineNum, "cannot compare values of type %s", left.Type)
+}
+
+func evaluateBuiltinMap(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	list, function, err := eval.evaluateListAndFunction(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	mappedList := []node.Node{}
+	for _, element := range list.Params {
+		value, err := eval.callFunction(lineNum, getListBuiltinName(LISTS_MAP), *function, []node.Node{element})
+		if err != nil {
+			return nil, err
+		}
+		mappedList = append(mappedList, *value)
+	}
+	return node.CreateList(lineNum, mappedList).Ptr(), nil
+}
+
+func evaluateBuiltinFilter(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	list, function, err := eval.evaluateListAndFunction(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	filteredList := []node.Node{}
+	for _, element := range list.Params {
+		keep, err := eval.callPredicate(lineNum, getListBuiltinName(LISTS_FILTER), *function, element)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			filteredList = append(filteredList, element)
+		}
+	}
+	return node.CreateList(lineNum, filteredList).Ptr(), nil
+}
+
+func evaluateBuiltinReduce(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Like "fold", but the first element is used as the initial value. The list must not be empty.
+	list, function, err := eval.evaluateListAndFunction(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list.Params) == 0 {
+		return nil, utils.CreateError(lineNum, "cannot reduce an empty list")
+	}
+
+	return eval.fold(lineNum, getListBuiltinName(LISTS_REDUCE), list.Params[1:], *function, list.Params[0])
+}
+
+func evaluateBuiltinFold(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	list, function, err := eval.evaluateListAndFunction(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return eval.fold(lineNum, getListBuiltinName(LISTS_FOLD), list.Params, *function, callParameters[2])
+}
+
+func (e *evaluator) fold(lineNum int, builtinName string, elements []node.Node, function node.Node, initialValue node.Node) (*node.Node, error) {
+	// Call the function with the accumulated value and each element, using the result as the next accumulated value
+	accumulator := initialValue
+	for _, element := range elements {
+		value, err := e.callFunction(lineNum, builtinName, function, []node.Node{accumulator, element})
+		if err != nil {
+			return nil, err
+		}
+		accumulator = *value
+	}
+	return &accumulator, nil
+}
+
+func evaluateBuiltinSort(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	/*
+		Return a new list sorted in ascending order. If a key function is given, elements are sorted by the result of
+		calling the key function on each element. The sort is stable, so equal elements keep their original order.
+	*/
+	if err := checkNumArgsInRange(lineNum, callParameters, 1, 2); err != nil {
+		return nil, err
+	}
+
+	list, err := checkType(callParameters[0], node.LIST)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := eval.evaluateKeys(lineNum, getListBuiltinName(LISTS_SORT), list.Params, callParameters[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]int, len(list.Params))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	var compareErr error
+	sort.SliceStable(indices, func(i, j int) bool {
+		result, err := compareValues(lineNum, keys[indices[i]], keys[indices[j]])
+		if err != nil && compareErr == nil {
+			compareErr = err
+		}
+		return result < 0
+	})
+	if compareErr != nil {
+		return nil, compareErr
+	}
+
+	sortedList := []node.Node{}
+	for _, index := range indices {
+		sortedList = append(sortedList, list.Params[index])
+	}
+	return node.CreateList(lineNum, sortedList).Ptr(), nil
+}
+
+func (e *evaluator) evaluateKeys(lineNum int, builtinName string, elements []node.Node, keyFunctionParam []node.Node) ([]node.Node, error) {
+	// Return the result of the optional key function for each element, or the elements themselves if there is no key function
+	if len(keyFunctionParam) == 0 {
+		return elements, nil
+	}
+
+	keyFunction, err := checkFunctionArgument(keyFunctionParam[0])
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []node.Node{}
+	for _, element := range elements {
+		key, err := e.callFunction(lineNum, builtinName, *keyFunction, []node.Node{element})
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, nil
+}
+
+func evaluateBuiltinReverse(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Lists and strings can be reversed. Strings are reversed by character, not byte.
+	value := callParameters[0]
+
+	switch value.Type {
+	case node.LIST:
+		reversedList := []node.Node{}
+		for i := len(value.Params) - 1; i >= 0; i-- {
+			reversedList = append(reversedList, value.Params[i])
+		}
+		return node.CreateList(lineNum, reversedList).Ptr(), nil
+
+	case node.STRING:
+		characters := []rune(value.Value)
+		for i, j := 0, len(characters)-1; i < j; i, j = i+1, j-1 {
+			characters[i], characters[j] = characters[j], characters[i]
+		}
+		return node.CreateRawString(lineNum, string(characters)).Ptr(), nil
+	}
+
+	return nil, utils.CreateError(lineNum, "expected %s or %s, got %s", node.LIST, node.STRING, value.Type)
+}
+
+func evaluateBuiltinZip(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Combine the elements at the same positions in each list. The result is as long as the shortest list.
+	if len(callParameters) < 2 {
+		return nil, utils.CreateError(lineNum, "incorrect number of arguments. expected at least 2, got %d", len(callParameters))
+	}
+
+	lists := []node.Node{}
+	shortestLength := -1
+	for _, param := range callParameters {
+		list, err := checkType(param, node.LIST)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, *list)
+
+		if shortestLength == -1 || len(list.Params) < shortestLength {
+			shortestLength = len(list.Params)
+		}
+	}
+
+	zippedList := []node.Node{}
+	for i := 0; i < shortestLength; i++ {
+		elements := []node.Node{}
+		for _, list := range lists {
+			elements = append(elements, list.Params[i])
+		}
+		zippedList = append(zippedList, node.CreateList(lineNum, elements))
+	}
+	return node.CreateList(lineNum, zippedList).Ptr(), nil
+}
+
+func evaluateBuiltinFlatten(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Flatten one level of nested lists. Elements that are not lists are kept as-is.
+	list, err := checkType(callParameters[0], node.LIST)
+	if err != nil {
+		return nil, err
+	}
+
+	flattenedList := []node.Node{}
+	for _, element := range list.Params {
+		if element.Type == node.LIST {
+			flattenedList = append(flattenedList, element.Params...)
+		} else {
+			flattenedList = append(flattenedList, element)
+		}
+	}
+	return node.CreateList(lineNum, flattenedList).Ptr(), nil
+}
+
+func evaluateBuiltinUnique(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Remove duplicate elements, keeping the first occurrence of each
+	list, err := checkType(callParameters[0], node.LIST)
+	if err != nil {
+		return nil, err
+	}
+
+	uniqueList := []node.Node{}
+	for _, element := range list.Params {
+		if !containsNode(uniqueList, element) {
+			uniqueList = append(uniqueList, element)
+		}
+	}
+	return node.CreateList(lineNum, uniqueList).Ptr(), nil
+}
+
+func containsNode(nodes []node.Node, value node.Node) bool {
+	for _, n := range nodes {
+		if n.Equals(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func evaluateBuiltinAny(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// True if any element is true, or if the optional function returns true for any element
+	return eval.evaluateAnyAll(lineNum, getListBuiltinName(LISTS_ANY), callParameters, true)
+}
+
+func evaluateBuiltinAll(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// True if every element is true, or if the optional function returns true for every element
+	return eval.evaluateAnyAll(lineNum, getListBuiltinName(LISTS_ALL), callParameters, false)
+}
+
+func (e *evaluator) evaluateAnyAll(lineNum int, builtinName string, callParameters []node.Node, isAny bool) (*node.Node, error) {
+	/*
+		"any" stops at the first true value and "all" stops at the first false value, so the function is not called
+		for the remaining elements.
+	*/
+	if err := checkNumArgsInRange(lineNum, callParameters, 1, 2); err != nil {
+		return nil, err
+	}
+
+	list, err := checkType(callParameters[0], node.LIST)
+	if err != nil {
+		return nil, err
+	}
+
+	var function *node.Node
+	if len(callParameters) == 2 {
+		function, err = checkFunctionArgument(callParameters[1])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, element := range list.Params {
+		var value bool
+		if function != nil {
+			value, err = e.callPredicate(lineNum, builtinName, *function, element)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			if err := utils.CheckTypeError(lineNum, element.Type, node.BOOLEAN); err != nil {
+				return nil, err
+			}
+			value = element.Value == node.CreateBooleanTrue(lineNum).Value
+		}
+
+		if value == isAny {
+			return createBoolean(lineNum, isAny).Ptr(), nil
+		}
+	}
+	return createBoolean(lineNum, !isAny).Ptr(), nil
+}
+
+func evaluateBuiltinSum(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// The sum of an empty list is 0
+	list, err := checkType(callParameters[0], node.LIST)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := 0.0
+	for _, element := range list.Params {
+		if err := utils.CheckTypeError(lineNum, element.Type, node.NUMBER); err != nil {
+			return nil, err
+		}
+		sum += *utils.ConvertStringToFloat(element.Value)
+	}
+	return node.CreateNumber(lineNum, tokens.FormatNumber(sum)).Ptr(), nil
+}
+
+func evaluateBuiltinMin(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	return eval.evaluateMinMax(lineNum, getListBuiltinName(LISTS_MIN), callParameters, -1)
+}
+
+func evaluateBuiltinMax(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	return eval.evaluateMinMax(lineNum, getListBuiltinName(LISTS_MAX), callParameters, 1)
+}
+
+func (e *evaluator) evaluateMinMax(lineNum int, builtinName string, callParameters []node.Node, direction int) (*node.Node, error) {
+	/*
+		Return the smallest ("direction" is -1) or largest ("direction" is 1) element, comparing the results of the
+		optional key function if one is given. The first element is returned when there are ties.
+	*/
+	if err := checkNumArgsInRange(lineNum, callParameters, 1, 2); err != nil {
+		return nil, err
+	}
+
+	list, err := checkType(callParameters[0], node.LIST)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list.Params) == 0 {
+		return nil, utils.CreateError(lineNum, "%s requires a non-empty list", builtinName)
+	}
+
+	keys, err := e.evaluateKeys(lineNum, builtinName, list.Params, callParameters[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	bestIndex := 0
+	for i := 1; i < len(keys); i++ {
+		result, err := compareValues(lineNum, keys[i], keys[bestIndex])
+		if err != nil {
+			return nil, err
+		}
+		if result*direction > 0 {
+			bestIndex = i
+		}
+	}
+	return &list.Params[bestIndex], nil
+}
+
+func evaluateBuiltinFind(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Return a monad containing the first element the function returns true for, or an empty monad if there is none
+	list, function, err := eval.evaluateListAndFunction(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, element := range list.Params {
+		found, err := eval.callPredicate(lineNum, getListBuiltinName(LISTS_FIND), *function, element)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return node.CreateMonad(lineNum, &element).Ptr(), nil
+		}
+	}
+	return node.CreateMonad(lineNum, nil).Ptr(), nil
+}
+
+func evaluateBuiltinGroupBy(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	/*
+		Group elements by the result of the key function. Returns a list of (key, elements) lists, ordered by the first
+		appearance of each key. For example, grouping (1, 2, 3, 4) by whether each number is even returns
+		((false, (1, 3)), (true, (2, 4))).
+	*/
+	list, function, err := eval.evaluateListAndFunction(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []node.Node{}
+	groups := [][]node.Node{}
+	for _, element := range list.Params {
+		key, err := eval.callFunction(lineNum, getListBuiltinName(LISTS_GROUP_BY), *function, []node.Node{element})
+		if err != nil {
+			return nil, err
+		}
+
+		groupIndex := -1
+		for i, existingKey := range keys {
+			if existingKey.Equals(*key) {
+				groupIndex = i
+				break
+			}
+		}
+
+		if groupIndex == -1 {
+			keys = append(keys, *key)
+			groups = append(groups, []node.Node{})
+			groupIndex = len(keys) - 1
+		}
+		groups[groupIndex] = append(groups[groupIndex], element)
+	}
+
+	groupedList := []node.Node{}
+	for i, key := range keys {
+		group := node.CreateList(lineNum, []node.Node{key, node.CreateList(lineNum, groups[i])})
+		groupedList = append(groupedList, group)
+	}
+	return node.CreateList(lineNum, groupedList).Ptr(), nil
+}
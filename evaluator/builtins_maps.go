@@ -0,0 +1,84 @@
+package evaluator
+
+import (
+	"boomerang/node"
+)
+
+/*
+Map builtins. Maps are created by "json_parse" and "map_set", and values can be looked up with "@" (e.g.,
+'config @ "name"'). Maps are never changed in place; "map_set" returns a new map.
+*/
+const (
+	BUILTIN_MAP_GET    = "map_get"
+	BUILTIN_MAP_SET    = "map_set"
+	BUILTIN_MAP_KEYS   = "map_keys"
+	BUILTIN_MAP_VALUES = "map_values"
+)
+
+func getMapBuiltins() map[string]Builtin {
+	return map[string]Builtin{
+		BUILTIN_MAP_GET:    {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinMapGet},
+		BUILTIN_MAP_SET:    {Type: node.BUILTIN_FUNCTION, NumArgs: 3, Function: evaluateBuiltinMapSet},
+		BUILTIN_MAP_KEYS:   {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinMapKeys},
+		BUILTIN_MAP_VALUES: {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinMapValues},
+	}
+}
+
+func (e *evaluator) evaluateMapAndKey(callParameters []node.Node) (*node.Node, *node.Node, error) {
+	mapValue, err := checkType(callParameters[0], node.MAP)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := checkType(callParameters[1], node.STRING)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mapValue, key, nil
+}
+
+func evaluateBuiltinMapGet(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Return a monad with the value for the key, or an empty monad if the key is not in the map
+	mapValue, key, err := eval.evaluateMapAndKey(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	value, _ := mapValue.GetMapValue(key.Value)
+	return node.CreateMonad(lineNum, value).Ptr(), nil
+}
+
+func evaluateBuiltinMapSet(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	mapValue, key, err := eval.evaluateMapAndKey(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapValue.SetMapValue(key.Value, callParameters[2]).Ptr(), nil
+}
+
+func evaluateBuiltinMapKeys(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	mapValue, err := checkType(callParameters[0], node.MAP)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []node.Node{}
+	for _, entry := range mapValue.Params {
+		keys = append(keys, entry.GetParam(node.MAP_KEY))
+	}
+	return node.CreateList(lineNum, keys).Ptr(), nil
+}
+
+func evaluateBuiltinMapValues(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	mapValue, err := checkType(callParameters[0], node.MAP)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []node.Node{}
+	for _, entry := range mapValue.Params {
+		values = append(values, entry.GetParam(node.MAP_VALUE))
+	}
+	return node.CreateList(lineNum, values).Ptr(), nil
+}
@@ -0,0 +1,417 @@
+package evaluator
+
+import (
+	"boomerang/node"
+	"boomerang/tokens"
+	"boomerang/utils"
+	"math"
+	"math/big"
+	"sort"
+)
+
+// Largest integer that numbers can store exactly
+const maxExactInteger = 1 << 53
+
+/*
+Math builtins. Math functions and constants are members of the "math" builtin variable (e.g., "math.sqrt <- (4,)" and
+"math.e"), so only "math" is a reserved name, and names like "floor" and "e" are still available for variables.
+
+The value of "math" is a module, like the value of an import statement. Math functions are stored in "builtins" with
+the "math." prefix (e.g., "math.sqrt"), which can't conflict with any identifier because identifiers can't contain
+periods.
+*/
+const (
+	BUILTIN_MATH = "math"
+
+	// Functions
+	MATH_SQRT       = "sqrt"
+	MATH_POW        = "pow"
+	MATH_ABS        = "abs"
+	MATH_FLOOR      = "floor"
+	MATH_CEIL       = "ceil"
+	MATH_ROUND      = "round"
+	MATH_EXP        = "exp"
+	MATH_LOG        = "log"
+	MATH_LOG10      = "log10"
+	MATH_LOG2       = "log2"
+	MATH_SIN        = "sin"
+	MATH_COS        = "cos"
+	MATH_TAN        = "tan"
+	MATH_ASIN       = "asin"
+	MATH_ACOS       = "acos"
+	MATH_ATAN       = "atan"
+	MATH_ATAN2      = "atan2"
+	MATH_MIN        = "min"
+	MATH_MAX        = "max"
+	MATH_CLAMP      = "clamp"
+	MATH_GCD        = "gcd"
+	MATH_LCM        = "lcm"
+	MATH_IS_INTEGER = "is_integer"
+	MATH_IS_NAN     = "is_nan"
+	MATH_IS_INF     = "is_inf"
+
+	// Constants
+	MATH_PI  = "pi"
+	MATH_E   = "e"
+	MATH_INF = "inf"
+	MATH_NAN = "nan"
+)
+
+var mathConstants = map[string]float64{
+	MATH_PI:  math.Pi,
+	MATH_E:   math.E,
+	MATH_INF: math.Inf(1),
+	MATH_NAN: math.NaN(),
+}
+
+func getMathFunctions() map[string]Builtin {
+	return map[string]Builtin{
+		MATH_SQRT:       {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateMathSqrt},
+		MATH_POW:        {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateMathPow},
+		MATH_ABS:        {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: createMathFunction(math.Abs)},
+		MATH_FLOOR:      {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: createMathFunction(math.Floor)},
+		MATH_CEIL:       {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: createMathFunction(math.Ceil)},
+		MATH_ROUND:      {Type: node.BUILTIN_FUNCTION, NumArgs: nArgsValue, Function: evaluateMathRound},
+		MATH_EXP:        {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: createMathFunction(math.Exp)},
+		MATH_LOG:        {Type: node.BUILTIN_FUNCTION, NumArgs: nArgsValue, Function: evaluateMathLog},
+		MATH_LOG10:      {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: createLogFunction(MATH_LOG10, math.Log10)},
+		MATH_LOG2:       {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: createLogFunction(MATH_LOG2, math.Log2)},
+		MATH_SIN:        {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: createMathFunction(math.Sin)},
+		MATH_COS:        {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: createMathFunction(math.Cos)},
+		MATH_TAN:        {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: createMathFunction(math.Tan)},
+		MATH_ASIN:       {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: createInverseTrigFunction(MATH_ASIN, math.Asin)},
+		MATH_ACOS:       {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: createInverseTrigFunction(MATH_ACOS, math.Acos)},
+		MATH_ATAN:       {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: createMathFunction(math.Atan)},
+		MATH_ATAN2:      {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateMathAtan2},
+		MATH_MIN:        {Type: node.BUILTIN_FUNCTION, NumArgs: nArgsValue, Function: evaluateMathMin},
+		MATH_MAX:        {Type: node.BUILTIN_FUNCTION, NumArgs: nArgsValue, Function: evaluateMathMax},
+		MATH_CLAMP:      {Type: node.BUILTIN_FUNCTION, NumArgs: 3, Function: evaluateMathClamp},
+		MATH_GCD:        {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateMathGcd},
+		MATH_LCM:        {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateMathLcm},
+		MATH_IS_INTEGER: {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateMathIsInteger},
+		MATH_IS_NAN:     {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateMathIsNan},
+		MATH_IS_INF:     {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateMathIsInf},
+	}
+}
+
+func getMathBuiltins() map[string]Builtin {
+	mathBuiltins := map[string]Builtin{
+		BUILTIN_MATH: {Type: node.BUILTIN_VARIABLE, NumArgs: 0, Function: evaluateBuiltinMath},
+	}
+
+	for name, builtin := range getMathFunctions() {
+		mathBuiltins[getMathBuiltinName(name)] = builtin
+	}
+	return mathBuiltins
+}
+
+func getMathBuiltinName(name string) string {
+	return BUILTIN_MATH + "." + name
+}
+
+func evaluateBuiltinMath(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// The members are sorted by name so the module is the same every time it is created
+	names := []string{}
+	for name := range getMathFunctions() {
+		names = append(names, name)
+	}
+	for name := range mathConstants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	members := []node.Node{}
+	for _, name := range names {
+		var value node.Node
+		if constant, ok := mathConstants[name]; ok {
+			value = createNumber(lineNum, constant)
+		} else {
+			value = node.CreateBuiltinFunctionIdentifier(lineNum, getMathBuiltinName(name))
+		}
+		members = append(members, node.CreateAssignmentNode(node.CreateIdentifier(lineNum, name), value))
+	}
+
+	return node.CreateModule(lineNum, BUILTIN_MATH, members).Ptr(), nil
+}
+
+func createNumber(lineNum int, value float64) node.Node {
+	return node.CreateNumber(lineNum, tokens.FormatNumber(value))
+}
+
+func (e *evaluator) evaluateNumbers(callParameters []node.Node) ([]float64, error) {
+	// Evaluate call parameters that must all be numbers and return their values
+	values := []float64{}
+	for _, param := range callParameters {
+		value, err := checkType(param, node.NUMBER)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, *utils.ConvertStringToFloat(value.Value))
+	}
+	return values, nil
+}
+
+func (e *evaluator) evaluateIntegers(lineNum int, callParameters []node.Node) ([]int, error) {
+	// Evaluate call parameters that must all be integers and return their values
+	values := []int{}
+	for _, param := range callParameters {
+		value, err := checkType(param, node.NUMBER)
+		if err != nil {
+			return nil, err
+		}
+
+		integer := utils.ConvertStringToInteger(value.Value)
+		if integer == nil {
+			return nil, utils.CreateError(lineNum, "expected an integer, got %s", value.Value)
+		}
+		values = append(values, *integer)
+	}
+	return values, nil
+}
+
+func createMathFunction(function func(float64) float64) func(*evaluator, int, []node.Node) (*node.Node, error) {
+	// Create a builtin for a function that takes one number and is defined for all numbers
+	return func(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+		values, err := eval.evaluateNumbers(callParameters)
+		if err != nil {
+			return nil, err
+		}
+		return createNumber(lineNum, function(values[0])).Ptr(), nil
+	}
+}
+
+func createLogFunction(name string, function func(float64) float64) func(*evaluator, int, []node.Node) (*node.Node, error) {
+	// Logarithms are only defined for positive numbers
+	return func(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+		values, err := eval.evaluateNumbers(callParameters)
+		if err != nil {
+			return nil, err
+		}
+
+		if values[0] <= 0 {
+			return nil, utils.CreateError(lineNum, "%s is only defined for positive numbers, got %s", getMathBuiltinName(name), utils.FloatToString(values[0]))
+		}
+		return createNumber(lineNum, function(values[0])).Ptr(), nil
+	}
+}
+
+func createInverseTrigFunction(name string, function func(float64) float64) func(*evaluator, int, []node.Node) (*node.Node, error) {
+	// The inverse sine and cosine are only defined from -1 to 1
+	return func(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+		values, err := eval.evaluateNumbers(callParameters)
+		if err != nil {
+			return nil, err
+		}
+
+		if values[0] < -1 || values[0] > 1 {
+			return nil, utils.CreateError(lineNum, "%s is only defined from -1 to 1, got %s", getMathBuiltinName(name), utils.FloatToString(values[0]))
+		}
+		return createNumber(lineNum, function(values[0])).Ptr(), nil
+	}
+}
+
+func evaluateMathSqrt(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	values, err := eval.evaluateNumbers(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	if values[0] < 0 {
+		return nil, utils.CreateError(lineNum, "cannot take the square root of a negative number (%s)", utils.FloatToString(values[0]))
+	}
+	return createNumber(lineNum, math.Sqrt(values[0])).Ptr(), nil
+}
+
+func evaluateMathPow(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	values, err := eval.evaluateNumbers(callParameters)
+	if err != nil {
+		return nil, err
+	}
+	return createNumber(lineNum, math.Pow(values[0], values[1])).Ptr(), nil
+}
+
+func evaluateMathRound(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	/*
+		Round to the nearest integer, or to the given number of digits after the decimal point. Negative digits round
+		to the left of the decimal point (e.g., -2 rounds to the nearest hundred). Halves are rounded away from zero.
+	*/
+	if err := checkNumArgsInRange(lineNum, callParameters, 1, 2); err != nil {
+		return nil, err
+	}
+
+	values, err := eval.evaluateNumbers(callParameters[:1])
+	if err != nil {
+		return nil, err
+	}
+
+	digits := 0
+	if len(callParameters) == 2 {
+		digitValues, err := eval.evaluateIntegers(lineNum, callParameters[1:])
+		if err != nil {
+			return nil, err
+		}
+		digits = digitValues[0]
+	}
+
+	// Scales too large or small for a float would round every number to NaN
+	scale := math.Pow(10, float64(digits))
+	scaled := values[0] * scale
+	if scale == 0 || math.IsInf(scale, 0) || math.IsInf(scaled, 0) && !math.IsInf(values[0], 0) {
+		return nil, utils.CreateError(
+			lineNum,
+			"%s cannot round %s to %d digits",
+			getMathBuiltinName(MATH_ROUND),
+			utils.FloatToString(values[0]),
+			digits,
+		)
+	}
+	return createNumber(lineNum, math.Round(scaled)/scale).Ptr(), nil
+}
+
+func evaluateMathLog(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// The natural logarithm, or the logarithm with the given base
+	if err := checkNumArgsInRange(lineNum, callParameters, 1, 2); err != nil {
+		return nil, err
+	}
+
+	values, err := eval.evaluateNumbers(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, value := range values {
+		if value <= 0 {
+			return nil, utils.CreateError(lineNum, "%s is only defined for positive numbers, got %s", getMathBuiltinName(MATH_LOG), utils.FloatToString(value))
+		}
+	}
+
+	if len(values) == 1 {
+		return createNumber(lineNum, math.Log(values[0])).Ptr(), nil
+	}
+
+	if values[1] == 1 {
+		return nil, utils.CreateError(lineNum, "logarithm base cannot be 1")
+	}
+	return createNumber(lineNum, math.Log(values[0])/math.Log(values[1])).Ptr(), nil
+}
+
+func evaluateMathAtan2(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// The angle of the point (x, y), where the arguments are "y" then "x"
+	values, err := eval.evaluateNumbers(callParameters)
+	if err != nil {
+		return nil, err
+	}
+	return createNumber(lineNum, math.Atan2(values[0], values[1])).Ptr(), nil
+}
+
+func evaluateMathMin(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	return eval.evaluateMathMinMax(lineNum, MATH_MIN, callParameters, math.Min)
+}
+
+func evaluateMathMax(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	return eval.evaluateMathMinMax(lineNum, MATH_MAX, callParameters, math.Max)
+}
+
+func (e *evaluator) evaluateMathMinMax(lineNum int, name string, callParameters []node.Node, compare func(float64, float64) float64) (*node.Node, error) {
+	// Unlike the "min" and "max" list builtins, these take the numbers as separate arguments (e.g., "math.min <- (1, 2)")
+	if len(callParameters) == 0 {
+		return nil, utils.CreateError(lineNum, "%s requires at least one number", getMathBuiltinName(name))
+	}
+
+	values, err := e.evaluateNumbers(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	result := values[0]
+	for _, value := range values[1:] {
+		result = compare(result, value)
+	}
+	return createNumber(lineNum, result).Ptr(), nil
+}
+
+func evaluateMathClamp(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Limit a number to the range from "low" to "high"
+	values, err := eval.evaluateNumbers(callParameters)
+	if err != nil {
+		return nil, err
+	}
+	value, low, high := values[0], values[1], values[2]
+
+	if low > high {
+		return nil, utils.CreateError(
+			lineNum,
+			"clamp lower bound (%s) is greater than upper bound (%s)",
+			utils.FloatToString(low),
+			utils.FloatToString(high),
+		)
+	}
+	return createNumber(lineNum, math.Max(low, math.Min(value, high))).Ptr(), nil
+}
+
+func gcd(a int, b int) int {
+	// The greatest common divisor is always non-negative
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func evaluateMathGcd(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	values, err := eval.evaluateIntegers(lineNum, callParameters)
+	if err != nil {
+		return nil, err
+	}
+	return createNumber(lineNum, float64(gcd(values[0], values[1]))).Ptr(), nil
+}
+
+func evaluateMathLcm(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// The least common multiple is 0 if either number is 0
+	values, err := eval.evaluateIntegers(lineNum, callParameters)
+	if err != nil {
+		return nil, err
+	}
+	a, b := values[0], values[1]
+
+	if a == 0 || b == 0 {
+		return createNumber(lineNum, 0).Ptr(), nil
+	}
+
+	// Numbers larger than 2^53 can't be stored exactly, so larger results are errors instead of wrong answers
+	lcm := new(big.Int).Mul(big.NewInt(int64(a/gcd(a, b))), big.NewInt(int64(b)))
+	lcm.Abs(lcm)
+	if lcm.Cmp(big.NewInt(maxExactInteger)) > 0 {
+		return nil, utils.CreateError(lineNum, "%s of %d and %d is too large", getMathBuiltinName(MATH_LCM), a, b)
+	}
+	return createNumber(lineNum, float64(lcm.Int64())).Ptr(), nil
+}
+
+func evaluateMathIsInteger(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	values, err := eval.evaluateNumbers(callParameters)
+	if err != nil {
+		return nil, err
+	}
+	value := values[0]
+	return createBoolean(lineNum, !math.IsInf(value, 0) && value == math.Trunc(value)).Ptr(), nil
+}
+
+func evaluateMathIsNan(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// "nan" is not equal to any number, including itself, so "==" can't be used to check for it
+	values, err := eval.evaluateNumbers(callParameters)
+	if err != nil {
+		return nil, err
+	}
+	return createBoolean(lineNum, math.IsNaN(values[0])).Ptr(), nil
+}
+
+func evaluateMathIsInf(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// True for both positive and negative infinity
+	values, err := eval.evaluateNumbers(callParameters)
+	if err != nil {
+		return nil, err
+	}
+	return createBoolean(lineNum, math.IsInf(values[0], 0)).Ptr(), nil
+}
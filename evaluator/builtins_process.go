@@ -0,0 +1,149 @@
+package evaluator
+
+import (
+	"boomerang/node"
+	"boomerang/utils"
+	"fmt"
+	"os"
+	"sort"
+)
+
+/*
+Process builtins for command-line arguments, environment variables and exit codes. The arguments are set with
+"SetArgs"; "process.args" is an empty list if they are not set.
+
+Like "math", the value of "process" is a module. "process.args" is a list, like the constants in "math", and the other
+members are functions stored in "builtins" with the "process." prefix (e.g., "process.exit").
+*/
+const (
+	BUILTIN_PROCESS = "process"
+
+	PROCESS_ARGS    = "args"
+	PROCESS_ENV_GET = "env_get"
+	PROCESS_ENV_SET = "env_set"
+	PROCESS_EXIT    = "exit"
+)
+
+func getProcessFunctions() map[string]Builtin {
+	return map[string]Builtin{
+		PROCESS_ENV_GET: {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinEnvGet},
+		PROCESS_ENV_SET: {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinEnvSet},
+		PROCESS_EXIT:    {Type: node.BUILTIN_FUNCTION, NumArgs: nArgsValue, Function: evaluateBuiltinExit},
+	}
+}
+
+func getProcessBuiltins() map[string]Builtin {
+	processBuiltins := map[string]Builtin{
+		BUILTIN_PROCESS: {Type: node.BUILTIN_VARIABLE, NumArgs: 0, Function: evaluateBuiltinProcess},
+	}
+
+	for name, builtin := range getProcessFunctions() {
+		processBuiltins[getProcessBuiltinName(name)] = builtin
+	}
+	return processBuiltins
+}
+
+func getProcessBuiltinName(name string) string {
+	return BUILTIN_PROCESS + "." + name
+}
+
+func evaluateBuiltinProcess(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// The members are sorted by name so the module is the same every time it is created
+	names := []string{PROCESS_ARGS}
+	for name := range getProcessFunctions() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	members := []node.Node{}
+	for _, name := range names {
+		var value node.Node
+		if name == PROCESS_ARGS {
+			value = createArgs(eval, lineNum)
+		} else {
+			value = node.CreateBuiltinFunctionIdentifier(lineNum, getProcessBuiltinName(name))
+		}
+		members = append(members, node.CreateAssignmentNode(node.CreateIdentifier(lineNum, name), value))
+	}
+
+	return node.CreateModule(lineNum, BUILTIN_PROCESS, members).Ptr(), nil
+}
+
+/*
+ExitError is returned by "Evaluate" when a program calls "process.exit". It is returned like any other error so evaluation
+stops immediately, but it is not a failure unless the code is not 0.
+*/
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exited with code %d", e.Code)
+}
+
+// SetArgs sets the command-line arguments available to the program through "process.args".
+func (e *evaluator) SetArgs(args []string) {
+	e.args = args
+}
+
+func createArgs(eval *evaluator, lineNum int) node.Node {
+	args := []node.Node{}
+	for _, arg := range eval.args {
+		args = append(args, node.CreateRawString(lineNum, arg))
+	}
+	return node.CreateList(lineNum, args)
+}
+
+func evaluateBuiltinEnvGet(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Return a monad with the value of the environment variable, or an empty monad if it is not set
+	name, err := checkType(callParameters[0], node.STRING)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := os.LookupEnv(name.Value)
+	if !ok {
+		return node.CreateMonad(lineNum, nil).Ptr(), nil
+	}
+	return node.CreateMonad(lineNum, node.CreateRawString(lineNum, value).Ptr()).Ptr(), nil
+}
+
+func evaluateBuiltinEnvSet(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Set an environment variable and return its new value
+	name, err := checkType(callParameters[0], node.STRING)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := checkType(callParameters[1], node.STRING)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Setenv(name.Value, value.Value); err != nil {
+		return nil, utils.CreateError(lineNum, "cannot set environment variable %#v", name.Value)
+	}
+	return value, nil
+}
+
+func evaluateBuiltinExit(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Stop the program. The optional argument is the exit code, which defaults to 0.
+	if err := checkNumArgsInRange(lineNum, callParameters, 0, 1); err != nil {
+		return nil, err
+	}
+
+	codes, err := eval.evaluateIntegers(lineNum, callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	code := 0
+	if len(codes) == 1 {
+		code = codes[0]
+	}
+
+	if code < 0 || code > 255 {
+		return nil, utils.CreateError(lineNum, "exit code must be between 0 and 255, got %d", code)
+	}
+	return nil, &ExitError{Code: code}
+}
@@ -0,0 +1,194 @@
+package evaluator
+
+import (
+	"boomerang/node"
+	"boomerang/utils"
+	"sort"
+)
+
+/*
+Random builtins. All random values come from the evaluator's random number generator (see "SetRandomSeed"), so a
+program run with the same seed produces the same values every time.
+
+Like "lists", the value of "random" is a module, so names like "choice" and "sample" can still be used as identifiers.
+*/
+const (
+	BUILTIN_RANDOM = "random"
+
+	RANDOM_INT         = "int"
+	RANDOM_FLOAT       = "float"
+	RANDOM_CHOICE      = "choice"
+	RANDOM_SHUFFLE     = "shuffle"
+	RANDOM_SAMPLE      = "sample"
+	RANDOM_NORMAL      = "normal"
+	RANDOM_EXPONENTIAL = "exponential"
+)
+
+func getRandomFunctions() map[string]Builtin {
+	return map[string]Builtin{
+		RANDOM_INT:         {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinRandomInt},
+		RANDOM_FLOAT:       {Type: node.BUILTIN_FUNCTION, NumArgs: 0, Function: evaluateBuiltinRandomFloat},
+		RANDOM_CHOICE:      {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinChoice},
+		RANDOM_SHUFFLE:     {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinShuffle},
+		RANDOM_SAMPLE:      {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinSample},
+		RANDOM_NORMAL:      {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinRandomNormal},
+		RANDOM_EXPONENTIAL: {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinRandomExponential},
+	}
+}
+
+func getRandomBuiltins() map[string]Builtin {
+	randomBuiltins := map[string]Builtin{
+		BUILTIN_RANDOM: {Type: node.BUILTIN_VARIABLE, NumArgs: 0, Function: evaluateBuiltinRandom},
+	}
+
+	for name, builtin := range getRandomFunctions() {
+		randomBuiltins[getRandomBuiltinName(name)] = builtin
+	}
+	return randomBuiltins
+}
+
+func getRandomBuiltinName(name string) string {
+	return BUILTIN_RANDOM + "." + name
+}
+
+func evaluateBuiltinRandom(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// The members are sorted by name so the module is the same every time it is created
+	names := []string{}
+	for name := range getRandomFunctions() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	members := []node.Node{}
+	for _, name := range names {
+		value := node.CreateBuiltinFunctionIdentifier(lineNum, getRandomBuiltinName(name))
+		members = append(members, node.CreateAssignmentNode(node.CreateIdentifier(lineNum, name), value))
+	}
+
+	return node.CreateModule(lineNum, BUILTIN_RANDOM, members).Ptr(), nil
+}
+
+func evaluateBuiltinRandomInt(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	minNumber := callParameters[0]
+
+	if err := utils.CheckTypeError(lineNum, minNumber.Type, node.NUMBER); err != nil {
+		return nil, err
+	}
+
+	minValue := utils.ConvertStringToInteger(minNumber.Value)
+	if minValue == nil {
+		return nil, utils.CreateError(lineNum, "min value must be an integer")
+	}
+
+	maxNumber := callParameters[1]
+
+	if err := utils.CheckTypeError(lineNum, maxNumber.Type, node.NUMBER); err != nil {
+		return nil, err
+	}
+
+	maxValue := utils.ConvertStringToInteger(maxNumber.Value)
+	if maxValue == nil {
+		return nil, utils.CreateError(lineNum, "max value must be an integer")
+	}
+
+	if *minValue > *maxValue {
+		return nil, utils.CreateError(
+			minNumber.LineNum,
+			"the minimum number, %d, cannot be greater than the maximum number, %d",
+			*minValue,
+			*maxValue,
+		)
+	}
+
+	// "+ 1" ensures the generated number includes the maximum value
+	randomValue := eval.rng.Intn(*maxValue-*minValue+1) + *minValue
+	return node.CreateNumber(minNumber.LineNum, utils.IntToString(randomValue)).Ptr(), nil
+}
+
+func evaluateBuiltinRandomFloat(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// A number in the range [0, 1)
+	return createNumber(lineNum, eval.rng.Float64()).Ptr(), nil
+}
+
+func evaluateBuiltinChoice(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	list, err := checkType(callParameters[0], node.LIST)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list.Params) == 0 {
+		return nil, utils.CreateError(lineNum, "cannot choose from an empty list")
+	}
+	return &list.Params[eval.rng.Intn(len(list.Params))], nil
+}
+
+func evaluateBuiltinShuffle(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Return a shuffled copy of the list. The original list is not changed.
+	list, err := checkType(callParameters[0], node.LIST)
+	if err != nil {
+		return nil, err
+	}
+
+	shuffledList := append([]node.Node{}, list.Params...)
+	eval.rng.Shuffle(len(shuffledList), func(i, j int) {
+		shuffledList[i], shuffledList[j] = shuffledList[j], shuffledList[i]
+	})
+	return node.CreateList(lineNum, shuffledList).Ptr(), nil
+}
+
+func evaluateBuiltinSample(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Choose "size" elements without replacement. The elements are returned in the order they were chosen.
+	list, err := checkType(callParameters[0], node.LIST)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := eval.evaluateIntegers(lineNum, callParameters[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	size := values[0]
+	if size < 0 || size > len(list.Params) {
+		return nil, utils.CreateError(
+			lineNum,
+			"sample size must be between 0 and the length of the list (%d), got %d",
+			len(list.Params),
+			size,
+		)
+	}
+
+	sampledList := []node.Node{}
+	for _, index := range eval.rng.Perm(len(list.Params))[:size] {
+		sampledList = append(sampledList, list.Params[index])
+	}
+	return node.CreateList(lineNum, sampledList).Ptr(), nil
+}
+
+func evaluateBuiltinRandomNormal(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// A number from the normal distribution with the given mean and standard deviation
+	values, err := eval.evaluateNumbers(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	mean, standardDeviation := values[0], values[1]
+	if standardDeviation < 0 {
+		return nil, utils.CreateError(lineNum, "standard deviation cannot be negative, got %s", utils.FloatToString(standardDeviation))
+	}
+	return createNumber(lineNum, eval.rng.NormFloat64()*standardDeviation+mean).Ptr(), nil
+}
+
+func evaluateBuiltinRandomExponential(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// A number from the exponential distribution with the given rate (the mean is 1 / rate)
+	values, err := eval.evaluateNumbers(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := values[0]
+	if rate <= 0 {
+		return nil, utils.CreateError(lineNum, "rate must be greater than 0, got %s", utils.FloatToString(rate))
+	}
+	return createNumber(lineNum, eval.rng.ExpFloat64()/rate).Ptr(), nil
+}
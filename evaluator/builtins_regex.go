@@ -0,0 +1,220 @@
+package evaluator
+
+import (
+	"boomerang/node"
+	"boomerang/utils"
+	"container/list"
+	"regexp"
+	"strings"
+)
+
+/*
+Regular expression builtins. Patterns use Go's RE2 syntax (https://github.com/google/re2/wiki/Syntax). Compiled
+patterns are cached by the evaluator, so a pattern used in a loop is only compiled once. The cache keeps the most
+recently used patterns, so programs that build many different patterns do not use more and more memory.
+
+A match is a string if the pattern has no capture groups. Otherwise, it is a list containing the whole match followed
+by each group (e.g., matching "(\w+)@(\w+)" against "me@host" is ("me@host", "me", "host")). Groups that did not
+match are empty strings.
+*/
+const (
+	BUILTIN_REGEX_MATCH    = "regex_match"
+	BUILTIN_REGEX_GROUPS   = "regex_groups"
+	BUILTIN_REGEX_FIND_ALL = "regex_find_all"
+	BUILTIN_REGEX_REPLACE  = "regex_replace"
+	BUILTIN_REGEX_SPLIT    = "regex_split"
+)
+
+// Number of compiled patterns kept in the cache
+const regexCacheSize = 128
+
+type regexCacheEntry struct {
+	pattern string
+	regex   *regexp.Regexp
+}
+
+// regexCache stores compiled patterns, removing the least recently used pattern when it is full.
+type regexCache struct {
+	entries map[string]*list.Element
+	order   *list.List // Entries from the most to the least recently used
+}
+
+func newRegexCache() *regexCache {
+	return &regexCache{entries: map[string]*list.Element{}, order: list.New()}
+}
+
+func (c *regexCache) get(pattern string) (*regexp.Regexp, bool) {
+	element, ok := c.entries[pattern]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(regexCacheEntry).regex, true
+}
+
+func (c *regexCache) add(pattern string, regex *regexp.Regexp) {
+	c.entries[pattern] = c.order.PushFront(regexCacheEntry{pattern: pattern, regex: regex})
+
+	if c.order.Len() > regexCacheSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(regexCacheEntry).pattern)
+	}
+}
+
+func getRegexBuiltins() map[string]Builtin {
+	return map[string]Builtin{
+		BUILTIN_REGEX_MATCH:    {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinRegexMatch},
+		BUILTIN_REGEX_GROUPS:   {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinRegexGroups},
+		BUILTIN_REGEX_FIND_ALL: {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinRegexFindAll},
+		BUILTIN_REGEX_REPLACE:  {Type: node.BUILTIN_FUNCTION, NumArgs: 3, Function: evaluateBuiltinRegexReplace},
+		BUILTIN_REGEX_SPLIT:    {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinRegexSplit},
+	}
+}
+
+func (e *evaluator) evaluateStringAndPattern(lineNum int, callParameters []node.Node) (*node.Node, *regexp.Regexp, error) {
+	// Regex builtins take a string followed by a pattern
+	str, err := checkType(callParameters[0], node.STRING)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pattern, err := checkType(callParameters[1], node.STRING)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if regex, ok := e.regexCache.get(pattern.Value); ok {
+		return str, regex, nil
+	}
+
+	regex, err := regexp.Compile(pattern.Value)
+	if err != nil {
+		return nil, nil, utils.CreateError(lineNum, "invalid regular expression %#v: %s", pattern.Value, err.Error())
+	}
+	e.regexCache.add(pattern.Value, regex)
+	return str, regex, nil
+}
+
+func createMatch(lineNum int, regex *regexp.Regexp, groups []string) node.Node {
+	// See the comment at the top of this file for how matches are represented
+	if regex.NumSubexp() == 0 {
+		return node.CreateRawString(lineNum, groups[0])
+	}
+	return createStringList(lineNum, groups)
+}
+
+func createStringList(lineNum int, values []string) node.Node {
+	elements := []node.Node{}
+	for _, value := range values {
+		elements = append(elements, node.CreateRawString(lineNum, value))
+	}
+	return node.CreateList(lineNum, elements)
+}
+
+func evaluateBuiltinRegexMatch(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// True if the pattern matches any part of the string. Use "^" and "$" to match the whole string.
+	str, regex, err := eval.evaluateStringAndPattern(lineNum, callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	if regex.MatchString(str.Value) {
+		return node.CreateBooleanTrue(lineNum).Ptr(), nil
+	}
+	return node.CreateBooleanFalse(lineNum).Ptr(), nil
+}
+
+func evaluateBuiltinRegexGroups(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Return a monad containing a list with the first match followed by its groups, or an empty monad
+	str, regex, err := eval.evaluateStringAndPattern(lineNum, callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := regex.FindStringSubmatch(str.Value)
+	if groups == nil {
+		return node.CreateMonad(lineNum, nil).Ptr(), nil
+	}
+	return node.CreateMonad(lineNum, createStringList(lineNum, groups).Ptr()).Ptr(), nil
+}
+
+func evaluateBuiltinRegexFindAll(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	str, regex, err := eval.evaluateStringAndPattern(lineNum, callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []node.Node{}
+	for _, groups := range regex.FindAllStringSubmatch(str.Value, -1) {
+		matches = append(matches, createMatch(lineNum, regex, groups))
+	}
+	return node.CreateList(lineNum, matches).Ptr(), nil
+}
+
+func evaluateBuiltinRegexReplace(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	/*
+		Replace every match. The replacement is either a string, where "$1" or "${name}" is replaced with the text of a
+		group ("$$" for a literal "$"), or a function that takes a match and returns the replacement string.
+	*/
+	str, regex, err := eval.evaluateStringAndPattern(lineNum, callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	replacement := callParameters[2]
+
+	if replacement.Type == node.STRING {
+		return node.CreateRawString(lineNum, regex.ReplaceAllString(str.Value, replacement.Value)).Ptr(), nil
+	}
+
+	if replacement.Type != node.FUNCTION && replacement.Type != node.BUILTIN_FUNCTION {
+		return nil, utils.CreateError(
+			lineNum,
+			"expected %s, %s or %s, got %s",
+			node.STRING,
+			node.FUNCTION,
+			node.BUILTIN_FUNCTION,
+			replacement.Type,
+		)
+	}
+
+	// Build the result from the text between matches and the return values of the function
+	var result strings.Builder
+	previousEnd := 0
+	for _, indices := range regex.FindAllStringSubmatchIndex(str.Value, -1) {
+		groups := []string{}
+		for i := 0; i < len(indices); i += 2 {
+			if indices[i] < 0 {
+				groups = append(groups, "")
+			} else {
+				groups = append(groups, str.Value[indices[i]:indices[i+1]])
+			}
+		}
+
+		value, err := eval.callFunction(lineNum, BUILTIN_REGEX_REPLACE, replacement, []node.Node{createMatch(lineNum, regex, groups)})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := utils.CheckTypeError(lineNum, value.Type, node.STRING); err != nil {
+			return nil, err
+		}
+
+		result.WriteString(str.Value[previousEnd:indices[0]])
+		result.WriteString(value.Value)
+		previousEnd = indices[1]
+	}
+	result.WriteString(str.Value[previousEnd:])
+
+	return node.CreateRawString(lineNum, result.String()).Ptr(), nil
+}
+
+func evaluateBuiltinRegexSplit(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Split a string around each match
+	str, regex, err := eval.evaluateStringAndPattern(lineNum, callParameters)
+	if err != nil {
+		return nil, err
+	}
+	return createStringList(lineNum, regex.Split(str.Value, -1)).Ptr(), nil
+}
@@ -0,0 +1,328 @@
+package evaluator
+
+import (
+	"boomerang/node"
+	"boomerang/tokens"
+	"boomerang/utils"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+String builtins. All lengths and positions are measured in characters (Unicode code points), not bytes, so
+"héllo" has a length of 5 and the index of "l" is 2.
+
+Like "lists", the value of "strings" is a module. String functions are stored in "builtins" with the "strings." prefix
+(e.g., "strings.split"), so names like "join" and "replace" can still be used as identifiers.
+*/
+const (
+	BUILTIN_STRINGS = "strings"
+
+	STRINGS_SPLIT       = "split"
+	STRINGS_JOIN        = "join"
+	STRINGS_TRIM        = "trim"
+	STRINGS_UPPER       = "upper"
+	STRINGS_LOWER       = "lower"
+	STRINGS_REPLACE     = "replace"
+	STRINGS_CONTAINS    = "contains"
+	STRINGS_STARTS_WITH = "starts_with"
+	STRINGS_ENDS_WITH   = "ends_with"
+	STRINGS_INDEX_OF    = "index_of"
+	STRINGS_REPEAT      = "repeat"
+	STRINGS_PAD_LEFT    = "pad_left"
+	STRINGS_PAD_RIGHT   = "pad_right"
+	STRINGS_TO_STRING   = "to_string"
+	STRINGS_TO_NUMBER   = "to_number"
+
+	// Longer results would use too much memory, so they are errors
+	MAX_STRING_LENGTH = 10_000_000
+)
+
+func getStringFunctions() map[string]Builtin {
+	return map[string]Builtin{
+		STRINGS_SPLIT:       {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinSplit},
+		STRINGS_JOIN:        {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinJoin},
+		STRINGS_TRIM:        {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinTrim},
+		STRINGS_UPPER:       {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinUpper},
+		STRINGS_LOWER:       {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinLower},
+		STRINGS_REPLACE:     {Type: node.BUILTIN_FUNCTION, NumArgs: 3, Function: evaluateBuiltinReplace},
+		STRINGS_CONTAINS:    {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinContains},
+		STRINGS_STARTS_WITH: {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinStartsWith},
+		STRINGS_ENDS_WITH:   {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinEndsWith},
+		STRINGS_INDEX_OF:    {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinIndexOf},
+		STRINGS_REPEAT:      {Type: node.BUILTIN_FUNCTION, NumArgs: 2, Function: evaluateBuiltinRepeat},
+		STRINGS_PAD_LEFT:    {Type: node.BUILTIN_FUNCTION, NumArgs: 3, Function: evaluateBuiltinPadLeft},
+		STRINGS_PAD_RIGHT:   {Type: node.BUILTIN_FUNCTION, NumArgs: 3, Function: evaluateBuiltinPadRight},
+		STRINGS_TO_STRING:   {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinToString},
+		STRINGS_TO_NUMBER:   {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinToNumber},
+	}
+}
+
+func getStringBuiltins() map[string]Builtin {
+	stringBuiltins := map[string]Builtin{
+		BUILTIN_STRINGS: {Type: node.BUILTIN_VARIABLE, NumArgs: 0, Function: evaluateBuiltinStrings},
+	}
+
+	for name, builtin := range getStringFunctions() {
+		stringBuiltins[getStringBuiltinName(name)] = builtin
+	}
+	return stringBuiltins
+}
+
+func getStringBuiltinName(name string) string {
+	return BUILTIN_STRINGS + "." + name
+}
+
+func evaluateBuiltinStrings(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// The members are sorted by name so the module is the same every time it is created
+	names := []string{}
+	for name := range getStringFunctions() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	members := []node.Node{}
+	for _, name := range names {
+		value := node.CreateBuiltinFunctionIdentifier(lineNum, getStringBuiltinName(name))
+		members = append(members, node.CreateAssignmentNode(node.CreateIdentifier(lineNum, name), value))
+	}
+
+	return node.CreateModule(lineNum, BUILTIN_STRINGS, members).Ptr(), nil
+}
+
+func (e *evaluator) evaluateStrings(callParameters []node.Node) ([]string, error) {
+	// Evaluate call parameters that must all be strings and return their values
+	values := []string{}
+	for _, param := range callParameters {
+		value, err := checkType(param, node.STRING)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value.Value)
+	}
+	return values, nil
+}
+
+func evaluateBuiltinSplit(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	values, err := eval.evaluateStrings(callParameters)
+	if err != nil {
+		return nil, err
+	}
+	str, separator := values[0], values[1]
+
+	// An empty separator splits the string into individual characters
+	var parts []string
+	if separator == "" {
+		for _, char := range str {
+			parts = append(parts, string(char))
+		}
+	} else {
+		parts = strings.Split(str, separator)
+	}
+
+	list := []node.Node{}
+	for _, part := range parts {
+		list = append(list, node.CreateRawString(lineNum, part))
+	}
+	return node.CreateList(lineNum, list).Ptr(), nil
+}
+
+func evaluateBuiltinJoin(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	list, err := checkType(callParameters[0], node.LIST)
+	if err != nil {
+		return nil, err
+	}
+
+	separator, err := checkType(callParameters[1], node.STRING)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := []string{}
+	for _, element := range list.Params {
+		if err := utils.CheckTypeError(lineNum, element.Type, node.STRING); err != nil {
+			return nil, err
+		}
+		parts = append(parts, element.Value)
+	}
+	return node.CreateRawString(lineNum, strings.Join(parts, separator.Value)).Ptr(), nil
+}
+
+func evaluateBuiltinTrim(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	values, err := eval.evaluateStrings(callParameters)
+	if err != nil {
+		return nil, err
+	}
+	return node.CreateRawString(lineNum, strings.TrimSpace(values[0])).Ptr(), nil
+}
+
+func evaluateBuiltinUpper(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	values, err := eval.evaluateStrings(callParameters)
+	if err != nil {
+		return nil, err
+	}
+	return node.CreateRawString(lineNum, strings.ToUpper(values[0])).Ptr(), nil
+}
+
+func evaluateBuiltinLower(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	values, err := eval.evaluateStrings(callParameters)
+	if err != nil {
+		return nil, err
+	}
+	return node.CreateRawString(lineNum, strings.ToLower(values[0])).Ptr(), nil
+}
+
+func evaluateBuiltinReplace(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	values, err := eval.evaluateStrings(callParameters)
+	if err != nil {
+		return nil, err
+	}
+	return node.CreateRawString(lineNum, strings.ReplaceAll(values[0], values[1], values[2])).Ptr(), nil
+}
+
+func evaluateBuiltinContains(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	values, err := eval.evaluateStrings(callParameters)
+	if err != nil {
+		return nil, err
+	}
+	return createBoolean(lineNum, strings.Contains(values[0], values[1])).Ptr(), nil
+}
+
+func evaluateBuiltinStartsWith(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	values, err := eval.evaluateStrings(callParameters)
+	if err != nil {
+		return nil, err
+	}
+	return createBoolean(lineNum, strings.HasPrefix(values[0], values[1])).Ptr(), nil
+}
+
+func evaluateBuiltinEndsWith(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	values, err := eval.evaluateStrings(callParameters)
+	if err != nil {
+		return nil, err
+	}
+	return createBoolean(lineNum, strings.HasSuffix(values[0], values[1])).Ptr(), nil
+}
+
+func evaluateBuiltinIndexOf(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	/*
+		Return a monad containing the position of the first occurrence of the substring, or an empty monad if the
+		substring is not found. The position is the number of characters before the substring, not bytes.
+	*/
+	values, err := eval.evaluateStrings(callParameters)
+	if err != nil {
+		return nil, err
+	}
+	str, substring := values[0], values[1]
+
+	byteIndex := strings.Index(str, substring)
+	if byteIndex == -1 {
+		return node.CreateMonad(lineNum, nil).Ptr(), nil
+	}
+
+	index := node.CreateNumber(lineNum, utils.IntToString(utf8.RuneCountInString(str[:byteIndex])))
+	return node.CreateMonad(lineNum, &index).Ptr(), nil
+}
+
+func evaluateBuiltinRepeat(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	str, err := checkType(callParameters[0], node.STRING)
+	if err != nil {
+		return nil, err
+	}
+
+	countNumber, err := checkType(callParameters[1], node.NUMBER)
+	if err != nil {
+		return nil, err
+	}
+
+	count := utils.ConvertStringToInteger(countNumber.Value)
+	if count == nil || *count < 0 {
+		return nil, utils.CreateError(lineNum, "repeat count must be a non-negative integer")
+	}
+
+	length := utf8.RuneCountInString(str.Value)
+	if length > 0 && *count > MAX_STRING_LENGTH/length {
+		return nil, utils.CreateError(lineNum, "repeat result cannot be longer than %d characters", MAX_STRING_LENGTH)
+	}
+
+	return node.CreateRawString(lineNum, strings.Repeat(str.Value, *count)).Ptr(), nil
+}
+
+func evaluateBuiltinPadLeft(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	return evaluatePad(eval, lineNum, callParameters, true)
+}
+
+func evaluateBuiltinPadRight(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	return evaluatePad(eval, lineNum, callParameters, false)
+}
+
+func evaluatePad(eval *evaluator, lineNum int, callParameters []node.Node, padLeft bool) (*node.Node, error) {
+	/*
+		Add the padding character to the start ("padLeft" is true) or end ("padLeft" is false) of the string until the
+		string is "width" characters long. Strings already at least "width" characters long are returned unchanged.
+	*/
+	str, err := checkType(callParameters[0], node.STRING)
+	if err != nil {
+		return nil, err
+	}
+
+	widthNumber, err := checkType(callParameters[1], node.NUMBER)
+	if err != nil {
+		return nil, err
+	}
+
+	width := utils.ConvertStringToInteger(widthNumber.Value)
+	if width == nil {
+		return nil, utils.CreateError(lineNum, "pad width must be an integer")
+	}
+	if *width > MAX_STRING_LENGTH {
+		return nil, utils.CreateError(lineNum, "pad width cannot be more than %d, got %d", MAX_STRING_LENGTH, *width)
+	}
+
+	padding, err := checkType(callParameters[2], node.STRING)
+	if err != nil {
+		return nil, err
+	}
+
+	if utf8.RuneCountInString(padding.Value) != 1 {
+		return nil, utils.CreateError(lineNum, "pad character must be a single character, got %#v", padding.Value)
+	}
+
+	paddingLength := *width - utf8.RuneCountInString(str.Value)
+	if paddingLength <= 0 {
+		return node.CreateRawString(lineNum, str.Value).Ptr(), nil
+	}
+
+	pad := strings.Repeat(padding.Value, paddingLength)
+	if padLeft {
+		return node.CreateRawString(lineNum, pad+str.Value).Ptr(), nil
+	}
+	return node.CreateRawString(lineNum, str.Value+pad).Ptr(), nil
+}
+
+func evaluateBuiltinToString(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	value := callParameters[0]
+
+	// Strings are returned as-is so quotes are not added around the value
+	if value.Type == node.STRING {
+		return node.CreateRawString(lineNum, value.Value).Ptr(), nil
+	}
+	return node.CreateRawString(lineNum, value.String()).Ptr(), nil
+}
+
+func evaluateBuiltinToNumber(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Return a monad containing the number, or an empty monad if the string is not a valid number
+	str, err := checkType(callParameters[0], node.STRING)
+	if err != nil {
+		return nil, err
+	}
+
+	number := utils.ConvertStringToFloat(strings.TrimSpace(str.Value))
+	if number == nil {
+		return node.CreateMonad(lineNum, nil).Ptr(), nil
+	}
+
+	numberNode := node.CreateNumber(lineNum, tokens.FormatNumber(*number))
+	return node.CreateMonad(lineNum, &numberNode).Ptr(), nil
+}
@@ -0,0 +1,269 @@
+package evaluator
+
+import (
+	"boomerang/node"
+	"boomerang/utils"
+	"math"
+	"sort"
+	"time"
+)
+
+/*
+Time builtins are members of the "time" builtin variable (e.g., "time.now <- ()"), like the math builtins. Timestamps
+are numbers of seconds since the Unix epoch (January 1, 1970 UTC) and durations are numbers of seconds, so they can be
+used with the arithmetic operators (e.g., "time.now <- () + 60" is one minute from now). Timestamps have microsecond
+precision.
+
+The current time comes from the evaluator's clock, which can be replaced with "SetClock".
+*/
+const (
+	BUILTIN_TIME = "time"
+
+	// Functions
+	TIME_NOW             = "now"
+	TIME_MONOTONIC       = "monotonic"
+	TIME_SLEEP           = "sleep"
+	TIME_FORMAT          = "format"
+	TIME_PARSE           = "parse"
+	TIME_PARSE_DURATION  = "parse_duration"
+	TIME_FORMAT_DURATION = "format_duration"
+	TIME_ADD_DATE        = "add_date"
+	TIME_PARTS           = "parts"
+)
+
+// Clock is the source of the current time for the time builtins.
+type Clock interface {
+	Now() time.Time
+	Sleep(duration time.Duration)
+}
+
+// The default clock, which uses the system time
+type systemClock struct{}
+
+func (c systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c systemClock) Sleep(duration time.Duration) {
+	time.Sleep(duration)
+}
+
+// SetClock replaces the clock used by the time builtins (e.g., with a fake clock in tests).
+func (e *evaluator) SetClock(clock Clock) {
+	e.clock = clock
+	e.clockStart = clock.Now()
+}
+
+// ISO-8601 layouts accepted by "time.parse". Timestamps without a time zone are in UTC.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+func getTimeFunctions() map[string]Builtin {
+	return map[string]Builtin{
+		TIME_NOW:             {Type: node.BUILTIN_FUNCTION, NumArgs: 0, Function: evaluateBuiltinTimeNow},
+		TIME_MONOTONIC:       {Type: node.BUILTIN_FUNCTION, NumArgs: 0, Function: evaluateBuiltinTimeMonotonic},
+		TIME_SLEEP:           {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinTimeSleep},
+		TIME_FORMAT:          {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinTimeFormat},
+		TIME_PARSE:           {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinTimeParse},
+		TIME_PARSE_DURATION:  {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinTimeParseDuration},
+		TIME_FORMAT_DURATION: {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinTimeFormatDuration},
+		TIME_ADD_DATE:        {Type: node.BUILTIN_FUNCTION, NumArgs: 4, Function: evaluateBuiltinTimeAddDate},
+		TIME_PARTS:           {Type: node.BUILTIN_FUNCTION, NumArgs: 1, Function: evaluateBuiltinTimeParts},
+	}
+}
+
+func getTimeBuiltins() map[string]Builtin {
+	timeBuiltins := map[string]Builtin{
+		BUILTIN_TIME: {Type: node.BUILTIN_VARIABLE, NumArgs: 0, Function: evaluateBuiltinTime},
+	}
+
+	for name, builtin := range getTimeFunctions() {
+		timeBuiltins[getTimeBuiltinName(name)] = builtin
+	}
+	return timeBuiltins
+}
+
+func getTimeBuiltinName(name string) string {
+	return BUILTIN_TIME + "." + name
+}
+
+func evaluateBuiltinTime(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// The members are sorted by name so the module is the same every time it is created
+	names := []string{}
+	for name := range getTimeFunctions() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	members := []node.Node{}
+	for _, name := range names {
+		value := node.CreateBuiltinFunctionIdentifier(lineNum, getTimeBuiltinName(name))
+		members = append(members, node.CreateAssignmentNode(node.CreateIdentifier(lineNum, name), value))
+	}
+
+	return node.CreateModule(lineNum, BUILTIN_TIME, members).Ptr(), nil
+}
+
+func timestampToTime(timestamp float64) time.Time {
+	seconds := math.Floor(timestamp)
+	microseconds := math.Round((timestamp - seconds) * 1e6)
+	return time.Unix(int64(seconds), int64(microseconds)*int64(time.Microsecond)).UTC()
+}
+
+func timeToTimestamp(t time.Time) float64 {
+	return float64(t.Unix()) + float64(t.Nanosecond()/int(time.Microsecond))/1e6
+}
+
+func secondsToDuration(lineNum int, name string, seconds float64) (time.Duration, error) {
+	// Durations are stored in nanoseconds, so they must fit in an int64
+	nanoseconds := math.Round(seconds * float64(time.Second))
+	if math.IsNaN(nanoseconds) || nanoseconds < math.MinInt64 || nanoseconds >= math.MaxInt64 {
+		return 0, utils.CreateError(
+			lineNum,
+			"%s duration is out of range (about 292 years), got %s seconds",
+			getTimeBuiltinName(name),
+			utils.FloatToString(seconds),
+		)
+	}
+	return time.Duration(nanoseconds), nil
+}
+
+func evaluateBuiltinTimeNow(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	return createNumber(lineNum, timeToTimestamp(eval.clock.Now())).Ptr(), nil
+}
+
+func evaluateBuiltinTimeMonotonic(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	/*
+		Seconds since the program started. Unlike "time.now", this value is not affected by changes to the system time,
+		so it should be used for measuring how long something takes.
+	*/
+	return createNumber(lineNum, eval.clock.Now().Sub(eval.clockStart).Seconds()).Ptr(), nil
+}
+
+func evaluateBuiltinTimeSleep(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	values, err := eval.evaluateNumbers(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	if values[0] < 0 {
+		return nil, utils.CreateError(lineNum, "cannot sleep for a negative duration (%s)", utils.FloatToString(values[0]))
+	}
+
+	duration, err := secondsToDuration(lineNum, TIME_SLEEP, values[0])
+	if err != nil {
+		return nil, err
+	}
+
+	eval.clock.Sleep(duration)
+	return node.CreateBlockStatementReturnValue(lineNum, nil).Ptr(), nil
+}
+
+func evaluateBuiltinTimeFormat(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Format a timestamp as an ISO-8601 string in UTC (e.g., "2023-11-14T22:13:20Z")
+	values, err := eval.evaluateNumbers(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	formatted := timestampToTime(values[0]).Format(time.RFC3339Nano)
+	return node.CreateRawString(lineNum, formatted).Ptr(), nil
+}
+
+func evaluateBuiltinTimeParse(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Return a monad containing the timestamp, or an empty monad if the string is not a valid ISO-8601 timestamp
+	str, err := checkType(callParameters[0], node.STRING)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, str.Value); err == nil {
+			timestamp := createNumber(lineNum, timeToTimestamp(t))
+			return node.CreateMonad(lineNum, &timestamp).Ptr(), nil
+		}
+	}
+	return node.CreateMonad(lineNum, nil).Ptr(), nil
+}
+
+func evaluateBuiltinTimeParseDuration(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Return a monad containing the number of seconds in a duration like "1h30m" or "250ms", or an empty monad
+	str, err := checkType(callParameters[0], node.STRING)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := time.ParseDuration(str.Value)
+	if err != nil {
+		return node.CreateMonad(lineNum, nil).Ptr(), nil
+	}
+
+	seconds := createNumber(lineNum, duration.Seconds())
+	return node.CreateMonad(lineNum, &seconds).Ptr(), nil
+}
+
+func evaluateBuiltinTimeFormatDuration(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Format a number of seconds as a duration (e.g., 5400 is "1h30m0s")
+	values, err := eval.evaluateNumbers(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := secondsToDuration(lineNum, TIME_FORMAT_DURATION, values[0])
+	if err != nil {
+		return nil, err
+	}
+	return node.CreateRawString(lineNum, duration.String()).Ptr(), nil
+}
+
+func evaluateBuiltinTimeAddDate(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	/*
+		Add years, months and days to a timestamp in UTC. Days that do not exist are normalized, so adding one month to
+		January 31 is March 2 or 3.
+	*/
+	timestamp, err := eval.evaluateNumbers(callParameters[:1])
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := eval.evaluateIntegers(lineNum, callParameters[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	t := timestampToTime(timestamp[0]).AddDate(values[0], values[1], values[2])
+	return createNumber(lineNum, timeToTimestamp(t)).Ptr(), nil
+}
+
+func evaluateBuiltinTimeParts(eval *evaluator, lineNum int, callParameters []node.Node) (*node.Node, error) {
+	// Return a map with the parts of a timestamp in UTC. Weekdays start at 0 for Sunday.
+	values, err := eval.evaluateNumbers(callParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	t := timestampToTime(values[0])
+	parts := []struct {
+		Name  string
+		Value float64
+	}{
+		{Name: "year", Value: float64(t.Year())},
+		{Name: "month", Value: float64(t.Month())},
+		{Name: "day", Value: float64(t.Day())},
+		{Name: "hour", Value: float64(t.Hour())},
+		{Name: "minute", Value: float64(t.Minute())},
+		{Name: "second", Value: float64(t.Second()) + float64(t.Nanosecond())/1e9},
+		{Name: "weekday", Value: float64(t.Weekday())},
+		{Name: "day_of_year", Value: float64(t.YearDay())},
+	}
+
+	entries := []node.Node{}
+	for _, part := range parts {
+		entries = append(entries, node.CreateMapEntry(lineNum, part.Name, createNumber(lineNum, part.Value)))
+	}
+	return node.CreateMap(lineNum, entries).Ptr(), nil
+}
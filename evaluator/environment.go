@@ -8,10 +8,14 @@ import (
 type environment struct {
 	identifiers map[string]node.Node
 	parentEnv   *environment
+	modulePath  string // Absolute path of the module the code running in the environment belongs to; empty for the main program
 }
 
 func CreateEnvironment(pEnv *environment) environment {
 	env := environment{identifiers: map[string]node.Node{}, parentEnv: pEnv}
+	if pEnv != nil {
+		env.modulePath = pEnv.modulePath
+	}
 	return env
 }
 
@@ -19,16 +23,15 @@ func (e *environment) SetIdentifier(key string, value node.Node) {
 	e.identifiers[key] = value
 }
 
-func (e *environment) GetIdentifier(key node.Node) (*node.Node, error) {
-	identifierName := key.Value
+func (e *environment) GetIdentifier(lineNum int, identifierName string) (*node.Node, error) {
 	env := e
 
 	for env != nil {
 		if value, ok := env.identifiers[identifierName]; ok {
-			value.LineNum = key.LineNum
+			value.LineNum = lineNum
 			return &value, nil
 		}
 		env = env.parentEnv
 	}
-	return nil, utils.CreateError(key.LineNum, "undefined identifier: %s", identifierName)
+	return nil, utils.CreateError(lineNum, "undefined identifier: %s", identifierName)
 }
@@ -1,6 +1,7 @@
 package evaluator
 
 import (
+	"boomerang/ast"
 	"boomerang/node"
 	"boomerang/tokens"
 	"boomerang/utils"
@@ -11,28 +12,61 @@ import (
 )
 
 type evaluator struct {
-	ast []node.Node
-	env environment
+	statements []ast.Statement
+	env        environment
+	modules    *moduleLoader
+	directory  string      // Directory of the module being evaluated; empty for the main program
+	exports    []string    // Names exported from the global scope with "export"
+	rng        *rand.Rand  // Source for the random builtins; shared with imported modules
+	fileRoot   string      // Directory the filesystem builtins can access; empty if filesystem access is disabled
+	args       []string    // Command-line arguments passed to the program
+	clock      Clock       // Source of the current time for the time builtins
+	clockStart time.Time   // Time the program started, according to "clock"
+	regexCache *regexCache // Compiled patterns for the regex builtins; shared with imported modules
 }
 
-func NewEvaluator(ast []node.Node) evaluator {
-
-	rand.Seed(time.Now().UnixNano()) // for builtin "random" function
-
+/*
+NewEvaluator creates an evaluator for a program (see "parser.ParseTyped"). Programs are evaluated from the typed AST,
+and the values they create (numbers, lists, functions, etc.) are "node.Node" trees.
+*/
+func NewEvaluator(statements []ast.Statement) evaluator {
 	return evaluator{
-		ast: ast,
-		env: CreateEnvironment(nil),
+		statements: statements,
+		env:        CreateEnvironment(nil),
+		modules:    newModuleLoader(),
+		rng:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		clock:      systemClock{},
+		clockStart: time.Now(),
+		regexCache: newRegexCache(),
 	}
 }
 
+// SetRandomSeed seeds the evaluator's random number generator so the random builtins produce the same values on
+// every run.
+func (e *evaluator) SetRandomSeed(seed int64) {
+	e.rng.Seed(seed)
+}
+
 func (e *evaluator) Evaluate() ([]node.Node, error) {
-	return e.evaluateGlobalStatements(e.ast)
+	return e.evaluateGlobalStatements(e.statements)
 }
 
-func (e *evaluator) evaluateGlobalStatements(stmts []node.Node) ([]node.Node, error) {
+func (e *evaluator) evaluateGlobalStatements(stmts []ast.Statement) ([]node.Node, error) {
+	if err := e.declareFunctions(stmts); err != nil {
+		return nil, err
+	}
+
 	results := []node.Node{}
 	for _, stmt := range stmts {
-		result, err := e.evaluateStatement(stmt)
+		var result *node.Node
+		var err error
+
+		// Exports are only allowed in the global scope
+		if export, ok := stmt.(*ast.Export); ok {
+			result, err = e.evaluateExportStatement(export)
+		} else {
+			result, err = e.evaluateStatement(stmt)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -48,16 +82,16 @@ func (e *evaluator) evaluateGlobalStatements(stmts []node.Node) ([]node.Node, er
 	return results, nil
 }
 
-func (e *evaluator) evaluateBlockStatements(statements node.Node) (*node.Node, error) {
+func (e *evaluator) evaluateBlockStatements(block *ast.BlockStatements) (*node.Node, error) {
 
-	if statements.Type != node.BLOCK_STATEMENTS {
-		panic(fmt.Sprintf("invalid type for block statement: %s", statements.ErrorDisplay()))
+	if err := e.declareFunctions(block.Statements); err != nil {
+		return nil, err
 	}
 
 	var returnValue *node.Node
-	lineNum := statements.LineNum
-	for _, statement := range statements.Params {
-		lineNum = statement.LineNum
+	lineNum := block.LineNum
+	for _, statement := range block.Statements {
+		lineNum = statement.Line()
 		result, err := e.evaluateStatement(statement)
 		if err != nil {
 			return nil, err
@@ -76,14 +110,83 @@ func (e *evaluator) evaluateBlockStatements(statements node.Node) (*node.Node, e
 	return returnValue, nil
 }
 
-func (e *evaluator) evaluateStatement(stmt node.Node) (*node.Node, error) {
+func (e *evaluator) declareFunctions(stmts []ast.Statement) error {
+	/*
+		Named function declarations are bound before any statements in the same block run, so functions can call
+		each other regardless of the order they are declared in. For example:
+		```
+		func is_even(n) { ... is_odd <- (n - 1) ... };
+		func is_odd(n) { ... is_even <- (n - 1) ... };
+		```
+	*/
+	for _, stmt := range stmts {
+		if export, ok := stmt.(*ast.Export); ok {
+			stmt = export.Statement
+		}
+
+		if function, ok := stmt.(*ast.Function); ok && function.Name != "" {
+			if err := e.declareFunction(function); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *evaluator) declareFunction(function *ast.Function) error {
+	// Check that the user hasn't declared a function with the same name as a builtin construct
+	if IsBuiltin(function.Name) {
+		return utils.CreateError(
+			function.LineNum,
+			"%#v is a builtin function or variable",
+			function.Name,
+		)
+	}
+	e.env.SetIdentifier(function.Name, e.evaluateFunction(function))
+	return nil
+}
+
+func (e *evaluator) evaluateFunction(function *ast.Function) node.Node {
+	/*
+		Function values store their definition as a "node.Node" tree, like other values, so they can be printed,
+		compared and passed to builtins. The definition is converted back to the typed AST when the function is called
+		(see "evaluateCall").
+
+		Functions defined in modules are called in the scope of that module. This includes functions created while a
+		module's function is running, even when it was called from another module.
+	*/
+	value := ast.ToNode(function)
+	if e.env.modulePath != "" {
+		return node.CreateModuleFunction(value, e.env.modulePath)
+	}
+	return value
+}
+
+func (e *evaluator) evaluateStatement(stmt ast.Statement) (*node.Node, error) {
+
+	switch stmt := stmt.(type) {
 
-	switch stmt.Type {
+	case *ast.Break:
+		return node.CreateBreakStatement(stmt.LineNum).Ptr(), nil
 
-	case node.BREAK, node.CONTINUE, node.RETURN:
-		return &stmt, nil
+	case *ast.Continue:
+		return node.CreateContinueStatement(stmt.LineNum).Ptr(), nil
 
-	case node.WHILE_LOOP:
+	case *ast.Return:
+		// The return value is evaluated here, and the function returns it once the blocks it is in have finished
+		value, err := e.evaluateExpression(stmt.Value)
+		if err != nil {
+			return nil, err
+		}
+		return node.CreateReturnStatement(stmt.LineNum, *value).Ptr(), nil
+
+	case *ast.Import:
+		return nil, e.evaluateImportStatement(stmt)
+
+	case *ast.Export:
+		return nil, utils.CreateError(stmt.LineNum, "%s statements only allowed in the global scope", tokens.EXPORT_TOKEN.Literal)
+
+	case *ast.WhileLoop:
 		returnValue, err := e.evaluateWhileLoop(stmt)
 		if err != nil {
 			return nil, err
@@ -94,92 +197,90 @@ func (e *evaluator) evaluateStatement(stmt node.Node) (*node.Node, error) {
 		}
 		return nil, nil
 
-	default:
+	case ast.Expression:
 		return e.evaluateExpression(stmt)
+
+	default:
+		// This error will only happen if the developer has not implemented a statement type
+		panic(fmt.Sprintf("invalid statement %T", stmt))
 	}
 }
 
-func (e *evaluator) evaluateAssignmentStatement(stmt node.Node) (*node.Node, error) {
-	variable := stmt.GetParam(node.ASSIGN_STMT_IDENTIFIER)       // identifier, list of identifiers
-	value, err := e.evaluateExpression(stmt.GetParam(node.EXPR)) // actual value(s)
+func (e *evaluator) evaluateAssignmentStatement(stmt *ast.Assignment) (*node.Node, error) {
+	value, err := e.evaluateExpression(stmt.Value) // actual value(s)
 	if err != nil {
 		return nil, err
 	}
+	return e.assign(stmt.LineNum, stmt.Target, *value)
+}
+
+func (e *evaluator) assign(lineNum int, variable ast.Expression, value node.Node) (*node.Node, error) {
+	// "variable" is an identifier or a list of identifiers
+
+	switch variable := variable.(type) {
 
-	if variable.Type == node.IDENTIFIER {
+	case *ast.Identifier:
 		// Check that the user hasn't created a variable with the same name as a builtin construct
-		if IsBuiltin(variable.Value) {
+		if IsBuiltin(variable.Name) {
 			return nil, utils.CreateError(
-				stmt.LineNum,
+				lineNum,
 				"%#v is a builtin function or variable",
-				variable.Value,
+				variable.Name,
 			)
 		}
 
-		value, err := e.evaluateExpression(*value)
-		if err != nil {
-			return nil, err
-		}
-		e.env.SetIdentifier(variable.Value, *value)
-		return value, nil
+		e.env.SetIdentifier(variable.Name, value)
+		return &value, nil
 
-	} else if variable.Type == node.LIST && value.Type == node.LIST {
-
-		evaluatedValues := []node.Node{}
-
-		assignments := e.partitionAssignmentVariables(variable, *value)
-		for _, identifierPair := range assignments {
-
-			identifier := identifierPair[0]
-			identifierValue := identifierPair[1]
-
-			if identifier.Type != node.IDENTIFIER {
-				return nil, utils.CreateError(identifier.LineNum, "invalid type for assignment: %s", identifier.ErrorDisplay())
-			}
+	case *ast.List:
+		if value.Type != node.LIST {
+			break
+		}
 
-			identifierValueEvaluated, err := e.evaluateExpression(identifierValue)
-			if err != nil {
-				return nil, err
+		values := e.partitionAssignmentValues(variable.Elements, value)
+		for i, element := range variable.Elements {
+			identifier, ok := element.(*ast.Identifier)
+			if !ok {
+				return nil, utils.CreateError(element.Line(), "invalid type for assignment: %s", errorDisplay(ast.ToNode(element)))
 			}
-			e.env.SetIdentifier(identifier.Value, *identifierValueEvaluated)
-			evaluatedValues = append(evaluatedValues, *identifierValueEvaluated)
+			e.env.SetIdentifier(identifier.Name, values[i])
 		}
 
 		// multiple assignment expressions return the full list on the right side of the assignment operator
-		return node.CreateList(stmt.LineNum, evaluatedValues).Ptr(), nil
+		return node.CreateList(lineNum, values).Ptr(), nil
 	}
 
 	return nil, utils.CreateError(
-		stmt.LineNum,
+		lineNum,
 		"invalid type for assignment: %s",
-		variable.ErrorDisplay(),
+		errorDisplay(ast.ToNode(variable)),
 	)
 }
 
-func (e *evaluator) partitionAssignmentVariables(identifiers, values node.Node) [][]node.Node {
+func (e *evaluator) partitionAssignmentValues(identifiers []ast.Expression, values node.Node) []node.Node {
 
-	var identifierValuePairs = [][]node.Node{} // Map identifiers to their corresponding values
+	var identifierValues = []node.Node{} // The value assigned to each identifier, in the same order
 
 	/*
 		Iterate though first (n - 1) identifiers. If an identifier has an associated value, pair the identifier with
 		that value. Otherwise, pair the identifier with an empty monad object.
 	*/
 	index := 0
-	for ; index < len(identifiers.Params)-1; index++ {
-		identifier := identifiers.Params[index]
+	for ; index < len(identifiers)-1; index++ {
+		identifier := identifiers[index]
 
 		var value node.Node
 		if index >= len(values.Params) {
 			// If the number of identifiers is greater than the number of values, set subsequent variables to an empty monad.
-			value = node.CreateMonad(identifier.LineNum, nil)
+			value = node.CreateMonad(identifier.Line(), nil)
 		} else {
 			value = values.Params[index]
 		}
 
-		identifierValuePairs = append(identifierValuePairs, []node.Node{identifier, value})
+		identifierValues = append(identifierValues, value)
 	}
 
-	lastIdentifier := identifiers.Params[index]
+	lastIdentifier := identifiers[index]
 	var lastIdentifierValue node.Node
 
 	/*
@@ -187,7 +288,7 @@ func (e *evaluator) partitionAssignmentVariables(identifiers, values node.Node)
 		identifiers than values, so pair that identifier with an empty monad object.
 	*/
 	if index >= len(values.Params) {
-		lastIdentifierValue = node.CreateMonad(lastIdentifier.LineNum, nil)
+		lastIdentifierValue = node.CreateMonad(lastIdentifier.Line(), nil)
 	} else {
 		/*
 			Otherwise, if the length of the remaining values is 1, pair that value with the last identifier. If the number of
@@ -198,26 +299,23 @@ func (e *evaluator) partitionAssignmentVariables(identifiers, values node.Node)
 		case 1:
 			lastIdentifierValue = values.Params[index]
 		default:
-			lastIdentifierValue = node.CreateList(lastIdentifier.LineNum, values.Params[index:])
+			lastIdentifierValue = node.CreateList(lastIdentifier.Line(), values.Params[index:])
 		}
 	}
-	identifierValuePairs = append(identifierValuePairs, []node.Node{lastIdentifier, lastIdentifierValue})
+	identifierValues = append(identifierValues, lastIdentifierValue)
 
-	return identifierValuePairs
+	return identifierValues
 }
 
-func (e *evaluator) evaluateWhileLoop(stmt node.Node) (*node.Node, error) {
-	condition := stmt.GetParam(node.WHILE_LOOP_CONDITION)
-	statements := stmt.GetParam(node.WHILE_LOOP_STATEMENTS)
-
+func (e *evaluator) evaluateWhileLoop(loop *ast.WhileLoop) (*node.Node, error) {
 	for {
-		evaluatedCondition, err := e.evaluateExpression(condition)
+		evaluatedCondition, err := e.evaluateExpression(loop.Condition)
 		if err != nil {
 			return nil, err
 		}
 
-		if evaluatedCondition.Equals(node.CreateBooleanTrue(stmt.LineNum)) {
-			stmt, err := e.evaluateBlockStatements(statements)
+		if evaluatedCondition.Equals(node.CreateBooleanTrue(loop.LineNum)) {
+			stmt, err := e.evaluateBlockStatements(loop.Body)
 			if err != nil {
 				return nil, err
 			}
@@ -242,99 +340,116 @@ func (e *evaluator) evaluateWhileLoop(stmt node.Node) (*node.Node, error) {
 	return nil, nil
 }
 
-func (e *evaluator) evaluateExpression(expr node.Node) (*node.Node, error) {
+func (e *evaluator) evaluateExpression(expr ast.Expression) (*node.Node, error) {
 
-	switch expr.Type {
+	switch expr := expr.(type) {
 
-	case node.NUMBER, node.BOOLEAN, node.FUNCTION, node.BUILTIN_FUNCTION, node.MONAD:
-		// Builtin functions will be evaluated later during a function call
-		return &expr, nil
+	case *ast.Number:
+		return node.CreateNumber(expr.LineNum, expr.Value).Ptr(), nil
+
+	case *ast.Boolean:
+		return createBoolean(expr.LineNum, expr.Value).Ptr(), nil
+
+	case *ast.String:
+		return node.CreateRawString(expr.LineNum, expr.Value).Ptr(), nil
 
-	case node.STRING:
+	case *ast.InterpolatedString:
 		return e.evaluateString(expr)
 
-	case node.LIST:
-		return e.evaluateParameter(expr)
+	case *ast.BuiltinFunction:
+		// Builtin functions will be evaluated later during a function call
+		return node.CreateBuiltinFunctionIdentifier(expr.LineNum, expr.Name).Ptr(), nil
+
+	case *ast.Function:
+		return e.evaluateFunction(expr).Ptr(), nil
 
-	case node.IDENTIFIER:
-		return e.evaluateIdentifier(expr)
+	case *ast.List:
+		return e.evaluateList(expr)
 
-	case node.BUILTIN_VARIABLE:
-		return evaluateBuiltinFunction(expr.Value, e, expr.LineNum, []node.Node{})
+	case *ast.Identifier:
+		return e.env.GetIdentifier(expr.LineNum, expr.Name) // Get the user-defined variable from the environment
 
-	case node.UNARY_EXPR:
+	case *ast.BuiltinVariable:
+		return evaluateBuiltinFunction(expr.Name, e, expr.LineNum, []node.Node{})
+
+	case *ast.UnaryExpression:
 		return e.evaluateUnaryExpression(expr)
 
-	case node.BIN_EXPR:
+	case *ast.BinaryExpression:
 		return e.evaluateBinaryExpression(expr)
 
-	case node.ASSIGN_STMT:
+	case *ast.Assignment:
 		return e.evaluateAssignmentStatement(expr)
 
-	case node.FUNCTION_CALL:
+	case *ast.FunctionCall:
 		return e.evaluateFunctionCall(expr)
 
-	case node.WHEN:
+	case *ast.When:
 		return e.evaluateWhenExpression(expr)
 
-	case node.FOR_LOOP:
+	case *ast.ForLoop:
 		return e.evaluateForLoop(expr)
 
 	default:
 		// This error will only happen if the developer has not implemented an expression type
-		panic(fmt.Sprintf("invalid type %#v", expr.Type))
+		panic(fmt.Sprintf("invalid type %T", expr))
 	}
 }
 
-func (e *evaluator) evaluateIdentifier(identifierExpression node.Node) (*node.Node, error) {
-	return e.env.GetIdentifier(identifierExpression) // Get the user-defined variable from the environment
+func (e *evaluator) evaluateList(listExpression *ast.List) (*node.Node, error) {
+	values, err := e.evaluateExpressions(listExpression.Elements)
+	if err != nil {
+		return nil, err
+	}
+	return node.CreateList(listExpression.LineNum, values).Ptr(), nil
 }
 
-func (e *evaluator) evaluateParameter(parameterExpression node.Node) (*node.Node, error) {
+func (e *evaluator) evaluateExpressions(expressions []ast.Expression) ([]node.Node, error) {
 
-	evaluatedParameters := []node.Node{}
+	values := []node.Node{}
 
-	for i := range parameterExpression.Params {
+	for _, expression := range expressions {
 
-		parameter, err := e.evaluateExpression(parameterExpression.Params[i])
+		value, err := e.evaluateExpression(expression)
 		if err != nil {
 			return nil, err
 		}
-		evaluatedParameters = append(evaluatedParameters, *parameter)
+		values = append(values, *value)
 	}
-	return node.CreateList(parameterExpression.LineNum, evaluatedParameters).Ptr(), nil
+	return values, nil
 }
 
-func (e *evaluator) evaluateString(stringExpression node.Node) (*node.Node, error) {
-	for i, param := range stringExpression.Params {
-		value, err := e.evaluateExpression(param)
-		if err != nil {
-			return nil, err
-		}
+func (e *evaluator) evaluateString(stringExpression *ast.InterpolatedString) (*node.Node, error) {
+	// Combine the text and the formatted results of the interpolated expressions
+	var str strings.Builder
 
-		// With string interpolation, the quotes around strings should not be included in the final string
-		var replacementString string
-		if value.Type == node.STRING {
-			replacementString = value.Value
-		} else {
-			replacementString = value.String()
+	for _, part := range stringExpression.Parts {
+		switch part := part.(type) {
+
+		case *ast.String:
+			str.WriteString(part.Value)
+
+		case *ast.Interpolation:
+			value, err := e.evaluateExpression(part.Expression)
+			if err != nil {
+				return nil, err
+			}
+
+			formattedValue, err := formatValue(part.LineNum, *value, part.FormatSpec)
+			if err != nil {
+				return nil, err
+			}
+			str.WriteString(formattedValue)
 		}
-		stringExpression.Value = strings.Replace(stringExpression.Value, fmt.Sprintf("<%d>", i), replacementString, 1)
 	}
 
-	return node.CreateRawString(stringExpression.LineNum, stringExpression.Value).Ptr(), nil
+	return node.CreateRawString(stringExpression.LineNum, str.String()).Ptr(), nil
 }
 
-func (e *evaluator) evaluateForLoop(expr node.Node) (*node.Node, error) {
-	lineNum := expr.LineNum
-
-	elementVariableExpression := expr.GetParam(node.FOR_LOOP_ELEM_ASSIGN)
-	if err := utils.CheckTypeError(lineNum, elementVariableExpression.Type, node.ASSIGN_STMT); err != nil {
-		return nil, err
-	}
+func (e *evaluator) evaluateForLoop(loop *ast.ForLoop) (*node.Node, error) {
+	lineNum := loop.LineNum
 
-	list := elementVariableExpression.GetParam(node.EXPR)
-	evaluatedList, err := e.evaluateExpression(list)
+	evaluatedList, err := e.evaluateExpression(loop.Values)
 	if err != nil {
 		return nil, err
 	}
@@ -347,21 +462,17 @@ func (e *evaluator) evaluateForLoop(expr node.Node) (*node.Node, error) {
 		)
 	}
 
-	variables := elementVariableExpression.GetParam(node.IDENTIFIER)
-	statements := expr.GetParam(node.BLOCK_STATEMENTS)
-
 	var values = []node.Node{}
 
 	for _, element := range evaluatedList.Params {
 		// Assign the placeholder/element variable to the value of the current list element
-		placeHolderVariable := node.CreateAssignmentNode(variables, element)
-		_, err = e.evaluateStatement(placeHolderVariable)
+		_, err = e.assign(loop.Variables.Line(), loop.Variables, element)
 		if err != nil {
 			return nil, err
 		}
 
 		// Evaluate the block statements in the for-loop
-		result, err := e.evaluateBlockStatements(statements)
+		result, err := e.evaluateBlockStatements(loop.Body)
 		if err != nil {
 			return nil, err
 		}
@@ -383,12 +494,12 @@ func (e *evaluator) evaluateForLoop(expr node.Node) (*node.Node, error) {
 	return node.CreateList(lineNum, values).Ptr(), nil
 }
 
-func (e *evaluator) evaluateUnaryExpression(unaryExpression node.Node) (*node.Node, error) {
-	expression, err := e.evaluateExpression(unaryExpression.GetParam(node.EXPR))
+func (e *evaluator) evaluateUnaryExpression(unaryExpression *ast.UnaryExpression) (*node.Node, error) {
+	expression, err := e.evaluateExpression(unaryExpression.Expression)
 	if err != nil {
 		return nil, err
 	}
-	operator := unaryExpression.GetParam(node.OPERATOR)
+	operator := unaryExpression.Operator
 	if operator.Type == tokens.MINUS {
 
 		if expression.Type != node.NUMBER {
@@ -398,7 +509,7 @@ func (e *evaluator) evaluateUnaryExpression(unaryExpression node.Node) (*node.No
 		if floatValue == nil {
 			return nil, utils.NotANumberError(expression.LineNum, expression.Value)
 		}
-		return node.CreateNumber(unaryExpression.LineNum, utils.FloatToString(-*floatValue)).Ptr(), nil
+		return node.CreateNumber(unaryExpression.LineNum, tokens.FormatNumber(-*floatValue)).Ptr(), nil
 
 	} else if operator.Type == tokens.NOT {
 
@@ -424,22 +535,25 @@ func (e *evaluator) evaluateUnaryExpression(unaryExpression node.Node) (*node.No
 	return nil, utils.CreateError(
 		unaryExpression.LineNum,
 		"invalid unary operator: %s",
-		operator.ErrorDisplay(),
+		errorDisplay(node.CreateTokenNode(operator)),
 	)
 }
 
-func (e *evaluator) evaluateBinaryExpression(binaryExpression node.Node) (*node.Node, error) {
+func (e *evaluator) evaluateBinaryExpression(binaryExpression *ast.BinaryExpression) (*node.Node, error) {
 
-	leftNode := binaryExpression.GetParam(node.LEFT)
-	op := binaryExpression.GetParam(node.OPERATOR)
-	rightNode := binaryExpression.GetParam(node.RIGHT)
+	op := binaryExpression.Operator
 
-	left, err := e.evaluateExpression(leftNode)
+	left, err := e.evaluateExpression(binaryExpression.Left)
 	if err != nil {
 		return nil, err
 	}
 
-	right, err := e.evaluateExpression(rightNode)
+	// The right side of member access is a name, not an expression, so it is not evaluated
+	if op.Type == tokens.PERIOD {
+		return e.evaluateMemberAccess(*left, binaryExpression.Right)
+	}
+
+	right, err := e.evaluateExpression(binaryExpression.Right)
 	if err != nil {
 		return nil, err
 	}
@@ -487,29 +601,31 @@ func (e *evaluator) evaluateBinaryExpression(binaryExpression node.Node) (*node.
 
 	default:
 		return nil, utils.CreateError(
-			op.LineNum,
+			op.LineNumber,
 			"invalid binary operator: %s",
-			op.ErrorDisplay(),
+			errorDisplay(node.CreateTokenNode(op)),
 		)
 	}
 }
 
-func (e *evaluator) evaluateFunctionCall(functionCallExpression node.Node) (*node.Node, error) {
-	callParams := functionCallExpression.GetParam(node.CALL_PARAMS) // Parameters pass to function
-
-	function := functionCallExpression.GetParamByKeys([]string{node.IDENTIFIER, node.FUNCTION})
+func (e *evaluator) evaluateFunctionCall(functionCallExpression *ast.FunctionCall) (*node.Node, error) {
+	function, err := e.evaluateExpression(functionCallExpression.Function)
+	if err != nil {
+		return nil, err
+	}
 
-	if function.Type == node.BUILTIN_FUNCTION {
-		return evaluateBuiltinFunction(function.Value, e, function.LineNum, callParams.Params)
+	callParams, err := e.evaluateExpressions(functionCallExpression.Arguments) // Parameters pass to function
+	if err != nil {
+		return nil, err
 	}
+	return e.evaluateCall(*function, callParams)
+}
 
-	if function.Type == node.IDENTIFIER {
-		// If the function object is an identifier, retireve the actual function object from the environment
-		identifierFunction, err := e.env.GetIdentifier(function)
-		if err != nil {
-			return nil, err
-		}
-		function = *identifierFunction
+func (e *evaluator) evaluateCall(function node.Node, callParams []node.Node) (*node.Node, error) {
+	// Call a function value with already-evaluated parameters
+
+	if function.Type == node.BUILTIN_FUNCTION {
+		return evaluateBuiltinFunction(function.Value, e, function.LineNum, callParams)
 	}
 
 	// Assert that the function object is, in fact, a callable function
@@ -521,6 +637,13 @@ func (e *evaluator) evaluateFunctionCall(functionCallExpression node.Node) (*nod
 		)
 	}
 
+	// Function values store their definition as a "node.Node" tree (see "evaluateFunction")
+	statement, err := ast.FromNode(function)
+	if err != nil {
+		return nil, utils.CreateError(function.LineNum, "invalid function: %s", err.Error())
+	}
+	definition := statement.(*ast.Function)
+
 	/*
 		Create a new environment/scope for the function call. This ensures that variables defined within the function
 		are not accessible outside of that function.
@@ -528,110 +651,112 @@ func (e *evaluator) evaluateFunctionCall(functionCallExpression node.Node) (*nod
 	oldEnv := e.env
 	e.env = CreateEnvironment(&oldEnv)
 
+	// Functions defined in a module can access that module's global variables, even when called from another module
+	modulePath := function.GetFunctionModule()
+	if modulePath != "" {
+		moduleEnv := e.modules.environments[modulePath]
+		e.env = CreateEnvironment(&moduleEnv)
+	}
+	e.env.modulePath = modulePath
+
+	// Named functions can always refer to themselves, even if the name they were declared with has been reassigned
+	if definition.Name != "" {
+		e.env.SetIdentifier(definition.Name, function)
+	}
+
+	// Errors in a function from another module (or from the main program) are labeled with the function's module
+	labelError := func(err error) error {
+		if modulePath != oldEnv.modulePath {
+			return moduleError(modulePath, err)
+		}
+		return err
+	}
+
 	// Evaluate function/function call parameters
-	if err := e.evaluateParameters(function, callParams); err != nil {
-		return nil, err
+	if err := e.evaluateParameters(definition, callParams); err != nil {
+		return nil, labelError(err)
 	}
 
 	// Evaluate what the function will return
-	wrappedReturnValue, err := e.evaluateFunctionReturnValue(function)
+	wrappedReturnValue, err := e.evaluateFunctionReturnValue(definition)
 	if err != nil {
-		return nil, err
+		return nil, labelError(err)
 	}
 
 	// Reset environment back to original scope environment
-	e.env = *e.env.parentEnv
+	e.env = oldEnv
 
 	return wrappedReturnValue, nil
 }
 
-func (e *evaluator) evaluateParameters(function, callParams node.Node) error {
-
-	functionParams := function.GetParam(node.LIST) // Parameters included in function definition
+func (e *evaluator) evaluateParameters(function *ast.Function, callParams []node.Node) error {
 
 	// Keyword arguments do not count as arguments passed to the function
 	callParamsIndex := 0
-	for _, functionParam := range functionParams.Params {
-
-		switch functionParam.Type {
-
-		case node.IDENTIFIER:
-			if callParamsIndex >= len(callParams.Params) {
-				/*
-					The user has overwritten default parameter values, but has not provided value(s) for additional parameters that do not
-					have default values. For example:
-					```
-					f = func(a=1, b=2, c) { ... };
-					f <- (3, 4);
-					```
-
-					"a" and "b" are overwritten with "3" and "4", respectively, but "c" does not have a default value, and the user has
-					only provided two values in the function call.
-				*/
-				return utils.CreateError(
-					function.LineNum,
-					"Function paramter %#v does not have a value. Either add %d more values to the function call or assign %#v a default value in the function definition parameters.",
-					functionParam.Value,
-					callParamsIndex-len(callParams.Params)+1,
-					functionParam.Value,
-				)
-			}
+	for _, functionParam := range function.Parameters {
 
-			parameterValue := callParams.Params[callParamsIndex]
+		parameterName := functionParam.Name.Name
 
-			evaluatedParameterValue, err := e.evaluateExpression(parameterValue)
+		if callParamsIndex < len(callParams) {
+			// The user is providing a value, or overwriting a default parameter value
+			e.env.SetIdentifier(parameterName, callParams[callParamsIndex])
+
+		} else if functionParam.Default != nil {
+			// The user is not overriding a default parameter value
+			defaultValue, err := e.evaluateExpression(functionParam.Default)
 			if err != nil {
 				return err
 			}
-			e.env.SetIdentifier(functionParam.Value, *evaluatedParameterValue)
-
-		case node.ASSIGN_STMT:
-			if callParamsIndex < len(callParams.Params) {
-				// The user is overwriting a default parameter value
-				parameterValue := callParams.Params[callParamsIndex]
-
-				evaluatedParameterValue, err := e.evaluateExpression(parameterValue)
-				if err != nil {
-					return err
-				}
-				parameterName := functionParam.GetParam(node.ASSIGN_STMT_IDENTIFIER).Value
-				e.env.SetIdentifier(parameterName, *evaluatedParameterValue)
-
-			} else {
-				// The user is not overriding a default parameter value
-				e.evaluateExpression(functionParam)
-			}
+			e.env.SetIdentifier(parameterName, *defaultValue)
+
+		} else {
+			/*
+				The user has overwritten default parameter values, but has not provided value(s) for additional parameters that do not
+				have default values. For example:
+				```
+				f = func(a=1, b=2, c) { ... };
+				f <- (3, 4);
+				```
+
+				"a" and "b" are overwritten with "3" and "4", respectively, but "c" does not have a default value, and the user has
+				only provided two values in the function call.
+			*/
+			return utils.CreateError(
+				function.LineNum,
+				"Function paramter %#v does not have a value. Either add %d more values to the function call or assign %#v a default value in the function definition parameters.",
+				parameterName,
+				callParamsIndex-len(callParams)+1,
+				parameterName,
+			)
 		}
 		callParamsIndex += 1
 	}
 
-	if callParamsIndex < len(callParams.Params) {
+	if callParamsIndex < len(callParams) {
 		/*
 			After evaluating each expression, the value of "callParamsIndex" will be the expected number of call parameters (the
-			number of parameters in the function definition), and "len(callParams.Params)" will be the actual number of call
+			number of parameters in the function definition), and "len(callParams)" will be the actual number of call
 			parameters provided (the number of values in the function call).
 
-			If "callParamsIndex" is less than "len(callParams.Params)", the user has not provided enough values to the function call.
+			If "callParamsIndex" is less than "len(callParams)", the user has not provided enough values to the function call.
 		*/
 		return utils.CreateError(
 			function.LineNum,
 			"expected %d arguments, got %d",
 			callParamsIndex,
-			len(callParams.Params),
+			len(callParams),
 		)
 	}
 	return nil
 }
 
-func (e *evaluator) evaluateFunctionReturnValue(function node.Node) (*node.Node, error) {
-
-	functionStatements := function.GetParam(node.STMTS)
+func (e *evaluator) evaluateFunctionReturnValue(function *ast.Function) (*node.Node, error) {
 
-	if len(functionStatements.Params) == 0 {
+	if len(function.Body.Statements) == 0 {
 		return node.CreateBlockStatementReturnValue(function.LineNum, nil).Ptr(), nil
 	}
 
-	functionReturnValue, err := e.evaluateBlockStatements(functionStatements)
+	functionReturnValue, err := e.evaluateBlockStatements(function.Body)
 	if err != nil {
 		return nil, err
 	}
@@ -643,11 +768,9 @@ func (e *evaluator) evaluateFunctionReturnValue(function node.Node) (*node.Node,
 		return functionReturnValue, nil
 
 	case node.RETURN:
-		returnValueExpression, err := e.evaluateExpression(functionReturnValue.GetParam(node.EXPR))
-		if err != nil {
-			return nil, err
-		}
-		return node.CreateBlockStatementReturnValue(functionReturnValue.LineNum, returnValueExpression).Ptr(), nil
+		// The return value was evaluated by the return statement (see "evaluateStatement")
+		returnValue := functionReturnValue.GetParam(node.EXPR)
+		return node.CreateBlockStatementReturnValue(functionReturnValue.LineNum, &returnValue).Ptr(), nil
 
 	default:
 		// When the function returns no values
@@ -724,6 +847,15 @@ func (e *evaluator) compareIn(left node.Node, right node.Node) (*node.Node, erro
 
 func (e *evaluator) index(left node.Node, right node.Node) (*node.Node, error) {
 
+	// Maps are indexed by key
+	if left.Type == node.MAP && right.Type == node.STRING {
+		value, ok := left.GetMapValue(right.Value)
+		if !ok {
+			return nil, utils.CreateError(right.LineNum, "key %#v not found in map", right.Value)
+		}
+		return value, nil
+	}
+
 	if right.Type == node.NUMBER {
 		index := utils.ConvertStringToInteger(right.Value)
 		if index == nil {
@@ -738,10 +870,12 @@ func (e *evaluator) index(left node.Node, right node.Node) (*node.Node, error) {
 			}
 			return left.Params[indexLiteral].Ptr(), nil
 		case node.STRING:
-			if err := utils.CheckOutOfRange(left.LineNum, indexLiteral, len(left.Value)); err != nil {
+			// Strings are indexed by character, not byte, so multi-byte characters are not split
+			characters := []rune(left.Value)
+			if err := utils.CheckOutOfRange(left.LineNum, indexLiteral, len(characters)); err != nil {
 				return nil, err
 			}
-			character := left.Value[indexLiteral : indexLiteral+1]
+			character := string(characters[indexLiteral])
 			return node.CreateRawString(left.LineNum, character).Ptr(), nil
 		}
 	}
@@ -769,7 +903,10 @@ func (e *evaluator) add(left node.Node, right node.Node) (*node.Node, error) {
 
 		result := *leftValue + *rightValue
 
-		return node.CreateNumber(left.LineNum, utils.FloatToString(result)).Ptr(), nil
+		return node.CreateNumber(left.LineNum, tokens.FormatNumber(result)).Ptr(), nil
+
+	} else if left.Type == node.STRING && right.Type == node.STRING {
+		return node.CreateRawString(left.LineNum, left.Value+right.Value).Ptr(), nil
 	}
 	return nil, utils.CreateError(
 		left.LineNum,
@@ -794,7 +931,7 @@ func (e *evaluator) subtract(left node.Node, right node.Node) (*node.Node, error
 
 		result := *leftValue - *rightValue
 
-		return node.CreateNumber(left.LineNum, utils.FloatToString(result)).Ptr(), nil
+		return node.CreateNumber(left.LineNum, tokens.FormatNumber(result)).Ptr(), nil
 	}
 	return nil, utils.CreateError(
 		left.LineNum,
@@ -819,7 +956,7 @@ func (e *evaluator) multuply(left node.Node, right node.Node) (*node.Node, error
 
 		result := *leftValue * *rightValue
 
-		return node.CreateNumber(left.LineNum, utils.FloatToString(result)).Ptr(), nil
+		return node.CreateNumber(left.LineNum, tokens.FormatNumber(result)).Ptr(), nil
 	}
 	return nil, utils.CreateError(
 		left.LineNum,
@@ -847,7 +984,7 @@ func (e *evaluator) divide(left node.Node, right node.Node) (*node.Node, error)
 		}
 
 		result := *leftValue / *rightValue
-		return node.CreateNumber(left.LineNum, utils.FloatToString(result)).Ptr(), nil
+		return node.CreateNumber(left.LineNum, tokens.FormatNumber(result)).Ptr(), nil
 	}
 	return nil, utils.CreateError(
 		left.LineNum,
@@ -887,9 +1024,7 @@ func (e *evaluator) modulo(left, right node.Node) (*node.Node, error) {
 
 func (e *evaluator) send(left node.Node, right node.Node) (*node.Node, error) {
 	if (left.Type == node.FUNCTION || left.Type == node.BUILTIN_FUNCTION) && right.Type == node.LIST {
-		// Need to include "node.IDENTIFIER" check for builtin functions
-		functionCall := node.CreateFunctionCall(left.LineNum, left, right.Params)
-		return e.evaluateExpression(functionCall)
+		return e.evaluateCall(left, right.Params)
 
 	} else if left.Type == node.LIST {
 
@@ -946,43 +1081,42 @@ func (e *evaluator) booleanAnd(left node.Node, right node.Node) (*node.Node, err
 	return node.CreateBooleanFalse(left.LineNum).Ptr(), nil
 }
 
-func (e *evaluator) evaluateWhenExpression(whenExpression node.Node) (*node.Node, error) {
+func (e *evaluator) evaluateWhenExpression(whenExpression *ast.When) (*node.Node, error) {
 
-	expression, err := e.evaluateExpression(whenExpression.GetParam(node.WHEN_VALUE))
+	expression, err := e.evaluateExpression(whenExpression.Value)
 	if err != nil {
 		return nil, err
 	}
 
-	cases := whenExpression.GetParam(node.WHEN_CASES)
-
-	for _, _case := range cases.Params {
-		caseValue, err := e.evaluateExpression(_case.GetParam(node.CASE_VALUE))
+	for _, _case := range whenExpression.Cases {
+		caseValue, err := e.evaluateExpression(_case.Value)
 		if err != nil {
 			return nil, err
 		}
 
 		if caseValue.Equals(*expression) {
-			return e.evaluateBlockStatements(_case.GetParam(node.CASE_STMTS))
+			return e.evaluateBlockStatements(_case.Body)
 		}
 	}
 
 	// If none of the cases match, the else/default case will be returned.
-	return e.evaluateBlockStatements(whenExpression.GetParam(node.WHEN_CASES_DEFAULT))
+	return e.evaluateBlockStatements(whenExpression.Else)
 }
 
-func (e *evaluator) evaluateAndCheckType(expression node.Node, expectedType string) (*node.Node, error) {
-	evaluatedExpression, err := e.evaluateExpression(expression)
-	if err != nil {
-		return nil, err
-	}
-
-	if evaluatedExpression.Type != expectedType {
+func checkType(value node.Node, expectedType string) (*node.Node, error) {
+	// Check the type of a value passed to a builtin
+	if value.Type != expectedType {
 		return nil, utils.CreateError(
-			evaluatedExpression.LineNum,
+			value.LineNum,
 			"expected %s, got %s",
 			expectedType,
-			evaluatedExpression.ErrorDisplay(),
+			value.ErrorDisplay(),
 		)
 	}
-	return evaluatedExpression, nil
+	return &value, nil
+}
+
+func errorDisplay(n node.Node) string {
+	// "ErrorDisplay" for nodes that are not stored in a variable (e.g., nodes converted from the typed AST)
+	return n.ErrorDisplay()
 }
@@ -0,0 +1,155 @@
+package evaluator
+
+import (
+	"boomerang/node"
+	"boomerang/utils"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+Format specifiers for string interpolation (e.g., "{x:.2f}" or "{name:>8}"). The syntax is:
+
+	[[fill]align][width][.precision][type]
+
+	fill       Character used for padding (default: space)
+	align      "<" (left), ">" (right) or "^" (center). Numbers are right-aligned by default, all other values are
+	           left-aligned.
+	width      Minimum number of characters
+	precision  Number of digits after the decimal point (numbers only)
+	type       "f" (fixed-point), "e" (scientific notation), "%" (percentage) or "d" (integer). Numbers only.
+*/
+var formatSpecPattern = regexp.MustCompile(`^(?:(.)?([<>^]))?([0-9]+)?(?:\.([0-9]+))?([fed%])?$`)
+
+const (
+	FORMAT_ALIGN_LEFT   = "<"
+	FORMAT_ALIGN_RIGHT  = ">"
+	FORMAT_ALIGN_CENTER = "^"
+
+	FORMAT_TYPE_FIXED      = "f"
+	FORMAT_TYPE_SCIENTIFIC = "e"
+	FORMAT_TYPE_PERCENTAGE = "%"
+	FORMAT_TYPE_INTEGER    = "d"
+
+	DEFAULT_FORMAT_PRECISION = 6
+
+	// Larger widths and precisions would create very large strings, so they are errors
+	MAX_FORMAT_WIDTH     = 10_000
+	MAX_FORMAT_PRECISION = 100
+)
+
+func formatValue(lineNum int, value node.Node, formatSpec string) (string, error) {
+	// With string interpolation, the quotes around strings should not be included in the final string
+	str := value.String()
+	if value.Type == node.STRING {
+		str = value.Value
+	}
+
+	if formatSpec == "" {
+		return str, nil
+	}
+
+	match := formatSpecPattern.FindStringSubmatch(formatSpec)
+	if match == nil {
+		return "", utils.CreateError(lineNum, "invalid format specifier %#v", formatSpec)
+	}
+	fill, align, width, precision, formatType := match[1], match[2], match[3], match[4], match[5]
+
+	if precision != "" || formatType != "" {
+		if value.Type != node.NUMBER {
+			return "", utils.CreateError(
+				lineNum,
+				"format specifier %#v can only be used with type %s, got %s",
+				formatSpec,
+				node.NUMBER,
+				value.Type,
+			)
+		}
+
+		var err error
+		str, err = formatNumber(lineNum, value.Value, precision, formatType)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if width == "" {
+		return str, nil
+	}
+
+	if fill == "" {
+		fill = " "
+	}
+
+	if align == "" {
+		align = FORMAT_ALIGN_LEFT
+		if value.Type == node.NUMBER {
+			align = FORMAT_ALIGN_RIGHT
+		}
+	}
+
+	widthValue, err := parseFormatLimit(lineNum, "width", width, MAX_FORMAT_WIDTH)
+	if err != nil {
+		return "", err
+	}
+	paddingLength := widthValue - utf8.RuneCountInString(str)
+	if paddingLength <= 0 {
+		return str, nil
+	}
+
+	switch align {
+	case FORMAT_ALIGN_RIGHT:
+		return strings.Repeat(fill, paddingLength) + str, nil
+	case FORMAT_ALIGN_CENTER:
+		// Extra padding goes on the right when the padding can't be split evenly
+		left := paddingLength / 2
+		return strings.Repeat(fill, left) + str + strings.Repeat(fill, paddingLength-left), nil
+	default:
+		return str + strings.Repeat(fill, paddingLength), nil
+	}
+}
+
+func formatNumber(lineNum int, number string, precision string, formatType string) (string, error) {
+	value := *utils.ConvertStringToFloat(number)
+
+	digits := DEFAULT_FORMAT_PRECISION
+	if precision != "" {
+		var err error
+		digits, err = parseFormatLimit(lineNum, "precision", precision, MAX_FORMAT_PRECISION)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	switch formatType {
+	case FORMAT_TYPE_SCIENTIFIC:
+		return strconv.FormatFloat(value, 'e', digits, 64), nil
+
+	case FORMAT_TYPE_PERCENTAGE:
+		return strconv.FormatFloat(value*100, 'f', digits, 64) + "%", nil
+
+	case FORMAT_TYPE_INTEGER:
+		if precision != "" {
+			return "", utils.CreateError(lineNum, "precision cannot be used with format type %#v", FORMAT_TYPE_INTEGER)
+		}
+		if utils.ConvertStringToInteger(number) == nil {
+			return "", utils.CreateError(lineNum, "format type %#v requires an integer, got %s", FORMAT_TYPE_INTEGER, number)
+		}
+		return number, nil
+
+	default:
+		// Fixed-point is used when only the precision is given (e.g., ".2")
+		return strconv.FormatFloat(value, 'f', digits, 64), nil
+	}
+}
+
+func parseFormatLimit(lineNum int, name string, value string, max int) (int, error) {
+	// Widths and precisions only contain digits (see "formatSpecPattern"), so parsing only fails for very large values
+	number, err := strconv.Atoi(value)
+	if err != nil || number > max {
+		return 0, utils.CreateError(lineNum, "format %s cannot be greater than %d, got %s", name, max, value)
+	}
+	return number, nil
+}
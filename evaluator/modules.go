@@ -0,0 +1,255 @@
+package evaluator
+
+import (
+	"boomerang/ast"
+	"boomerang/node"
+	"boomerang/utils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Environment variable containing additional directories to search for imported modules
+const BOOMERANG_PATH = "BOOMERANG_PATH"
+
+/*
+Parses source code into a typed AST. The parser package imports this package, so to avoid an import cycle, the parser
+registers this function when that package is initialized.
+*/
+var parseSource func(source string) ([]ast.Statement, error)
+
+func RegisterParser(parser func(source string) ([]ast.Statement, error)) {
+	parseSource = parser
+}
+
+/*
+ModuleError is an error from code in an imported module. Errors are only labeled with the module they happened in, so
+errors passed up through other modules (e.g., a module importing a module with an error) are not labeled again. Errors
+in the main program have an empty path and are not labeled.
+*/
+type ModuleError struct {
+	Path string // Absolute path of the module
+	Err  error
+}
+
+func (e *ModuleError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("in module %#v: %s", e.Path, e.Err.Error())
+}
+
+func (e *ModuleError) Unwrap() error {
+	return e.Err
+}
+
+func moduleError(modulePath string, err error) error {
+	// Label an error with the module it happened in, unless it is already labeled or is from "process.exit"
+	switch err.(type) {
+	case *ModuleError, *ExitError:
+		return err
+	}
+	return &ModuleError{Path: modulePath, Err: err}
+}
+
+type moduleLoader struct {
+	searchPaths  []string
+	cache        map[string]node.Node   // Evaluated modules by absolute path, so each file is only evaluated once
+	environments map[string]environment // Global scopes of modules by absolute path, for calling module functions
+	importStack  []string               // Absolute paths of modules currently being evaluated, for detecting import cycles
+}
+
+func newModuleLoader() *moduleLoader {
+	return &moduleLoader{
+		searchPaths:  defaultSearchPaths(),
+		cache:        map[string]node.Node{},
+		environments: map[string]environment{},
+		importStack:  []string{},
+	}
+}
+
+func defaultSearchPaths() []string {
+	/*
+		Modules are searched for in the project root (the current working directory) followed by each directory in
+		the BOOMERANG_PATH environment variable.
+	*/
+	searchPaths := []string{}
+
+	if projectRoot, err := os.Getwd(); err == nil {
+		searchPaths = append(searchPaths, projectRoot)
+	}
+
+	for _, path := range filepath.SplitList(os.Getenv(BOOMERANG_PATH)) {
+		if path != "" {
+			searchPaths = append(searchPaths, path)
+		}
+	}
+	return searchPaths
+}
+
+func (e *evaluator) SetSearchPaths(searchPaths []string) {
+	e.modules.searchPaths = searchPaths
+}
+
+func (e *evaluator) resolveModulePath(lineNum int, path string) (string, error) {
+	/*
+		Relative paths are resolved against the directory of the importing module first, then each search path. For
+		the main program, which has no directory, only the search paths are used.
+	*/
+	candidates := []string{}
+	if filepath.IsAbs(path) {
+		candidates = append(candidates, path)
+	} else {
+		if e.directory != "" {
+			candidates = append(candidates, filepath.Join(e.directory, path))
+		}
+		for _, searchPath := range e.modules.searchPaths {
+			candidates = append(candidates, filepath.Join(searchPath, path))
+		}
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return filepath.Abs(candidate)
+		}
+	}
+	return "", utils.CreateError(lineNum, "module %#v not found", path)
+}
+
+func (e *evaluator) evaluateImportStatement(stmt *ast.Import) error {
+	alias := stmt.Alias
+
+	if IsBuiltin(alias.Name) {
+		return utils.CreateError(stmt.LineNum, "%#v is a builtin function or variable", alias.Name)
+	}
+
+	module, err := e.loadModule(stmt.LineNum, stmt.Path)
+	if err != nil {
+		return err
+	}
+
+	e.env.SetIdentifier(alias.Name, *module)
+	return nil
+}
+
+func (e *evaluator) loadModule(lineNum int, path string) (*node.Node, error) {
+	modulePath, err := e.resolveModulePath(lineNum, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if module, ok := e.modules.cache[modulePath]; ok {
+		return &module, nil
+	}
+
+	for i, importingPath := range e.modules.importStack {
+		if importingPath == modulePath {
+			cycle := append([]string{}, e.modules.importStack[i:]...)
+			cycle = append(cycle, modulePath)
+			return nil, utils.CreateError(lineNum, "import cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	source, err := os.ReadFile(modulePath)
+	if err != nil {
+		return nil, utils.CreateError(lineNum, "cannot read module %#v: %s", path, err.Error())
+	}
+
+	statements, err := parseSource(string(source))
+	if err != nil {
+		return nil, moduleError(modulePath, err)
+	}
+
+	// Modules are evaluated in their own global scope, but share the module cache with the importing program
+	moduleEvaluator := evaluator{
+		statements: statements,
+		env:        CreateEnvironment(nil),
+		modules:    e.modules,
+		directory:  filepath.Dir(modulePath),
+		rng:        e.rng,
+		fileRoot:   e.fileRoot,
+		args:       e.args,
+		clock:      e.clock,
+		clockStart: e.clockStart,
+		regexCache: e.regexCache,
+	}
+	moduleEvaluator.env.modulePath = modulePath
+	e.modules.environments[modulePath] = moduleEvaluator.env
+
+	e.modules.importStack = append(e.modules.importStack, modulePath)
+	_, err = moduleEvaluator.Evaluate()
+	e.modules.importStack = e.modules.importStack[:len(e.modules.importStack)-1]
+
+	if err != nil {
+		// Calling "process.exit" in a module stops the whole program, so the exit code is passed along as it is
+		return nil, moduleError(modulePath, err)
+	}
+
+	members := []node.Node{}
+	for _, name := range moduleEvaluator.exports {
+		value, err := moduleEvaluator.env.GetIdentifier(lineNum, name)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, node.CreateAssignmentNode(node.CreateIdentifier(lineNum, name), *value))
+	}
+
+	module := node.CreateModule(lineNum, modulePath, members)
+	e.modules.cache[modulePath] = module
+	return &module, nil
+}
+
+func (e *evaluator) evaluateExportStatement(stmt *ast.Export) (*node.Node, error) {
+	var result *node.Node
+	var err error
+
+	switch statement := stmt.Statement.(type) {
+	case *ast.Function:
+		// Named functions are bound before the statements in the global scope run (see "declareFunctions")
+		result = e.evaluateFunction(statement).Ptr()
+		e.exports = append(e.exports, statement.Name)
+
+	case *ast.Identifier:
+		result, err = e.env.GetIdentifier(statement.LineNum, statement.Name)
+		if err != nil {
+			return nil, err
+		}
+		e.exports = append(e.exports, statement.Name)
+
+	case *ast.Assignment:
+		result, err = e.evaluateAssignmentStatement(statement)
+		if err != nil {
+			return nil, err
+		}
+
+		// The assignment fails if any of the variables are not identifiers
+		variables := []ast.Expression{statement.Target}
+		if list, ok := statement.Target.(*ast.List); ok {
+			variables = list.Elements
+		}
+		for _, variable := range variables {
+			e.exports = append(e.exports, variable.(*ast.Identifier).Name)
+		}
+
+	default:
+		return nil, utils.CreateError(stmt.LineNum, "invalid type for export: %s", errorDisplay(ast.ToNode(statement)))
+	}
+
+	return result, nil
+}
+
+func (e *evaluator) evaluateMemberAccess(left node.Node, member ast.Expression) (*node.Node, error) {
+	// The parser only creates member access with an identifier on the right side
+	identifier, ok := member.(*ast.Identifier)
+	if !ok {
+		return nil, utils.CreateError(member.Line(), "invalid member: %s", errorDisplay(ast.ToNode(member)))
+	}
+
+	value, err := left.GetMember(identifier.Name)
+	if err != nil {
+		return nil, err
+	}
+	value.LineNum = identifier.LineNum
+	return value, nil
+}
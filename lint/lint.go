@@ -0,0 +1,272 @@
+package lint
+
+import (
+	"boomerang/ast"
+	"boomerang/cst"
+	"boomerang/parser"
+	"boomerang/resolver"
+	"boomerang/tokens"
+	"boomerang/typechecker"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*
+The linter reports code that runs but is likely a mistake (e.g., statements after "return"). Each rule has a name, a
+default severity, and a function that checks a program. Severities can be changed with "Config", and "off" disables
+a rule.
+
+Problems can be suppressed with comments. A comment with "lint:ignore" suppresses the rules after it (separated by
+commas), or every rule when none are given. A comment at the end of a line applies to that line, and a comment on its
+own line applies to the next line:
+
+	x == true; # lint:ignore compare-boolean
+
+	# lint:ignore
+	x == true;
+*/
+
+const (
+	ERROR   = "error"
+	WARNING = "warning"
+	INFO    = "info"
+	OFF     = "off"
+
+	IGNORE_COMMENT = "lint:ignore"
+
+	DEFAULT_MAX_DEPTH = 4
+)
+
+var severities = []string{ERROR, WARNING, INFO, OFF}
+
+type Rule struct {
+	Name        string
+	Description string
+	Severity    string // Default severity
+	check       func(l *linter)
+}
+
+type Problem struct {
+	Path     string
+	LineNum  int
+	Rule     string
+	Severity string
+	Message  string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s [%s]", p.Path, p.LineNum, p.Severity, p.Message, p.Rule)
+}
+
+type Config struct {
+	Severities map[string]string // Severities by rule name, for rules that do not use their default severity
+	MaxDepth   int               // Maximum number of nested blocks for "deep-nesting"
+}
+
+func DefaultConfig() Config {
+	return Config{Severities: map[string]string{}, MaxDepth: DEFAULT_MAX_DEPTH}
+}
+
+// SetSeverity changes the severity of a rule, returning an error if the rule or severity does not exist.
+func (c *Config) SetSeverity(ruleName, severity string) error {
+	if _, ok := getRule(ruleName); !ok {
+		return fmt.Errorf("unknown rule %#v", ruleName)
+	}
+
+	for _, s := range severities {
+		if s == severity {
+			c.Severities[ruleName] = severity
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid severity %#v for rule %#v (expected %s)", severity, ruleName, strings.Join(severities, ", "))
+}
+
+func (c *Config) getSeverity(rule Rule) string {
+	if severity, ok := c.Severities[rule.Name]; ok {
+		return severity
+	}
+	return rule.Severity
+}
+
+// Rules returns every rule, sorted by name.
+func Rules() []Rule {
+	sortedRules := append([]Rule{}, rules...)
+	sort.Slice(sortedRules, func(i, j int) bool {
+		return sortedRules[i].Name < sortedRules[j].Name
+	})
+	return sortedRules
+}
+
+func getRule(name string) (Rule, bool) {
+	for _, rule := range rules {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+type linter struct {
+	statements []ast.Statement
+	config     Config
+	rule       Rule // Rule being checked
+	problems   []Problem
+	resolved   *resolver.Result    // Created the first time a rule needs it
+	typed      *typechecker.Result // Created the first time a rule needs it
+}
+
+func (l *linter) report(lineNum int, message string, values ...any) {
+	l.problems = append(l.problems, Problem{
+		LineNum:  lineNum,
+		Rule:     l.rule.Name,
+		Severity: l.config.getSeverity(l.rule),
+		Message:  fmt.Sprintf(message, values...),
+	})
+}
+
+func (l *linter) resolve() *resolver.Result {
+	if l.resolved == nil {
+		l.resolved = resolver.Resolve(l.statements)
+	}
+	return l.resolved
+}
+
+func (l *linter) checkTypes() *typechecker.Result {
+	if l.typed == nil {
+		l.typed = typechecker.Check(l.statements)
+	}
+	return l.typed
+}
+
+/*
+Lint checks a program with every rule that is not turned off, and returns the problems that are not suppressed, sorted
+by line number. "path" is only used to label the problems. Returns an error if the program cannot be parsed.
+*/
+func Lint(path, source string, config Config) ([]Problem, error) {
+	parserObj, err := parser.NewParser(tokens.NewTokenizer(source))
+	if err != nil {
+		return nil, err
+	}
+
+	statements, err := parserObj.ParseTyped()
+	if err != nil {
+		return nil, err
+	}
+
+	suppressed, err := getSuppressedRules(source)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &linter{statements: statements, config: config}
+	for _, rule := range rules {
+		if config.getSeverity(rule) == OFF {
+			continue
+		}
+		l.rule = rule
+		rule.check(l)
+	}
+
+	problems := []Problem{}
+	for _, problem := range l.problems {
+		if isSuppressed(suppressed[problem.LineNum], problem.Rule) {
+			continue
+		}
+		problem.Path = path
+		problems = append(problems, problem)
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].LineNum < problems[j].LineNum
+	})
+	return problems, nil
+}
+
+func getSuppressedRules(source string) (map[int][]string, error) {
+	/*
+		Find the rules suppressed on each line. An empty list suppresses every rule. Comments are not part of the AST, so
+		they are found in the concrete syntax tree.
+	*/
+	tree, err := cst.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	suppressed := map[int][]string{}
+	lineNum := 1
+	tokenOnLine := false // Whether a token has been found on the current line, before the current comment
+
+	addTrivia := func(trivia cst.Trivia) {
+		if trivia.Kind == cst.INLINE_COMMENT || trivia.Kind == cst.BLOCK_COMMENT {
+			if ruleNames, ok := parseIgnoreComment(trivia.Text); ok {
+				// Comments after code apply to that line, and comments on their own line apply to the next line
+				line := lineNum + strings.Count(trivia.Text, "\n") + 1
+				if tokenOnLine {
+					line = lineNum
+				}
+				existing, found := suppressed[line]
+				switch {
+				case found && len(existing) == 0:
+					// Every rule is already suppressed on the line
+				case len(ruleNames) == 0:
+					suppressed[line] = []string{}
+				default:
+					suppressed[line] = append(existing, ruleNames...)
+				}
+			}
+		}
+
+		if newLines := strings.Count(trivia.Text, "\n"); newLines > 0 {
+			lineNum += newLines
+			tokenOnLine = false
+		}
+	}
+
+	for _, token := range tree.Tokens() {
+		for _, trivia := range token.Leading {
+			addTrivia(trivia)
+		}
+
+		tokenOnLine = true
+		lineNum += strings.Count(token.Text, "\n")
+
+		for _, trivia := range token.Trailing {
+			addTrivia(trivia)
+		}
+	}
+	return suppressed, nil
+}
+
+func parseIgnoreComment(comment string) ([]string, bool) {
+	text := strings.TrimSpace(strings.Trim(comment, "#"))
+	if !strings.HasPrefix(text, IGNORE_COMMENT) {
+		return nil, false
+	}
+
+	ruleNames := []string{}
+	for _, name := range strings.Split(strings.TrimPrefix(text, IGNORE_COMMENT), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			ruleNames = append(ruleNames, name)
+		}
+	}
+	return ruleNames, true
+}
+
+func isSuppressed(ruleNames []string, rule string) bool {
+	if ruleNames == nil {
+		return false
+	}
+
+	if len(ruleNames) == 0 {
+		return true
+	}
+
+	for _, name := range ruleNames {
+		if name == rule {
+			return true
+		}
+	}
+	return false
+}
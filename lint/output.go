@@ -0,0 +1,123 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+/*
+Problems can be written as text (one problem per line), JSON (a list of problems), or SARIF, the Static Analysis Results
+Interchange Format (https://sarifweb.azurewebsites.net), which code review tools can show next to the code.
+*/
+
+func WriteText(writer io.Writer, problems []Problem) error {
+	for _, problem := range problems {
+		if _, err := fmt.Fprintln(writer, problem.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type jsonProblem struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func WriteJSON(writer io.Writer, problems []Problem) error {
+	values := []jsonProblem{}
+	for _, problem := range problems {
+		values = append(values, jsonProblem{
+			Path:     problem.Path,
+			Line:     problem.LineNum,
+			Rule:     problem.Rule,
+			Severity: problem.Severity,
+			Message:  problem.Message,
+		})
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(values)
+}
+
+const (
+	SARIF_VERSION = "2.1.0"
+	SARIF_SCHEMA  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIF calls severities "levels", and uses "note" instead of "info"
+var sarifLevels = map[string]string{ERROR: "error", WARNING: "warning", INFO: "note"}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID               string    `json:"id"`
+	ShortDescription sarifText `json:"shortDescription"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region struct {
+			StartLine int `json:"startLine"`
+		} `json:"region"`
+	} `json:"physicalLocation"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifText       `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver struct {
+			Name  string      `json:"name"`
+			Rules []sarifRule `json:"rules"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+func WriteSARIF(writer io.Writer, problems []Problem) error {
+	run := sarifRun{Results: []sarifResult{}}
+	run.Tool.Driver.Name = "boomerang-lint"
+	run.Tool.Driver.Rules = []sarifRule{}
+	for _, rule := range Rules() {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: rule.Name, ShortDescription: sarifText{Text: rule.Description}})
+	}
+
+	for _, problem := range problems {
+		var location sarifLocation
+		location.PhysicalLocation.ArtifactLocation.URI = problem.Path
+		location.PhysicalLocation.Region.StartLine = problem.LineNum
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    problem.Rule,
+			Level:     sarifLevels[problem.Severity],
+			Message:   sarifText{Text: problem.Message},
+			Locations: []sarifLocation{location},
+		})
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{Version: SARIF_VERSION, Schema: SARIF_SCHEMA, Runs: []sarifRun{run}})
+}
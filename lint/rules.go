@@ -0,0 +1,265 @@
+package lint
+
+import (
+	"boomerang/ast"
+	"boomerang/resolver"
+	"boomerang/tokens"
+	"boomerang/typechecker"
+)
+
+var rules = []Rule{
+	{
+		Name:        "unused-monad",
+		Description: "A function call's result is discarded instead of being unwrapped or checked.",
+		Severity:    WARNING,
+		check:       checkUnusedMonads,
+	},
+	{
+		Name:        "compare-boolean",
+		Description: "A value is compared with \"true\" or \"false\" instead of being used directly.",
+		Severity:    WARNING,
+		check:       checkBooleanComparisons,
+	},
+	{
+		Name:        "unreachable-code",
+		Description: "A statement comes after \"return\", \"break\" or \"continue\" in the same block, so it never runs.",
+		Severity:    WARNING,
+		check:       checkUnreachableCode,
+	},
+	{
+		Name:        "discarded-for-result",
+		Description: "A for-loop's list of results is discarded.",
+		Severity:    INFO,
+		check:       checkDiscardedForLoops,
+	},
+	{
+		Name:        "deep-nesting",
+		Description: "Blocks are nested more deeply than the maximum depth.",
+		Severity:    WARNING,
+		check:       checkNesting,
+	},
+	{
+		Name:        "undefined-name",
+		Description: "A name is used before it is assigned, or is never assigned.",
+		Severity:    ERROR,
+		check:       resolverCheck(resolver.UNDEFINED),
+	},
+	{
+		Name:        "unused-name",
+		Description: "A variable, parameter, function or import is never used.",
+		Severity:    WARNING,
+		check:       resolverCheck(resolver.UNUSED),
+	},
+	{
+		Name:        "builtin-name",
+		Description: "A variable, parameter, function or import has the same name as a builtin.",
+		Severity:    ERROR,
+		check:       resolverCheck(resolver.SHADOWED_BUILTIN),
+	},
+	{
+		Name:        "type-mismatch",
+		Description: "A value's type cannot be used where it is used, or does not match a type annotation.",
+		Severity:    ERROR,
+		check:       typeCheck(typechecker.MISMATCH),
+	},
+	{
+		Name:        "unknown-type",
+		Description: "A type annotation uses a type that does not exist.",
+		Severity:    ERROR,
+		check:       typeCheck(typechecker.UNKNOWN_TYPE),
+	},
+	{
+		Name:        "invalid-annotation",
+		Description: "A type annotation is used on something other than a variable.",
+		Severity:    ERROR,
+		check:       typeCheck(typechecker.INVALID_ANNOTATION),
+	},
+}
+
+func resolverCheck(code string) func(l *linter) {
+	// Rules for the diagnostics created by the resolver (see the "resolver" package)
+	return func(l *linter) {
+		for _, diagnostic := range l.resolve().Diagnostics {
+			if diagnostic.Code == code {
+				l.report(diagnostic.LineNum, "%s", diagnostic.Message)
+			}
+		}
+	}
+}
+
+func typeCheck(code string) func(l *linter) {
+	// Rules for the diagnostics created by the type checker (see the "typechecker" package)
+	return func(l *linter) {
+		for _, diagnostic := range l.checkTypes().Diagnostics {
+			if diagnostic.Code == code {
+				l.report(diagnostic.LineNum, "%s", diagnostic.Message)
+			}
+		}
+	}
+}
+
+/*
+Call "f" for each statement whose value is discarded. Statements in the global scope, function bodies and while-loops
+are discarded, except for "return" values. The last statement in a when-expression's case, or in a for-loop, is the
+value of that case or iteration, so it is only discarded if the when-expression or for-loop is discarded.
+*/
+func forEachDiscarded(statements []ast.Statement, f func(ast.Statement)) {
+	visitStatements(statements, false, f)
+}
+
+func visitStatements(statements []ast.Statement, lastUsed bool, f func(ast.Statement)) {
+	for i, statement := range statements {
+		used := lastUsed && i == len(statements)-1
+		if !used {
+			f(statement)
+		}
+		visitNode(statement, used, f)
+	}
+}
+
+func visitNode(n ast.Node, used bool, f func(ast.Statement)) {
+	switch n := n.(type) {
+	case *ast.When:
+		visitNode(n.Value, true, f)
+		for _, whenCase := range n.Cases {
+			visitNode(whenCase.Value, true, f)
+			visitStatements(whenCase.Body.Statements, used, f)
+		}
+		visitStatements(n.Else.Statements, used, f)
+
+	case *ast.ForLoop:
+		visitNode(n.Values, true, f)
+		visitStatements(n.Body.Statements, used, f)
+
+	case *ast.WhileLoop:
+		visitNode(n.Condition, true, f)
+		visitStatements(n.Body.Statements, false, f)
+
+	case *ast.Function:
+		for _, parameter := range n.Parameters {
+			if parameter.Default != nil {
+				visitNode(parameter.Default, true, f)
+			}
+		}
+		visitStatements(n.Body.Statements, false, f)
+
+	default:
+		for _, child := range ast.Children(n) {
+			visitNode(child, true, f)
+		}
+	}
+}
+
+func checkUnusedMonads(l *linter) {
+	// Calls to builtins are not included because many builtins do not return monads (e.g., "print")
+	forEachDiscarded(l.statements, func(statement ast.Statement) {
+		call, ok := statement.(*ast.BinaryExpression)
+		if !ok || call.Operator.Type != tokens.SEND {
+			return
+		}
+
+		isFunction := false
+		switch function := call.Left.(type) {
+		case *ast.Identifier, *ast.Function:
+			isFunction = true
+		case *ast.BinaryExpression:
+			// Functions in modules (e.g., "module.function <- ()")
+			isFunction = function.Operator.Type == tokens.PERIOD
+		}
+
+		if isFunction {
+			l.report(call.LineNum, "result of function call is discarded (unwrap it or check it with \"is_success\")")
+		}
+	})
+}
+
+func checkDiscardedForLoops(l *linter) {
+	forEachDiscarded(l.statements, func(statement ast.Statement) {
+		if forLoop, ok := statement.(*ast.ForLoop); ok {
+			l.report(forLoop.LineNum, "list created by for-loop is discarded")
+		}
+	})
+}
+
+func checkBooleanComparisons(l *linter) {
+	for _, statement := range l.statements {
+		ast.Inspect(statement, func(n ast.Node) bool {
+			comparison, ok := n.(*ast.BinaryExpression)
+			if !ok || (comparison.Operator.Type != tokens.EQ && comparison.Operator.Type != tokens.NE) {
+				return true
+			}
+
+			for _, side := range []ast.Expression{comparison.Left, comparison.Right} {
+				if boolean, ok := side.(*ast.Boolean); ok {
+					// "x == true" and "x != false" are "x", and "x == false" and "x != true" are "not x"
+					suggestion := "the value"
+					if boolean.Value != (comparison.Operator.Type == tokens.EQ) {
+						suggestion = "\"not\""
+					}
+					literal := tokens.FALSE_TOKEN.Literal
+					if boolean.Value {
+						literal = tokens.TRUE_TOKEN.Literal
+					}
+					l.report(comparison.LineNum, "comparison with \"%s %s\" (use %s instead)", comparison.Operator.Literal, literal, suggestion)
+					break
+				}
+			}
+			return true
+		})
+	}
+}
+
+func checkUnreachableCode(l *linter) {
+	checkStatements := func(statements []ast.Statement) {
+		for i := 0; i < len(statements)-1; i++ {
+			switch statements[i].(type) {
+			case *ast.Return, *ast.Break, *ast.Continue:
+				l.report(statements[i+1].Line(), "unreachable statement after %s", statementName(statements[i]))
+				return
+			}
+		}
+	}
+
+	checkStatements(l.statements)
+	for _, statement := range l.statements {
+		ast.Inspect(statement, func(n ast.Node) bool {
+			if block, ok := n.(*ast.BlockStatements); ok {
+				checkStatements(block.Statements)
+			}
+			return true
+		})
+	}
+}
+
+func statementName(statement ast.Statement) string {
+	switch statement.(type) {
+	case *ast.Return:
+		return tokens.RETURN_TOKEN.Literal
+	case *ast.Break:
+		return tokens.BREAK_TOKEN.Literal
+	}
+	return tokens.CONTINUE_TOKEN.Literal
+}
+
+func checkNesting(l *linter) {
+	// Report the outermost block that is too deep, but not the blocks inside it
+	var visit func(n ast.Node, depth int)
+	visit = func(n ast.Node, depth int) {
+		switch n.(type) {
+		case *ast.Function, *ast.When, *ast.ForLoop, *ast.WhileLoop:
+			depth += 1
+			if depth > l.config.MaxDepth {
+				l.report(n.Line(), "blocks are nested %d deep (maximum is %d)", depth, l.config.MaxDepth)
+				return
+			}
+		}
+
+		for _, child := range ast.Children(n) {
+			visit(child, depth)
+		}
+	}
+
+	for _, statement := range l.statements {
+		visit(statement, 0)
+	}
+}
@@ -1,33 +1,366 @@
 package main
 
 import (
+	"boomerang/ast"
 	"boomerang/evaluator"
+	"boomerang/lint"
+	"boomerang/node"
 	"boomerang/parser"
+	"boomerang/resolver"
 	"boomerang/tokens"
+	"boomerang/typechecker"
 	"boomerang/utils"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 func main() {
+	// "boomerang tokens" prints the tokens in a file instead of running it
+	if len(os.Args) > 1 && os.Args[1] == "tokens" {
+		printTokens(os.Args[2:])
+		return
+	}
+
+	// "boomerang ast" prints the AST for a file instead of running it
+	if len(os.Args) > 1 && os.Args[1] == "ast" {
+		printAST(os.Args[2:])
+		return
+	}
+
+	// "boomerang check" reports undefined and unused names in a file instead of running it
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		checkNames(os.Args[2:])
+		return
+	}
+
+	// "boomerang lint" reports likely mistakes in files instead of running them
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		lintFiles(os.Args[2:])
+		return
+	}
+
+	// Seed for the random builtins. When not provided, a different seed is used on every run.
+	var seed *int64
+	flag.Func("seed", "seed for the random number generator, for reproducible runs", func(value string) error {
+		parsedSeed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("seed must be an integer, got %#v", value)
+		}
+		seed = &parsedSeed
+		return nil
+	})
+
+	// Directory the filesystem builtins can access. When not provided, filesystem access is disabled.
+	fileRoot := flag.String("fs-root", "", "directory the filesystem builtins can access (disabled by default)")
+
+	// Programs with type errors do not run, unless type checking is turned off
+	noTypecheck := flag.Bool("no-typecheck", false, "run the program without checking it for type errors")
+	flag.Parse()
+
 	source := utils.GetSource("source.bmg")
 	tokenizer := tokens.NewTokenizer(source)
 
 	parser, err := parser.NewParser(tokenizer)
 	if err != nil {
 		fmt.Println(err.Error())
-		return
+		os.Exit(1)
 	}
 
-	statements, err := parser.Parse()
+	statements, err := parser.ParseTyped()
 	if err != nil {
 		fmt.Println(err.Error())
-		return
+		os.Exit(1)
+	}
+
+	if !*noTypecheck {
+		typeCheck(statements)
+	}
+
+	eval := evaluator.NewEvaluator(statements)
+	if seed != nil {
+		eval.SetRandomSeed(*seed)
+	}
+	eval.SetArgs(flag.Args())
+	if *fileRoot != "" {
+		if err := eval.SetFileRoot(*fileRoot); err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
 	}
 
-	eval := evaluator.NewEvaluator(*statements)
 	_, err = eval.Evaluate()
+
+	// Programs that call "process.exit" stop with the code they provide. Other errors stop the program with code 1.
+	var exitError *evaluator.ExitError
+	if errors.As(err, &exitError) {
+		os.Exit(exitError.Code)
+	}
+
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
+
+func typeCheck(statements []ast.Statement) {
+	// Print the type errors in a program and exit with code 1 if there are any
+	result := typechecker.Check(statements)
+	for _, diagnostic := range result.Diagnostics {
+		fmt.Println(diagnostic.String())
+	}
+
+	if result.HasErrors() {
+		os.Exit(1)
+	}
+}
+
+func printTokens(arguments []string) {
+	/*
+		Print the tokens in a file (by default, "source.bmg"), one per line. With "-", the source is read from standard
+		input. The file is tokenized as it is read, so large files do not have to fit in memory.
+
+		Formats:
+			text  line:column, type, and quoted literal, separated by tabs
+			json  one JSON object per token (JSON Lines)
+	*/
+	flags := flag.NewFlagSet("tokens", flag.ExitOnError)
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Parse(arguments)
+
+	if *format != "text" && *format != "json" {
+		fmt.Printf("invalid format %#v (expected text or json)\n", *format)
+		os.Exit(1)
+	}
+
+	reader := openSource(flags)
+	defer reader.Close()
+
+	output := bufio.NewWriter(os.Stdout)
+	encoder := json.NewEncoder(output)
+	tokenizer := tokens.NewReaderTokenizer(reader)
+
+	for {
+		token, err := tokenizer.Next()
+		if err != nil {
+			output.Flush()
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		if *format == "json" {
+			encoder.Encode(map[string]any{
+				"type":    token.Type,
+				"literal": token.Literal,
+				"line":    token.LineNumber,
+				"column":  token.Column,
+				"offset":  token.Offset,
+			})
+		} else {
+			fmt.Fprintf(output, "%d:%d\t%s\t%s\n", token.LineNumber, token.Column, token.Type, strconv.Quote(token.Literal))
+		}
+
+		if token.Type == tokens.EOF {
+			break
+		}
+	}
+	output.Flush()
+}
+
+func printAST(arguments []string) {
+	/*
+		Print the AST for a file (by default, "source.bmg"). With "-", the source is read from standard input.
+
+		Formats:
+			tree  indented tree of nodes with their types, values and line numbers
+			dot   Graphviz graph (e.g., "go run main.go ast -format dot | dot -Tsvg -o ast.svg")
+			json  the JSON form of the AST, which can be loaded back into nodes
+	*/
+	flags := flag.NewFlagSet("ast", flag.ExitOnError)
+	format := flags.String("format", "tree", "output format: tree, dot or json")
+	flags.Parse(arguments)
+
+	if *format != "tree" && *format != "dot" && *format != "json" {
+		fmt.Printf("invalid format %#v (expected tree, dot or json)\n", *format)
+		os.Exit(1)
+	}
+
+	reader := openSource(flags)
+	defer reader.Close()
+
+	parserObj, err := parser.NewParser(tokens.NewReaderTokenizer(reader))
 	if err != nil {
 		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	statements, err := parserObj.Parse()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	switch *format {
+	case "tree":
+		fmt.Print(node.Tree(*statements))
+
+	case "dot":
+		fmt.Print(node.DOT(*statements))
+
+	case "json":
+		output, err := json.MarshalIndent(*statements, "", "  ")
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+		fmt.Println(string(output))
+	}
+}
+
+func checkNames(arguments []string) {
+	/*
+		Report undefined names, names of builtins that are assigned, unused variables, parameters, functions and
+		imports, and type errors in a file (by default, "source.bmg"), without running it. With "-", the source is read
+		from standard input. Exits with code 1 if any errors are found.
+	*/
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	flags.Parse(arguments)
+
+	reader := openSource(flags)
+	defer reader.Close()
+
+	parserObj, err := parser.NewParser(tokens.NewReaderTokenizer(reader))
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	statements, err := parserObj.ParseTyped()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	result := resolver.Resolve(statements)
+	types := typechecker.Check(statements)
+
+	diagnostics := append(result.Diagnostics, types.Diagnostics...)
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].LineNum < diagnostics[j].LineNum
+	})
+	for _, diagnostic := range diagnostics {
+		fmt.Println(diagnostic.String())
+	}
+
+	if result.HasErrors() || types.HasErrors() {
+		os.Exit(1)
+	}
+}
+
+func openSource(flags *flag.FlagSet) io.ReadCloser {
+	// Open the file given as an argument to a command (by default, "source.bmg"), or standard input for "-"
+	path := "source.bmg"
+	if flags.NArg() > 0 {
+		path = flags.Arg(0)
+	}
+
+	if path == "-" {
+		return io.NopCloser(os.Stdin)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+	return file
+}
+
+func lintFiles(arguments []string) {
+	/*
+		Report likely mistakes in files (by default, "source.bmg"), without running them. With "-", the source is read
+		from standard input. Exits with code 1 if a file cannot be parsed or any problems are errors.
+
+		Formats:
+			text   one problem per line: path:line: severity: message [rule]
+			json   a list of problems
+			sarif  a SARIF log, for code review tools
+	*/
+	config := lint.DefaultConfig()
+
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	format := flags.String("format", "text", "output format: text, json or sarif")
+	listRules := flags.Bool("rules", false, "list the rules and their default severities")
+	flags.IntVar(&config.MaxDepth, "max-depth", lint.DEFAULT_MAX_DEPTH, "maximum number of nested blocks")
+	flags.Func("severity", "change a rule's severity (e.g., -severity compare-boolean=error); \"off\" disables a rule", func(value string) error {
+		ruleName, severity, ok := strings.Cut(value, "=")
+		if !ok {
+			return fmt.Errorf("expected rule=severity, got %#v", value)
+		}
+		return config.SetSeverity(ruleName, severity)
+	})
+	flags.Parse(arguments)
+
+	if *listRules {
+		for _, rule := range lint.Rules() {
+			fmt.Printf("%s\t%s\t%s\n", rule.Name, rule.Severity, rule.Description)
+		}
 		return
 	}
+
+	writers := map[string]func(io.Writer, []lint.Problem) error{
+		"text":  lint.WriteText,
+		"json":  lint.WriteJSON,
+		"sarif": lint.WriteSARIF,
+	}
+	writeProblems, ok := writers[*format]
+	if !ok {
+		fmt.Printf("invalid format %#v (expected text, json or sarif)\n", *format)
+		os.Exit(1)
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"source.bmg"}
+	}
+
+	problems := []lint.Problem{}
+	for _, path := range paths {
+		var source []byte
+		var err error
+		if path == "-" {
+			source, err = io.ReadAll(os.Stdin)
+		} else {
+			source, err = os.ReadFile(path)
+		}
+		if err != nil {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+
+		fileProblems, err := lint.Lint(path, string(source), config)
+		if err != nil {
+			fmt.Printf("%s: %s\n", path, err.Error())
+			os.Exit(1)
+		}
+		problems = append(problems, fileProblems...)
+	}
+
+	if err := writeProblems(os.Stdout, problems); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	for _, problem := range problems {
+		if problem.Severity == lint.ERROR {
+			os.Exit(1)
+		}
+	}
 }
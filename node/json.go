@@ -0,0 +1,232 @@
+package node
+
+import (
+	"boomerang/tokens"
+	"boomerang/utils"
+	"encoding/json"
+	"fmt"
+)
+
+/*
+Nodes are converted to and from JSON so trees can be inspected or created by other tools. Each node is an object with
+its type, value, line number and parameters. Parameters are stored in order, and parameters with names in "paramNames"
+include their name as "key". For example, "x = 1;" is:
+
+	{
+	  "type": "Assign",
+	  "line": 1,
+	  "params": [
+	    {"key": "Identifier", "type": "Identifier", "value": "x", "line": 1},
+	    {"key": "Expression", "type": "Number", "value": "1", "line": 1}
+	  ]
+	}
+
+"params" is omitted for nodes without a list of parameters, and is an empty list for nodes with an empty list of
+parameters (e.g., strings without interpolation). Keys are optional when loading JSON, but must match "paramNames" if
+they are given.
+
+Loaded trees are checked so they can be evaluated: only statements can be loaded (see "jsonStatementTypes"), each
+parameter must have a type the parser would put there (see "jsonParamTypes"), and numbers and booleans must have
+valid values.
+*/
+type jsonNode struct {
+	Key    string      `json:"key,omitempty"`
+	Type   string      `json:"type"`
+	Value  string      `json:"value,omitempty"`
+	Line   int         `json:"line"`
+	Params *[]jsonNode `json:"params,omitempty"`
+}
+
+// Names of parameters by node type, in order. Each name is also in "indexMap" with the same index.
+var paramNames = map[string][]string{
+	ASSIGN_STMT:   {ASSIGN_STMT_IDENTIFIER, EXPR},
+	BIN_EXPR:      {LEFT, OPERATOR, RIGHT},
+	UNARY_EXPR:    {OPERATOR, EXPR},
+	FUNCTION:      {LIST, STMTS},
+	FUNCTION_CALL: {CALL_PARAMS, FUNCTION},
+	WHEN:          {WHEN_VALUE, WHEN_CASES, WHEN_CASES_DEFAULT},
+	CASE:          {CASE_VALUE, CASE_STMTS},
+	FOR_LOOP:      {FOR_LOOP_ELEM_ASSIGN, BLOCK_STATEMENTS},
+	WHILE_LOOP:    {WHILE_LOOP_CONDITION, WHILE_LOOP_STATEMENTS},
+	MONAD:         {MONAD_VALUE},
+	RETURN:        {EXPR},
+	IMPORT:        {IDENTIFIER},
+	EXPORT:        {EXPR},
+	INTERPOLATION: {EXPR},
+	MAP_ENTRY:     {MAP_KEY, MAP_VALUE},
+}
+
+// paramName returns the name of a parameter, or an empty string if the parameter has no name
+func paramName(nodeType string, index int) string {
+	if names := paramNames[nodeType]; index < len(names) {
+		return names[index]
+	}
+	return ""
+}
+
+func typeSet(types ...string) map[string]bool {
+	set := map[string]bool{}
+	for _, nodeType := range types {
+		set[nodeType] = true
+	}
+	return set
+}
+
+var (
+	// Types of nodes the parser creates for expressions. Assignments and for loops are expressions because they have values.
+	jsonExpressionTypes = typeSet(
+		NUMBER, STRING, BOOLEAN, IDENTIFIER, BUILTIN_VARIABLE, BUILTIN_FUNCTION, LIST, FUNCTION,
+		UNARY_EXPR, BIN_EXPR, ASSIGN_STMT, WHEN, FOR_LOOP,
+	)
+
+	jsonStatementTypes = typeSet(
+		NUMBER, STRING, BOOLEAN, IDENTIFIER, BUILTIN_VARIABLE, BUILTIN_FUNCTION, LIST, FUNCTION,
+		UNARY_EXPR, BIN_EXPR, ASSIGN_STMT, WHEN, FOR_LOOP,
+		WHILE_LOOP, BREAK, CONTINUE, RETURN, IMPORT, EXPORT,
+	)
+
+	jsonBinaryOperators = typeSet(
+		tokens.PERIOD, tokens.PLUS, tokens.MINUS, tokens.ASTERISK, tokens.FORWARD_SLASH, tokens.MODULO, tokens.SEND,
+		tokens.AT, tokens.EQ, tokens.NE, tokens.LT, tokens.IN, tokens.OR, tokens.AND,
+	)
+
+	jsonUnaryOperators = typeSet(tokens.MINUS, tokens.NOT)
+)
+
+// Types allowed for each named parameter, by node type and index
+var jsonParamTypes = map[string][]map[string]bool{
+	ASSIGN_STMT:   {jsonExpressionTypes, jsonExpressionTypes},
+	BIN_EXPR:      {jsonExpressionTypes, jsonBinaryOperators, jsonExpressionTypes},
+	UNARY_EXPR:    {jsonUnaryOperators, jsonExpressionTypes},
+	FUNCTION:      {typeSet(LIST), typeSet(BLOCK_STATEMENTS)},
+	WHEN:          {jsonExpressionTypes, typeSet(WHEN_CASES), typeSet(BLOCK_STATEMENTS)},
+	CASE:          {jsonExpressionTypes, typeSet(BLOCK_STATEMENTS)},
+	FOR_LOOP:      {typeSet(ASSIGN_STMT), typeSet(BLOCK_STATEMENTS)},
+	WHILE_LOOP:    {jsonExpressionTypes, typeSet(BLOCK_STATEMENTS)},
+	RETURN:        {jsonExpressionTypes},
+	IMPORT:        {typeSet(IDENTIFIER)},
+	EXPORT:        {jsonExpressionTypes},
+	INTERPOLATION: {jsonExpressionTypes},
+}
+
+/*
+Types allowed for the parameters after the named ones (e.g., the elements of a list, or the return type of a function).
+Nodes of other types can't have any parameters after their named ones.
+*/
+var jsonOtherParamTypes = map[string]map[string]bool{
+	LIST:             jsonExpressionTypes,
+	STRING:           typeSet(STRING, INTERPOLATION),
+	BLOCK_STATEMENTS: jsonStatementTypes,
+	WHEN_CASES:       typeSet(CASE),
+	FUNCTION:         typeSet(TYPE_ANNOTATION),
+	IDENTIFIER:       typeSet(TYPE_ANNOTATION),
+	TYPE_ANNOTATION:  typeSet(TYPE_ANNOTATION),
+}
+
+func getJSONParamTypes(nodeType string, index int) map[string]bool {
+	if types := jsonParamTypes[nodeType]; index < len(types) {
+		return types[index]
+	}
+	return jsonOtherParamTypes[nodeType]
+}
+
+func (n Node) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toJSON(n, ""))
+}
+
+func (n *Node) UnmarshalJSON(data []byte) error {
+	var value jsonNode
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	result, err := fromJSON(value)
+	if err != nil {
+		return err
+	}
+
+	if !jsonStatementTypes[result.Type] {
+		return fmt.Errorf("%s node on line %d is not a statement", result.Type, result.LineNum)
+	}
+	*n = result
+	return nil
+}
+
+func toJSON(n Node, key string) jsonNode {
+	value := jsonNode{Key: key, Type: n.Type, Value: n.Value, Line: n.LineNum}
+	if n.Params != nil {
+		params := []jsonNode{}
+		for i, param := range n.Params {
+			params = append(params, toJSON(param, paramName(n.Type, i)))
+		}
+		value.Params = &params
+	}
+	return value
+}
+
+func fromJSON(value jsonNode) (Node, error) {
+	if value.Type == "" {
+		return Node{}, fmt.Errorf("node on line %d has no type", value.Line)
+	}
+
+	n := Node{Type: value.Type, Value: value.Value, LineNum: value.Line}
+	if err := checkValue(n); err != nil {
+		return Node{}, err
+	}
+
+	if value.Params == nil {
+		return n, checkParamCount(n)
+	}
+
+	n.Params = []Node{}
+	for i, param := range *value.Params {
+		if param.Key != "" {
+			if names := paramNames[n.Type]; i >= len(names) || names[i] != param.Key {
+				return Node{}, fmt.Errorf("invalid parameter %#v at index %d in %s node on line %d", param.Key, i, n.Type, n.LineNum)
+			}
+		}
+
+		paramNode, err := fromJSON(param)
+		if err != nil {
+			return Node{}, err
+		}
+
+		if !getJSONParamTypes(n.Type, i)[paramNode.Type] {
+			return Node{}, fmt.Errorf("invalid %s node at index %d in %s node on line %d", paramNode.Type, i, n.Type, n.LineNum)
+		}
+		n.Params = append(n.Params, paramNode)
+	}
+	return n, checkParamCount(n)
+}
+
+func checkValue(n Node) error {
+	// Numbers must have the value the tokenizer would give them, so equal numbers have equal values
+	switch n.Type {
+	case NUMBER:
+		number := utils.ConvertStringToFloat(n.Value)
+		if number == nil {
+			return fmt.Errorf("invalid number %#v on line %d", n.Value, n.LineNum)
+		}
+		if value := tokens.FormatNumber(*number); value != n.Value {
+			return fmt.Errorf("number %#v on line %d must be written as %#v", n.Value, n.LineNum, value)
+		}
+
+	case BOOLEAN:
+		if n.Value != tokens.TRUE_TOKEN.Literal && n.Value != tokens.FALSE_TOKEN.Literal {
+			return fmt.Errorf("invalid boolean %#v on line %d", n.Value, n.LineNum)
+		}
+	}
+	return nil
+}
+
+func checkParamCount(n Node) error {
+	// Nodes with named parameters need all of them, except monads, which may not have a value
+	if n.Type == MONAD {
+		return nil
+	}
+
+	if expected := len(paramNames[n.Type]); len(n.Params) < expected {
+		return fmt.Errorf("%s node on line %d needs %d parameters, got %d", n.Type, n.LineNum, expected, len(n.Params))
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"boomerang/utils"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 )
 
 type Node struct {
@@ -44,10 +45,10 @@ func (n *Node) GetParamByKeys(keys []string) Node {
 }
 
 func (n *Node) UpdateLineNumbers(lineNum int) {
-	n.LineNum = lineNum
-	for i := range n.Params {
-		n.Params[i].UpdateLineNumbers(lineNum)
-	}
+	*n = Rewrite(*n, func(c *Cursor) Node {
+		c.Node.LineNum = lineNum
+		return c.Node
+	})
 }
 
 func (n *Node) String() string {
@@ -67,7 +68,26 @@ func (n *Node) String() string {
 
 	case STRING:
 		doubleQuoteLiteral := "\""
-		return fmt.Sprintf("%s%s%s", doubleQuoteLiteral, n.Value, doubleQuoteLiteral)
+		if len(n.Params) == 0 {
+			return fmt.Sprintf("%s%s%s", doubleQuoteLiteral, n.Value, doubleQuoteLiteral)
+		}
+
+		// Interpolated strings are displayed with their expressions between curly braces
+		s := ""
+		for _, part := range n.Params {
+			if part.Type == STRING {
+				s += part.Value
+			} else {
+				s += part.String()
+			}
+		}
+		return fmt.Sprintf("%s%s%s", doubleQuoteLiteral, s, doubleQuoteLiteral)
+
+	case INTERPOLATION:
+		if n.Value == "" {
+			return fmt.Sprintf("{%s}", n.Params[0].String())
+		}
+		return fmt.Sprintf("{%s:%s}", n.Params[0].String(), n.Value)
 
 	case FUNCTION:
 		funcKeyword := tokens.FUNCTION_TOKEN.Literal
@@ -76,7 +96,12 @@ func (n *Node) String() string {
 		openCurlyBracket := tokens.OPEN_CURLY_BRACKET_TOKEN.Literal
 		closedCurlyBracket := tokens.CLOSED_CURLY_BRACKET_TOKEN.Literal
 
-		s := fmt.Sprintf("%s%s", funcKeyword, openParen)
+		s := funcKeyword
+		if n.Value != "" {
+			// Named functions display their name between the keyword and the parameters
+			s += fmt.Sprintf(" %s", n.Value)
+		}
+		s += openParen
 
 		parameterStrings := []string{}
 		for _, param := range n.GetParam(LIST).Params {
@@ -93,6 +118,17 @@ func (n *Node) String() string {
 	case BUILTIN_FUNCTION:
 		return fmt.Sprintf("<built-in function %s>", n.Value)
 
+	case MODULE:
+		return fmt.Sprintf("<module %s>", n.Value)
+
+	case MAP:
+		entries := []string{}
+		for _, entry := range n.Params {
+			key, value := entry.GetParam(MAP_KEY), entry.GetParam(MAP_VALUE)
+			entries = append(entries, fmt.Sprintf("%s: %s", key.String(), value.String()))
+		}
+		return fmt.Sprintf("{%s}", strings.Join(entries, ", "))
+
 	case MONAD:
 		s := "Monad{"
 		if len(n.Params) == 1 {
@@ -128,6 +164,17 @@ func (n Node) Equals(other Node) bool {
 		return false
 	}
 
+	// Maps are equal if they have the same entries, regardless of order
+	if n.Type == MAP {
+		for _, entry := range n.Params {
+			otherValue, ok := other.GetMapValue(entry.GetParam(MAP_KEY).Value)
+			if !ok || !entry.GetParam(MAP_VALUE).Equals(*otherValue) {
+				return false
+			}
+		}
+		return true
+	}
+
 	for i := 0; i < len(n.Params); i++ {
 		selfParam := n.Params[i]
 		otherParam := other.Params[i]
@@ -142,10 +189,11 @@ func (n Node) Equals(other Node) bool {
 func (n *Node) Length() (*Node, error) {
 	var length int
 	switch n.Type {
-	case LIST:
+	case LIST, MAP:
 		length = len(n.Params)
 	case STRING:
-		length = len(n.Value)
+		// The number of characters, not bytes (e.g., "héllo" has a length of 5)
+		length = utf8.RuneCountInString(n.Value)
 	default:
 		return nil, utils.CreateError(n.LineNum, "Type %s does not have a length", n.Type)
 	}
@@ -182,6 +230,8 @@ const (
 	BREAK                 = "Break"
 	CONTINUE              = "Continue"
 	RETURN                = "Return"
+	IMPORT                = "Import"
+	EXPORT                = "Export"
 
 	// Expressions
 	EXPR                   = "Expression"
@@ -203,6 +253,8 @@ const (
 	CASE_STMTS             = "CaseStatements"
 	FOR_LOOP               = "ForLoop"
 	FOR_LOOP_ELEM_ASSIGN   = "ForLoopElementAssignment"
+	INTERPOLATION          = "Interpolation"
+	TYPE_ANNOTATION        = "TypeAnnotation"
 
 	// Factors
 	NUMBER           = "Number"
@@ -214,6 +266,11 @@ const (
 	LIST             = "List"
 	MONAD            = "Monad"
 	MONAD_VALUE      = "MonadValue"
+	MODULE           = "Module"
+	MAP              = "Map"
+	MAP_ENTRY        = "MapEntry"
+	MAP_KEY          = "MapKey"
+	MAP_VALUE        = "MapValue"
 )
 
 /*
@@ -279,6 +336,19 @@ var indexMap = map[string]map[string]int{
 	RETURN: {
 		EXPR: 0,
 	},
+	IMPORT: {
+		IDENTIFIER: 0,
+	},
+	EXPORT: {
+		EXPR: 0,
+	},
+	INTERPOLATION: {
+		EXPR: 0,
+	},
+	MAP_ENTRY: {
+		MAP_KEY:   0,
+		MAP_VALUE: 1,
+	},
 }
 
 func CreateTokenNode(token tokens.Token) Node {
@@ -301,14 +371,23 @@ func CreateBooleanFalse(lineNum int) Node {
 	return CreateBoolean(lineNum, tokens.FALSE_TOKEN.Literal)
 }
 
-func CreateString(lineNum int, literal string, parameters []Node) Node {
-	// Strings that contain interpolation
-	return Node{Type: STRING, Value: literal, Params: parameters, LineNum: lineNum}
+func CreateInterpolatedString(lineNum int, parts []Node) Node {
+	/*
+		Strings that contain interpolation. Each part is either the text of the string (a string with no interpolation)
+		or an interpolated expression (see "CreateInterpolation"). The parts are combined in order when the string
+		is evaluated.
+	*/
+	return Node{Type: STRING, Params: parts, LineNum: lineNum}
+}
+
+func CreateInterpolation(lineNum int, expression Node, formatSpec string) Node {
+	// An expression in an interpolated string. The format specifier (e.g., ">8" or ".2f") may be empty.
+	return Node{Type: INTERPOLATION, Value: formatSpec, Params: []Node{expression}, LineNum: lineNum}
 }
 
 func CreateRawString(lineNum int, literal string) Node {
 	// Strings with no interpolation
-	return CreateString(lineNum, literal, []Node{})
+	return Node{Type: STRING, Value: literal, Params: []Node{}, LineNum: lineNum}
 }
 
 func CreateIdentifier(lineNum int, name string) Node {
@@ -372,6 +451,79 @@ func CreateFunction(lineNum int, parameters []Node, statements Node) Node {
 	}
 }
 
+func CreateNamedFunction(lineNum int, name string, parameters []Node, statements Node) Node {
+	// Functions declared with "func name(...) { ... }". The name is stored as the node's value.
+	function := CreateFunction(lineNum, parameters, statements)
+	function.Value = name
+	return function
+}
+
+func CreateModuleFunction(function Node, modulePath string) Node {
+	/*
+		Functions defined in imported modules store the module they were defined in as an additional parameter, so
+		calls to those functions can access the module's global scope instead of the caller's scope.
+	*/
+	if function.GetFunctionModule() != "" {
+		return function
+	}
+
+	params := append([]Node{}, function.Params...)
+	function.Params = append(params, Node{Type: MODULE, Value: modulePath, LineNum: function.LineNum})
+	return function
+}
+
+func (n *Node) GetFunctionModule() string {
+	// Return the path of the module a function was defined in, or an empty string for functions in the main program
+	if n.Type == FUNCTION {
+		for _, param := range n.Params[len(indexMap[FUNCTION]):] {
+			if param.Type == MODULE {
+				return param.Value
+			}
+		}
+	}
+	return ""
+}
+
+func CreateTypeAnnotation(lineNum int, name string, elementType *Node) Node {
+	/*
+		Type annotations (e.g., "Number" in "x: Number = 1") are checked before a program runs and are ignored by the
+		evaluator. Lists and monads can have the type of their values between square brackets (e.g., "List[Number]").
+	*/
+	params := []Node{}
+	if elementType != nil {
+		params = append(params, *elementType)
+	}
+	return Node{Type: TYPE_ANNOTATION, Value: name, LineNum: lineNum, Params: params}
+}
+
+func CreateAnnotatedIdentifier(identifier Node, annotation Node) Node {
+	// Annotated identifiers (in assignments and function parameters) store their type annotation as their only parameter
+	identifier.Params = []Node{annotation}
+	return identifier
+}
+
+func CreateAnnotatedFunction(function Node, returnType Node) Node {
+	// The return type of a function (e.g., "func(a) -> Number { ... }") is stored after the function's other parameters
+	params := append([]Node{}, function.Params...)
+	function.Params = append(params, returnType)
+	return function
+}
+
+func (n *Node) GetTypeAnnotation() *Node {
+	// Return the type annotation of an identifier, or the return type of a function. Returns nil if there is none.
+	params := n.Params
+	if n.Type == FUNCTION {
+		params = params[len(indexMap[FUNCTION]):]
+	}
+
+	for _, param := range params {
+		if param.Type == TYPE_ANNOTATION {
+			return param.Ptr()
+		}
+	}
+	return nil
+}
+
 func CreateFunctionCall(lineNum int, function Node, callParams []Node) Node {
 	return Node{
 		Type:    FUNCTION_CALL,
@@ -480,3 +632,94 @@ func CreateMonad(lineNum int, value *Node) Node {
 		Params:  params,
 	}
 }
+
+func CreateImportStatement(lineNum int, path string, alias Node) Node {
+	return Node{
+		Type:    IMPORT,
+		Value:   path,
+		LineNum: lineNum,
+		Params:  []Node{alias},
+	}
+}
+
+func CreateExportStatement(lineNum int, statement Node) Node {
+	return Node{
+		Value:   tokens.EXPORT_TOKEN.Literal,
+		Type:    EXPORT,
+		LineNum: lineNum,
+		Params:  []Node{statement},
+	}
+}
+
+func CreateModule(lineNum int, path string, members []Node) Node {
+	/*
+		Modules are the values created by import statements. Each member is an assignment node pairing an exported name
+		with its value, so members can be looked up by name with "GetMember".
+	*/
+	return Node{
+		Type:    MODULE,
+		Value:   path,
+		LineNum: lineNum,
+		Params:  members,
+	}
+}
+
+func (n *Node) GetMember(name string) (*Node, error) {
+	if n.Type != MODULE {
+		return nil, utils.CreateError(n.LineNum, "cannot access member %#v on type %s", name, n.ErrorDisplay())
+	}
+
+	for _, member := range n.Params {
+		if member.GetParam(ASSIGN_STMT_IDENTIFIER).Value == name {
+			return member.GetParam(EXPR).Ptr(), nil
+		}
+	}
+	return nil, utils.CreateError(n.LineNum, "module %s has no exported member %#v", n.Value, name)
+}
+
+func CreateMap(lineNum int, entries []Node) Node {
+	/*
+		Maps pair string keys with values. Each entry is a MAP_ENTRY node, and entries are kept in the order they were
+		added so maps are displayed and converted to JSON in a predictable order.
+	*/
+	return Node{
+		Type:    MAP,
+		LineNum: lineNum,
+		Params:  entries,
+	}
+}
+
+func CreateMapEntry(lineNum int, key string, value Node) Node {
+	return Node{
+		Type:    MAP_ENTRY,
+		LineNum: lineNum,
+		Params:  []Node{CreateRawString(lineNum, key), value},
+	}
+}
+
+func (n *Node) GetMapValue(key string) (*Node, bool) {
+	for _, entry := range n.Params {
+		if entry.GetParam(MAP_KEY).Value == key {
+			return entry.GetParam(MAP_VALUE).Ptr(), true
+		}
+	}
+	return nil, false
+}
+
+func (n *Node) SetMapValue(key string, value Node) Node {
+	// Return a copy of the map with the key set to the value. Existing keys keep their position.
+	entries := []Node{}
+	found := false
+	for _, entry := range n.Params {
+		if entry.GetParam(MAP_KEY).Value == key {
+			entry = CreateMapEntry(entry.LineNum, key, value)
+			found = true
+		}
+		entries = append(entries, entry)
+	}
+
+	if !found {
+		entries = append(entries, CreateMapEntry(n.LineNum, key, value))
+	}
+	return CreateMap(n.LineNum, entries)
+}
@@ -0,0 +1,103 @@
+package node
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+Renderers for trees of nodes, for debugging and reading parser output. Each node is shown with its type, value (when it
+has one) and line number, and parameters with names in "indexMap" are shown with their names. For example, the tree for
+"x = 1;" is:
+
+	Assign (line 1)
+	  Identifier: Identifier "x" (line 1)
+	  Expression: Number "1" (line 1)
+*/
+
+// Tree renders nodes as an indented tree, with two spaces of indentation for each level.
+func Tree(nodes []Node) string {
+	var builder strings.Builder
+	for _, n := range nodes {
+		Inspect(n, func(c *Cursor) bool {
+			builder.WriteString(strings.Repeat("  ", c.Depth()))
+			if key := getParamName(c); key != "" {
+				builder.WriteString(key + ": ")
+			}
+			builder.WriteString(strings.Join(getLabelParts(c.Node), " "))
+			builder.WriteString("\n")
+			return true
+		})
+	}
+	return builder.String()
+}
+
+/*
+DOT renders nodes as a Graphviz graph (e.g., "dot -Tsvg ast.dot -o ast.svg"). The nodes are the children of a
+"Program" node, and edges to parameters with names are labeled with their names.
+*/
+func DOT(nodes []Node) string {
+	var builder strings.Builder
+	builder.WriteString("digraph AST {\n")
+	builder.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	builder.WriteString("  n0 [label=\"Program\"];\n")
+
+	// IDs of the nodes containing the current node, starting with the root
+	count := 1
+	ids := []int{}
+	for _, n := range nodes {
+		Walk(
+			n,
+			func(c *Cursor) bool {
+				id := count
+				count += 1
+
+				labelParts := []string{}
+				for _, part := range getLabelParts(c.Node) {
+					labelParts = append(labelParts, escapeDOT(part))
+				}
+				fmt.Fprintf(&builder, "  n%d [label=\"%s\"];\n", id, strings.Join(labelParts, "\\n"))
+
+				parentID := 0
+				if len(ids) > 0 {
+					parentID = ids[len(ids)-1]
+				}
+				if key := getParamName(c); key != "" {
+					fmt.Fprintf(&builder, "  n%d -> n%d [label=\"%s\"];\n", parentID, id, escapeDOT(key))
+				} else {
+					fmt.Fprintf(&builder, "  n%d -> n%d;\n", parentID, id)
+				}
+
+				ids = append(ids, id)
+				return true
+			},
+			func(c *Cursor) {
+				ids = ids[:len(ids)-1]
+			},
+		)
+	}
+
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+func getParamName(c *Cursor) string {
+	parent, ok := c.Parent()
+	if !ok {
+		return ""
+	}
+	return paramName(parent.Type, c.Index)
+}
+
+func getLabelParts(n Node) []string {
+	parts := []string{n.Type}
+	if n.Value != "" {
+		parts = append(parts, strconv.Quote(n.Value))
+	}
+	return append(parts, fmt.Sprintf("(line %d)", n.LineNum))
+}
+
+func escapeDOT(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(s)
+}
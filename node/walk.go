@@ -0,0 +1,86 @@
+package node
+
+/*
+Utilities for traversing and transforming trees of nodes. Analyses (e.g., finding every identifier in a program) can
+use these functions instead of recursing through "Params" themselves.
+
+Nodes are visited in depth-first order, and each node's parameters are visited in the order they are stored in
+"Params" (see "indexMap").
+*/
+
+// Cursor describes a node found while traversing a tree.
+type Cursor struct {
+	Node Node
+
+	/*
+		The nodes containing "Node", starting with the root. "Parents" is only valid during the call it is passed to, so
+		it must be copied to be kept.
+	*/
+	Parents []Node
+
+	Index int // Index of "Node" in its parent's parameters, or -1 for the root
+}
+
+// Parent returns the node containing the cursor's node, or false for the root.
+func (c *Cursor) Parent() (Node, bool) {
+	if len(c.Parents) == 0 {
+		return Node{}, false
+	}
+	return c.Parents[len(c.Parents)-1], true
+}
+
+func (c *Cursor) Depth() int {
+	return len(c.Parents)
+}
+
+/*
+Walk traverses a tree, calling "pre" before a node's parameters are visited and "post" after. If "pre" returns false,
+the node's parameters (and "post" for that node) are skipped. Either function may be nil.
+*/
+func Walk(root Node, pre func(*Cursor) bool, post func(*Cursor)) {
+	walk(&Cursor{Node: root, Parents: []Node{}, Index: -1}, pre, post)
+}
+
+func walk(cursor *Cursor, pre func(*Cursor) bool, post func(*Cursor)) {
+	if pre != nil && !pre(cursor) {
+		return
+	}
+
+	current, parents, index := cursor.Node, cursor.Parents, cursor.Index
+	for i, param := range current.Params {
+		walk(&Cursor{Node: param, Parents: append(parents, current), Index: i}, pre, post)
+	}
+
+	if post != nil {
+		cursor.Node, cursor.Parents, cursor.Index = current, parents, index
+		post(cursor)
+	}
+}
+
+// Inspect traverses a tree, calling "f" for each node. If "f" returns false, the node's parameters are skipped.
+func Inspect(root Node, f func(*Cursor) bool) {
+	Walk(root, f, nil)
+}
+
+/*
+Rewrite creates a new tree by calling "f" for each node, after the node's parameters have been rewritten, and using the
+node it returns in place of the original node. The cursor's node has the rewritten parameters, but its parents are the
+nodes in the original tree. The original tree is not modified.
+*/
+func Rewrite(root Node, f func(*Cursor) Node) Node {
+	return rewrite(&Cursor{Node: root, Parents: []Node{}, Index: -1}, f)
+}
+
+func rewrite(cursor *Cursor, f func(*Cursor) Node) Node {
+	original := cursor.Node
+
+	// Copy parameters instead of modifying them because slices of parameters may be shared with other nodes
+	if len(original.Params) > 0 {
+		params := make([]Node, len(original.Params))
+		for i, param := range original.Params {
+			params[i] = rewrite(&Cursor{Node: param, Parents: append(cursor.Parents, original), Index: i}, f)
+		}
+		cursor.Node.Params = params
+	}
+	return f(cursor)
+}
@@ -1,12 +1,12 @@
 package parser
 
 import (
+	"boomerang/ast"
 	"boomerang/evaluator"
 	"boomerang/node"
 	"boomerang/tokens"
 	"boomerang/utils"
 	"fmt"
-	"regexp"
 	"strings"
 )
 
@@ -18,6 +18,7 @@ const (
 	PRODUCT
 	SEND
 	INDEX
+	MEMBER
 )
 
 var precedenceLevels = map[string]int{
@@ -36,30 +37,73 @@ var precedenceLevels = map[string]int{
 	tokens.LT:            COMPARE,
 	tokens.IN:            COMPARE,
 	tokens.ASSIGN:        ASSIGN,
+	tokens.PERIOD:        MEMBER,
 }
 
+func init() {
+	/*
+		The evaluator needs to parse imported modules, but this package imports the evaluator package, so the parser is
+		registered with the evaluator here instead of the evaluator importing this package.
+	*/
+	evaluator.RegisterParser(func(source string) ([]ast.Statement, error) {
+		parserObj, err := NewParser(tokens.NewTokenizer(source))
+		if err != nil {
+			return nil, err
+		}
+		return parserObj.ParseTyped()
+	})
+}
+
+// TokenSource provides the tokens the parser reads, like "tokens.Tokenizer". Sources return EOF tokens after the last token.
+type TokenSource interface {
+	Next() (*tokens.Token, error)
+}
+
+/*
+SyntaxSpan is a piece of syntax recognized by the parser. It covers the tokens from "Start" up to, but not including,
+"End", where tokens are counted from 0. "Kind" is the type of the node the parser created for it (e.g., "Function") or
+one of the SYNTAX_* kinds.
+*/
+type SyntaxSpan struct {
+	Kind  string
+	Start int
+	End   int
+}
+
+const (
+	SYNTAX_STATEMENT          = "Statement"         // A statement and the semicolon after it
+	SYNTAX_GROUPED_EXPRESSION = "GroupedExpression" // An expression between parentheses
+)
+
 type Parser struct {
-	tokenizer tokens.Tokenizer
+	tokenizer TokenSource
 	current   tokens.Token
 	peek      tokens.Token
+	position  int           // Index of the current token in the token stream
+	spans     *[]SyntaxSpan // Recognized syntax, in the order it was finished, when parsing with "ParseSyntax"
 }
 
 func NewParser(tokenizer tokens.Tokenizer) (*Parser, error) {
-	currentToken, err := tokenizer.Next()
+	return NewParserFromTokens(&tokenizer)
+}
+
+func NewParserFromTokens(source TokenSource) (*Parser, error) {
+	currentToken, err := source.Next()
 	if err != nil {
 		return nil, err
 	}
 
-	peekToken, err := tokenizer.Next()
+	peekToken, err := source.Next()
 	if err != nil {
 		return nil, err
 	}
 
-	return &Parser{tokenizer: tokenizer, current: *currentToken, peek: *peekToken}, nil
+	return &Parser{tokenizer: source, current: *currentToken, peek: *peekToken}, nil
 }
 
 func (p *Parser) advance() error {
 	p.current = p.peek
+	p.position += 1
 	nextToken, err := p.tokenizer.Next()
 	if err != nil {
 		return err
@@ -77,6 +121,46 @@ func (p Parser) Parse() (*[]node.Node, error) {
 	return statements, nil
 }
 
+// ParseTyped parses the program like "Parse", and returns the statements as a typed AST (see the "ast" package).
+func (p Parser) ParseTyped() ([]ast.Statement, error) {
+	statements, err := p.parseGlobalStatements()
+	if err != nil {
+		return nil, err
+	}
+	return ast.FromNodes(*statements)
+}
+
+/*
+ParseSyntax parses the program like "Parse", and also returns the spans of tokens that make up each statement and
+expression. Spans are always nested, so they can be used to build a tree of the tokens (see the "cst" package).
+*/
+func (p Parser) ParseSyntax() (*[]node.Node, []SyntaxSpan, error) {
+	spans := []SyntaxSpan{}
+	p.spans = &spans
+
+	statements, err := p.parseGlobalStatements()
+	if err != nil {
+		return nil, nil, err
+	}
+	return statements, spans, nil
+}
+
+func (p *Parser) recordSyntax(kind string, start int) {
+	/*
+		Record the tokens from "start" up to the current token as a piece of syntax. A span covering the same tokens as
+		the last span is skipped, so an expression is not recorded again by each function that returns it.
+	*/
+	if p.spans == nil || start == p.position {
+		return
+	}
+
+	spans := *p.spans
+	if len(spans) > 0 && spans[len(spans)-1].Start == start && spans[len(spans)-1].End == p.position {
+		return
+	}
+	*p.spans = append(spans, SyntaxSpan{Kind: kind, Start: start, End: p.position})
+}
+
 func (p *Parser) parseStatements(terminatingToken tokens.Token) (*[]node.Node, error) {
 	statements := []node.Node{}
 	for p.current.Type != terminatingToken.Type {
@@ -98,18 +182,21 @@ func (p *Parser) parseGlobalStatements() (*[]node.Node, error) {
 }
 
 func (p *Parser) parseBlockStatements() (*node.Node, error) {
+	start := p.position
 	statements, err := p.parseStatements(tokens.CLOSED_CURLY_BRACKET_TOKEN)
 	if err != nil {
 		return nil, err
 	}
 
 	blockStatementsNode := node.CreateBlockStatements(*statements)
+	p.recordSyntax(blockStatementsNode.Type, start)
 	return &blockStatementsNode, nil
 }
 
 func (p *Parser) parseStatement() (*node.Node, error) {
 	var returnNode *node.Node
 	var err error
+	start := p.position
 
 	if tokens.TokenTypesEqual(p.current, tokens.WHILE) {
 		returnNode, err = p.parseWhileLoop()
@@ -123,19 +210,28 @@ func (p *Parser) parseStatement() (*node.Node, error) {
 	} else if tokens.TokenTypesEqual(p.current, tokens.RETURN) {
 		returnNode, err = p.parseReturnStatement()
 
+	} else if tokens.TokenTypesEqual(p.current, tokens.IMPORT) {
+		returnNode, err = p.parseImportStatement()
+
+	} else if tokens.TokenTypesEqual(p.current, tokens.EXPORT) {
+		returnNode, err = p.parseExportStatement()
+
 	} else {
-		returnNode, err = p.parseExpression(LOWEST)
+		returnNode, err = p.parseExpressionStatement()
 	}
 
 	if err != nil {
 		// This error check needs to return so the below expected-token error does not overwrite this error
 		return nil, err
 	}
+	p.recordSyntax(returnNode.Type, start)
 
 	// Check that token at end of statement is a semicolon
 	if expectedTokenErr := p.expectToken(tokens.SEMICOLON_TOKEN); expectedTokenErr != nil {
 		returnNode = nil
 		err = expectedTokenErr
+	} else {
+		p.recordSyntax(SYNTAX_STATEMENT, start)
 	}
 
 	return returnNode, err
@@ -179,6 +275,129 @@ func (p *Parser) parseReturnStatement() (*node.Node, error) {
 	return node.CreateReturnStatement(lineNum, *returnExpression).Ptr(), nil
 }
 
+func (p *Parser) parseImportStatement() (*node.Node, error) {
+
+	lineNum := p.current.LineNumber
+
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	// The module path is a string literal; interpolation is not evaluated
+	pathToken := p.current
+	if err := p.expectToken(tokens.STRING_TOKEN); err != nil {
+		return nil, err
+	}
+
+	if err := p.expectToken(tokens.AS_TOKEN); err != nil {
+		return nil, err
+	}
+
+	aliasToken := p.current
+	if err := p.expectToken(tokens.IDENTIFIER_TOKEN); err != nil {
+		return nil, err
+	}
+	alias := node.CreateIdentifier(aliasToken.LineNumber, aliasToken.Literal)
+
+	return node.CreateImportStatement(lineNum, pathToken.Literal, alias).Ptr(), nil
+}
+
+func (p *Parser) parseExportStatement() (*node.Node, error) {
+
+	lineNum := p.current.LineNumber
+
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	statement, err := p.parseExpressionStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	// Only constructs that bind names can be exported: variable assignments, named functions, and identifiers
+	isNamedFunction := statement.Type == node.FUNCTION && statement.Value != ""
+	if statement.Type != node.ASSIGN_STMT && statement.Type != node.IDENTIFIER && !isNamedFunction {
+		return nil, utils.CreateError(lineNum, "invalid type for export: %s", statement.ErrorDisplay())
+	}
+
+	return node.CreateExportStatement(lineNum, *statement).Ptr(), nil
+}
+
+func (p *Parser) parseExpressionStatement() (*node.Node, error) {
+	// Assignments with a type annotation (e.g., "x: Number = 1") can only be statements, not parts of other expressions
+	if p.current.Type == tokens.IDENTIFIER && p.peek.Type == tokens.COLON {
+		return p.parseAnnotatedAssignment()
+	}
+	return p.parseExpression(LOWEST)
+}
+
+func (p *Parser) parseAnnotatedAssignment() (*node.Node, error) {
+	start := p.position
+	identifier, err := p.parseIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	p.recordSyntax(identifier.Type, start)
+
+	// Skip over colon
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	annotation, err := p.parseTypeAnnotation()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expectToken(tokens.ASSIGN_TOKEN); err != nil {
+		return nil, err
+	}
+
+	value, err := p.parseExpression(LOWEST)
+	if err != nil {
+		return nil, err
+	}
+
+	assignmentNode := node.CreateAssignmentNode(node.CreateAnnotatedIdentifier(*identifier, *annotation), *value)
+	p.recordSyntax(assignmentNode.Type, start)
+	return &assignmentNode, nil
+}
+
+func (p *Parser) parseTypeAnnotation() (*node.Node, error) {
+	/*
+		Type annotations are the name of a type, optionally followed by the type of the values in a list or monad
+		between square brackets (e.g., "Number" or "List[Number]"). Names are checked by the type checker, not the
+		parser.
+	*/
+	start := p.position
+	nameToken := p.current
+	if err := p.expectToken(tokens.IDENTIFIER_TOKEN); err != nil {
+		return nil, err
+	}
+
+	var elementType *node.Node
+	if tokens.TokenTypesEqual(p.current, tokens.OPEN_BRACKET) {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		var err error
+		elementType, err = p.parseTypeAnnotation()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expectToken(tokens.CLOSED_BRACKET_TOKEN); err != nil {
+			return nil, err
+		}
+	}
+
+	annotation := node.CreateTypeAnnotation(nameToken.LineNumber, nameToken.Literal, elementType)
+	p.recordSyntax(annotation.Type, start)
+	return &annotation, nil
+}
+
 func (p *Parser) parseWhileLoop() (*node.Node, error) {
 
 	lineNum := p.current.LineNumber
@@ -204,16 +423,19 @@ func (p *Parser) parseWhileLoop() (*node.Node, error) {
 }
 
 func (p *Parser) parseExpression(precedenceLevel int) (*node.Node, error) {
+	start := p.position
 	left, err := p.parsePrefix()
 	if err != nil {
 		return nil, err
 	}
+	p.recordSyntax(left.Type, start)
 
 	for precedenceLevel < p.getPrecedenceLevel(p.current) {
 		left, err = p.parseInfix(*left)
 		if err != nil {
 			return nil, err
 		}
+		p.recordSyntax(left.Type, start)
 	}
 
 	return left, nil
@@ -231,6 +453,12 @@ func (p *Parser) parsePrefix() (*node.Node, error) {
 	case tokens.STRING:
 		return p.parseString()
 
+	case tokens.RAW_STRING:
+		return p.parseRawString()
+
+	case tokens.STRING_START:
+		return p.parseInterpolatedString()
+
 	case tokens.MINUS, tokens.NOT:
 		return p.parseUnaryExpression()
 
@@ -284,6 +512,18 @@ func (p *Parser) parseInfix(left node.Node) (*node.Node, error) {
 		assignmentNode := node.CreateAssignmentNode(left, *right)
 		return &assignmentNode, nil
 
+	case tokens.PERIOD:
+		/*
+			Member names are not evaluated, so they are not parsed as expressions (otherwise, a member with the same name
+			as a builtin would be parsed as that builtin).
+		*/
+		memberToken := p.current
+		if err := p.expectToken(tokens.IDENTIFIER_TOKEN); err != nil {
+			return nil, err
+		}
+		member := node.CreateIdentifier(memberToken.LineNumber, memberToken.Literal)
+		return node.CreateBinaryExpression(left, op, member).Ptr(), nil
+
 	default:
 		right, err := p.parseExpression(p.getPrecedenceLevel(op))
 		if err != nil {
@@ -326,7 +566,13 @@ func (p *Parser) parseNumber() (*node.Node, error) {
 		return nil, err
 	}
 
-	numberNode := node.CreateNumber(numberToken.LineNumber, numberToken.Literal)
+	// Hexadecimal, scientific notation, etc. are converted to decimals (e.g., "0xff" is "255")
+	value, err := tokens.NumberValue(numberToken.Literal)
+	if err != nil {
+		return nil, utils.CreateError(numberToken.LineNumber, "%s", err.Error())
+	}
+
+	numberNode := node.CreateNumber(numberToken.LineNumber, value)
 	return &numberNode, nil
 }
 
@@ -342,49 +588,114 @@ func (p *Parser) parseBoolean() (*node.Node, error) {
 }
 
 func (p *Parser) parseString() (*node.Node, error) {
-	stringLiteral := p.current.Literal
-	lineNumber := p.current.LineNumber
+	text, err := decodeEscapeSequences(p.current)
+	if err != nil {
+		return nil, err
+	}
 
-	params := []node.Node{}
-	expressionIndex := 0
+	stringNode := node.CreateRawString(p.current.LineNumber, text)
+
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return &stringNode, nil
+}
+
+func (p *Parser) parseRawString() (*node.Node, error) {
+	// Raw strings have no escape sequences or interpolation, so the literal is used as-is
+	stringNode := node.CreateRawString(p.current.LineNumber, p.current.Literal)
+
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return &stringNode, nil
+}
+
+func (p *Parser) parseInterpolatedString() (*node.Node, error) {
+	/*
+		Interpolated strings are tokenized as a STRING_START token, followed by the tokens for each expression, each
+		separated by a STRING_MIDDLE token, and ending with a STRING_END token. Expressions can be followed by a
+		FORMAT_SPEC token.
+	*/
+	lineNumber := p.current.LineNumber
+	parts := []node.Node{}
 
-	// Parse each expression block in the string interpolation and save the result to the string
-	r := regexp.MustCompile(`{[^{}]*}`)
 	for {
-		match := r.FindStringIndex(stringLiteral)
-		if len(match) == 0 {
+		text, err := decodeEscapeSequences(p.current)
+		if err != nil {
+			return nil, err
+		}
+		if text != "" {
+			parts = append(parts, node.CreateRawString(p.current.LineNumber, text))
+		}
+
+		if tokens.TokenTypesEqual(p.current, tokens.STRING_END) {
 			break
 		}
 
-		startPos := match[0]
-		endPos := match[1]
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
 
-		expressionInString := stringLiteral[startPos+1 : endPos-1]
+		if tokens.TokenTypesEqual(p.current, tokens.STRING_MIDDLE) || tokens.TokenTypesEqual(p.current, tokens.STRING_END) {
+			return nil, utils.CreateError(p.current.LineNumber, "missing expression in string interpolation")
+		}
 
-		tokenizer := tokens.NewTokenizer(expressionInString)
-		parserObj, err := NewParser(tokenizer)
+		expressionLineNumber := p.current.LineNumber
+		expression, err := p.parseExpression(LOWEST)
 		if err != nil {
 			return nil, err
 		}
 
-		expression, err := parserObj.parseExpression(LOWEST)
-		if err != nil {
-			return nil, err
+		formatSpec := ""
+		if tokens.TokenTypesEqual(p.current, tokens.FORMAT_SPEC) {
+			formatSpec = p.current.Literal
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+
+		if !tokens.TokenTypesEqual(p.current, tokens.STRING_MIDDLE) && !tokens.TokenTypesEqual(p.current, tokens.STRING_END) {
+			return nil, utils.CreateError(
+				p.current.LineNumber,
+				"expected } after expression in string interpolation, got %s",
+				p.current.ErrorDisplay(),
+			)
 		}
-		params = append(params, *expression)
 
-		stringLiteral = strings.Replace(stringLiteral, stringLiteral[startPos:endPos], fmt.Sprintf("<%d>", expressionIndex), 1)
-		expressionIndex += 1
+		parts = append(parts, node.CreateInterpolation(expressionLineNumber, *expression, formatSpec))
 	}
 
 	if err := p.advance(); err != nil {
 		return nil, err
 	}
 
-	stringNode := node.CreateString(lineNumber, stringLiteral, params)
+	stringNode := node.CreateInterpolatedString(lineNumber, parts)
 	return &stringNode, nil
 }
 
+func decodeEscapeSequences(token tokens.Token) (string, error) {
+	// The tokenizer checks escape sequences are valid, but leaves decoding them to the parser
+	var text strings.Builder
+	literal := token.Literal
+
+	for i := 0; i < len(literal); {
+		if literal[i] != '\\' {
+			text.WriteByte(literal[i])
+			i += 1
+			continue
+		}
+
+		decoded, size, err := tokens.ReadEscapeSequence(literal[i:])
+		if err != nil {
+			return "", utils.CreateError(token.LineNumber, "%s", err.Error())
+		}
+		text.WriteString(decoded)
+		i += size
+	}
+	return text.String(), nil
+}
+
 func (p *Parser) parseUnaryExpression() (*node.Node, error) {
 	op := p.current
 	if err := p.advance(); err != nil {
@@ -402,6 +713,7 @@ func (p *Parser) parseUnaryExpression() (*node.Node, error) {
 func (p *Parser) parseGroupedExpression() (*node.Node, error) {
 
 	lineNumber := p.current.LineNumber
+	start := p.position
 
 	// Skip over open parenthesis
 	if err := p.advance(); err != nil {
@@ -429,6 +741,7 @@ func (p *Parser) parseGroupedExpression() (*node.Node, error) {
 		if err := p.advance(); err != nil {
 			return nil, err
 		}
+		p.recordSyntax(SYNTAX_GROUPED_EXPRESSION, start)
 		return expression, nil
 
 	// Commas denote list creation
@@ -473,14 +786,31 @@ func (p *Parser) parseFunctionParameters() (*node.Node, error) {
 			break
 		}
 
-		if p.current.Type == tokens.IDENTIFIER && p.peek.Type == tokens.ASSIGN {
-			identifierNode := node.CreateIdentifier(p.current.LineNumber, p.current.Literal)
+		if p.current.Type != tokens.IDENTIFIER {
+			return nil, utils.CreateError(p.current.LineNumber, "invalid type for function parameter: %s", p.current.Type)
+		}
+
+		identifierNode := node.CreateIdentifier(p.current.LineNumber, p.current.Literal)
 
-			// Advance past identifier
+		// Advance past identifier
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		// Parameters can have a type annotation (e.g., "a: Number")
+		if tokens.TokenTypesEqual(p.current, tokens.COLON) {
 			if err := p.advance(); err != nil {
 				return nil, err
 			}
 
+			annotation, err := p.parseTypeAnnotation()
+			if err != nil {
+				return nil, err
+			}
+			identifierNode = node.CreateAnnotatedIdentifier(identifierNode, *annotation)
+		}
+
+		if tokens.TokenTypesEqual(p.current, tokens.ASSIGN) {
 			// Advance past assignment operator
 			if err := p.advance(); err != nil {
 				return nil, err
@@ -494,17 +824,8 @@ func (p *Parser) parseFunctionParameters() (*node.Node, error) {
 			keywordArgumentNode := node.CreateAssignmentNode(identifierNode, *value)
 			params = append(params, keywordArgumentNode)
 
-		} else if p.current.Type == tokens.IDENTIFIER {
-			identifierNode := node.CreateIdentifier(p.current.LineNumber, p.current.Literal)
-
-			if err := p.advance(); err != nil {
-				return nil, err
-			}
-
-			params = append(params, identifierNode)
-
 		} else {
-			return nil, utils.CreateError(p.current.LineNumber, "invalid type for function parameter: %s", p.current.Type)
+			params = append(params, identifierNode)
 		}
 
 		if tokens.TokenTypesEqual(p.current, tokens.COMMA) {
@@ -566,6 +887,15 @@ func (p *Parser) parseFunction() (*node.Node, error) {
 		return nil, err
 	}
 
+	// Named function declarations (e.g., "func add(a, b) { ... }") have an identifier before the parameters
+	var name string
+	if tokens.TokenTypesEqual(p.current, tokens.IDENTIFIER) {
+		name = p.current.Literal
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := p.expectToken(tokens.OPEN_PAREN_TOKEN); err != nil {
 		return nil, err
 	}
@@ -575,6 +905,19 @@ func (p *Parser) parseFunction() (*node.Node, error) {
 		return nil, err
 	}
 
+	// Return type (e.g., "func(a, b) -> Number { ... }")
+	var returnType *node.Node
+	if tokens.TokenTypesEqual(p.current, tokens.ARROW) {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		returnType, err = p.parseTypeAnnotation()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if err := p.expectToken(tokens.OPEN_CURLY_BRACKET_TOKEN); err != nil {
 		return nil, err
 	}
@@ -583,7 +926,10 @@ func (p *Parser) parseFunction() (*node.Node, error) {
 		return nil, err
 	}
 
-	functionNode := node.CreateFunction(lineNumber, parameters.Params, *statements)
+	functionNode := node.CreateNamedFunction(lineNumber, name, parameters.Params, *statements)
+	if returnType != nil {
+		functionNode = node.CreateAnnotatedFunction(functionNode, *returnType)
+	}
 	return &functionNode, nil
 }
 
@@ -0,0 +1,348 @@
+package resolver
+
+import (
+	"boomerang/ast"
+	"boomerang/evaluator"
+	"boomerang/tokens"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*
+The resolver finds the declaration of each identifier in a program before it runs, so mistakes like misspelled names
+are reported even when they are in code that rarely runs.
+
+Scopes follow the evaluator: the program and each function body have a scope, and blocks in "when", "for" and
+"while" expressions use the scope they are in. Names are declared by assignments, function parameters, for-loop
+variables, named functions and imports. Statements are resolved in order, so a name must be assigned before it is
+used, with two exceptions:
+  - Named functions are declared before the statements in their block (see "declareFunctions" in the evaluator).
+  - Function bodies are resolved after the rest of the scope they are defined in, so functions can use names
+    assigned after them.
+
+Resolution is lexical, but the evaluator scopes names dynamically: when a function is called, its scope's parent is
+the scope of the caller, not the scope it is defined in. The resolver is a lint-level approximation of the evaluator,
+so its diagnostics can be wrong in both directions:
+  - A function that uses a name from the scope of its callers is reported as using an undefined name, even though
+    the name is found when the program runs.
+  - A function that uses a name from the scope it is defined in is not reported, even if it is called from a scope
+    where the name is not defined (e.g., a function returned from another function).
+*/
+
+const (
+	ERROR   = "error"
+	WARNING = "warning"
+
+	// Diagnostic codes
+	UNDEFINED        = "undefined"
+	SHADOWED_BUILTIN = "shadowed-builtin"
+	UNUSED           = "unused"
+
+	// Kinds of declarations
+	VARIABLE  = "variable"
+	PARAMETER = "parameter"
+	FUNCTION  = "function"
+	IMPORT    = "import"
+)
+
+type Diagnostic struct {
+	LineNum  int
+	Severity string
+	Code     string
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s at line %d: %s", d.Severity, d.LineNum, d.Message)
+}
+
+type Result struct {
+	Diagnostics []Diagnostic // Sorted by line number
+}
+
+// HasErrors returns true if any of the diagnostics are errors.
+func (r *Result) HasErrors() bool {
+	for _, diagnostic := range r.Diagnostics {
+		if diagnostic.Severity == ERROR {
+			return true
+		}
+	}
+	return false
+}
+
+type symbol struct {
+	name     string
+	kind     string
+	lineNum  int
+	used     bool
+	exported bool
+}
+
+type scope struct {
+	parent    *scope
+	symbols   map[string]*symbol
+	order     []*symbol       // Symbols in the order they were declared, so unused symbols are reported in order
+	functions []*ast.Function // Functions to resolve after the rest of the scope
+}
+
+func newScope(parent *scope) *scope {
+	return &scope{parent: parent, symbols: map[string]*symbol{}}
+}
+
+type resolver struct {
+	scope  *scope
+	result *Result
+}
+
+// Resolve checks the names in a program.
+func Resolve(statements []ast.Statement) *Result {
+	r := &resolver{
+		scope:  newScope(nil),
+		result: &Result{Diagnostics: []Diagnostic{}},
+	}
+
+	r.resolveStatements(statements)
+	r.closeScope()
+
+	sort.SliceStable(r.result.Diagnostics, func(i, j int) bool {
+		return r.result.Diagnostics[i].LineNum < r.result.Diagnostics[j].LineNum
+	})
+	return r.result
+}
+
+func (r *resolver) report(lineNum int, severity, code, message string, values ...any) {
+	r.result.Diagnostics = append(r.result.Diagnostics, Diagnostic{
+		LineNum:  lineNum,
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(message, values...),
+	})
+}
+
+func (r *resolver) closeScope() {
+	// Functions can add more functions to resolve, so the list is checked again after each function
+	for len(r.scope.functions) > 0 {
+		function := r.scope.functions[0]
+		r.scope.functions = r.scope.functions[1:]
+		r.resolveFunctionBody(function)
+	}
+
+	for _, s := range r.scope.order {
+		if !s.used && !s.exported && !strings.HasPrefix(s.name, "_") {
+			r.report(s.lineNum, WARNING, UNUSED, "unused %s %#v", s.kind, s.name)
+		}
+	}
+}
+
+func (r *resolver) declare(identifier *ast.Identifier, kind string) *symbol {
+	if evaluator.IsBuiltin(identifier.Name) {
+		// Builtin names cannot be assigned, but parameters with those names are allowed (and cannot be used)
+		severity := ERROR
+		if kind == PARAMETER {
+			severity = WARNING
+		}
+		r.report(identifier.LineNum, severity, SHADOWED_BUILTIN, "%s %#v has the same name as a builtin function or variable", kind, identifier.Name)
+
+		// Uses of the name are always the builtin, so the declaration is not added to the scope
+		return &symbol{name: identifier.Name, kind: kind, lineNum: identifier.LineNum}
+	}
+
+	// Assigning a name again in the same scope changes its value instead of declaring it again
+	if s, ok := r.scope.symbols[identifier.Name]; ok {
+		return s
+	}
+
+	s := &symbol{name: identifier.Name, kind: kind, lineNum: identifier.LineNum}
+	r.scope.symbols[identifier.Name] = s
+	r.scope.order = append(r.scope.order, s)
+	return s
+}
+
+func (r *resolver) use(identifier *ast.Identifier) {
+	for s := r.scope; s != nil; s = s.parent {
+		if found, ok := s.symbols[identifier.Name]; ok {
+			found.used = true
+			return
+		}
+	}
+	r.report(identifier.LineNum, ERROR, UNDEFINED, "undefined identifier: %s", identifier.Name)
+}
+
+func (r *resolver) declareFunctions(statements []ast.Statement) {
+	for _, statement := range statements {
+		exported := false
+		if export, ok := statement.(*ast.Export); ok {
+			statement, exported = export.Statement, true
+		}
+
+		if function, ok := statement.(*ast.Function); ok && function.Name != "" {
+			s := r.declare(&ast.Identifier{Position: function.Position, Name: function.Name}, FUNCTION)
+			s.exported = s.exported || exported
+		}
+	}
+}
+
+func (r *resolver) resolveStatements(statements []ast.Statement) {
+	r.declareFunctions(statements)
+	for _, statement := range statements {
+		r.resolveStatement(statement)
+	}
+}
+
+func (r *resolver) resolveBlock(block *ast.BlockStatements) {
+	r.resolveStatements(block.Statements)
+}
+
+func (r *resolver) resolveStatement(statement ast.Statement) {
+	switch statement := statement.(type) {
+	case *ast.WhileLoop:
+		r.resolveExpression(statement.Condition)
+		r.resolveBlock(statement.Body)
+
+	case *ast.Return:
+		r.resolveExpression(statement.Value)
+
+	case *ast.Import:
+		r.declare(statement.Alias, IMPORT)
+
+	case *ast.Export:
+		r.resolveExport(statement)
+
+	case *ast.Break, *ast.Continue:
+
+	case ast.Expression:
+		r.resolveExpression(statement)
+
+	default:
+		panic(fmt.Sprintf("invalid statement: %T", statement))
+	}
+}
+
+func (r *resolver) resolveExport(export *ast.Export) {
+	switch statement := export.Statement.(type) {
+	case *ast.Identifier:
+		r.use(statement)
+		if s, ok := r.scope.symbols[statement.Name]; ok {
+			s.exported = true
+		}
+
+	case *ast.Assignment:
+		r.resolveExpression(statement.Value)
+		for _, s := range r.declareTargets(statement.Target, VARIABLE) {
+			s.exported = true
+		}
+
+	default:
+		// Named functions are declared with the other functions in the scope
+		r.resolveStatement(statement)
+	}
+}
+
+func (r *resolver) declareTargets(target ast.Expression, kind string) []*symbol {
+	// Values are assigned to an identifier or a list of identifiers. Other targets are errors at runtime.
+	switch target := target.(type) {
+	case *ast.Identifier:
+		return []*symbol{r.declare(target, kind)}
+
+	case *ast.List:
+		symbols := []*symbol{}
+		for _, element := range target.Elements {
+			symbols = append(symbols, r.declareTargets(element, kind)...)
+		}
+		return symbols
+
+	case *ast.BuiltinFunction:
+		r.declare(&ast.Identifier{Position: target.Position, Name: target.Name}, kind)
+
+	case *ast.BuiltinVariable:
+		r.declare(&ast.Identifier{Position: target.Position, Name: target.Name}, kind)
+
+	default:
+		r.resolveExpression(target)
+	}
+	return []*symbol{}
+}
+
+func (r *resolver) resolveExpression(expression ast.Expression) {
+	switch expression := expression.(type) {
+	case *ast.Number, *ast.Boolean, *ast.String, *ast.BuiltinVariable, *ast.BuiltinFunction:
+
+	case *ast.Identifier:
+		r.use(expression)
+
+	case *ast.InterpolatedString:
+		for _, part := range expression.Parts {
+			if interpolation, ok := part.(*ast.Interpolation); ok {
+				r.resolveExpression(interpolation.Expression)
+			}
+		}
+
+	case *ast.List:
+		for _, element := range expression.Elements {
+			r.resolveExpression(element)
+		}
+
+	case *ast.UnaryExpression:
+		r.resolveExpression(expression.Expression)
+
+	case *ast.BinaryExpression:
+		r.resolveExpression(expression.Left)
+
+		// The right side of "." is the name of a module member, not a variable
+		if expression.Operator.Type != tokens.PERIOD {
+			r.resolveExpression(expression.Right)
+		}
+
+	case *ast.Assignment:
+		r.resolveExpression(expression.Value)
+		r.declareTargets(expression.Target, VARIABLE)
+
+	case *ast.Function:
+		r.scope.functions = append(r.scope.functions, expression)
+
+	case *ast.FunctionCall:
+		r.resolveExpression(expression.Function)
+		for _, argument := range expression.Arguments {
+			r.resolveExpression(argument)
+		}
+
+	case *ast.When:
+		r.resolveExpression(expression.Value)
+		for _, whenCase := range expression.Cases {
+			r.resolveExpression(whenCase.Value)
+			r.resolveBlock(whenCase.Body)
+		}
+		r.resolveBlock(expression.Else)
+
+	case *ast.ForLoop:
+		r.resolveExpression(expression.Values)
+		r.declareTargets(expression.Variables, VARIABLE)
+		r.resolveBlock(expression.Body)
+
+	default:
+		panic(fmt.Sprintf("invalid expression: %T", expression))
+	}
+}
+
+func (r *resolver) resolveFunctionBody(function *ast.Function) {
+	parent := r.scope
+	r.scope = newScope(parent)
+
+	// Named functions can always call themselves, so their names are declared in their own scope too
+	if function.Name != "" {
+		r.scope.symbols[function.Name] = &symbol{name: function.Name, kind: FUNCTION, lineNum: function.LineNum, used: true}
+	}
+
+	// Default values are evaluated in the function's scope, after the parameters before them are assigned
+	for _, parameter := range function.Parameters {
+		if parameter.Default != nil {
+			r.resolveExpression(parameter.Default)
+		}
+		r.declare(parameter.Name, PARAMETER)
+	}
+
+	r.resolveBlock(function.Body)
+	r.closeScope()
+	r.scope = parent
+}
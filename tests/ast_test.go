@@ -0,0 +1,195 @@
+package tests
+
+import (
+	"boomerang/ast"
+	"boomerang/node"
+	"boomerang/parser"
+	"boomerang/tokens"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var astSources = []string{
+	"1; 1.50; 0xff; true; false;",
+	"\"text\"; \"a {x} b {y:>5}\"; r\"{raw}\";",
+	"x = (1, 2, (3,)); (a, b) = x;",
+	"-1; not true; 1 + 2 * 3 - -4 / 5 % 6; 1 == 2 or 1 < 2 and 3 != 2;",
+	"print <- (\"hi\",); process; len <- (list @ 0,);",
+	"f = func(a, b = 2) {\n  return a + b;\n};\nf <- (1,);\nfunc() { 1; } <- ();",
+	"func named(a) {\n  a;\n};",
+	"when x {\n  is 1 { 1; }\n  is 2 { 2; }\n  else { 3; }\n};",
+	"when {\n  x < 1 { 1; }\n};\nwhen not {\n  x { 1; }\n};",
+	"for i in (1, 2) {\n  when {\n    i == 1 { continue; }\n  };\n  i;\n};",
+	"for (i, j) in ((1, 2),) { i + j; };",
+	"while true {\n  break;\n};",
+	"import \"geometry.bmg\" as g;\nexport func f() { g.circle_area <- (1,); };\nexport x;\nexport (a, b) = (1, 2);",
+	"func add(a: Number, b: List[Number] = (1,)) -> Monad[Number] {\n  total: Number = a;\n  return total;\n};\nf = func(x: String) { x; };\nexport y: Boolean = true;",
+	tokenizerBenchmarkBlock,
+}
+
+func getTypedAST(t *testing.T, source string) []ast.Statement {
+	p, err := parser.NewParser(tokens.NewTokenizer(source))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	statements, err := p.ParseTyped()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return statements
+}
+
+func TestAST_RoundTrip(t *testing.T) {
+	sources := append(append([]string{}, astSources...), getIntegrationSources(t)...)
+
+	for i, source := range sources {
+		expected := getParserAST(source)
+		actual := ast.ToNodes(getTypedAST(t, source))
+		AssertNodesEqual(t, i, expected, actual)
+	}
+}
+
+func TestAST_TypedNodes(t *testing.T) {
+	statements := getTypedAST(t, "f = func(a, b = 2) {\n  return a + b;\n};\nwhen f <- (1,) {\n  is 3 { \"{x:>5}!\"; }\n};")
+	if len(statements) != 2 {
+		t.Fatalf("Expected 2 statements, got %d", len(statements))
+	}
+
+	assignment, ok := statements[0].(*ast.Assignment)
+	if !ok {
+		t.Fatalf("Expected *ast.Assignment, got %T", statements[0])
+	}
+
+	function := assignment.Value.(*ast.Function)
+	if len(function.Parameters) != 2 || function.Parameters[0].Default != nil {
+		t.Fatalf("Expected parameters (a, b = 2), got %#v", function.Parameters)
+	}
+	if name := function.Parameters[1].Name.Name; name != "b" {
+		t.Fatalf("Expected parameter b, got %s", name)
+	}
+	if value := function.Parameters[1].Default.(*ast.Number).Value; value != "2" {
+		t.Fatalf("Expected default value 2, got %s", value)
+	}
+
+	returnStatement := function.Body.Statements[0].(*ast.Return)
+	if line := returnStatement.Line(); line != 2 {
+		t.Fatalf("Expected return statement on line 2, got line %d", line)
+	}
+
+	sum := returnStatement.Value.(*ast.BinaryExpression)
+	if sum.Operator.Type != tokens.PLUS || sum.Left.(*ast.Identifier).Name != "a" || sum.Right.(*ast.Identifier).Name != "b" {
+		t.Fatalf("Expected a + b, got %#v", sum)
+	}
+
+	when := statements[1].(*ast.When)
+	call := when.Value.(*ast.BinaryExpression)
+	if call.Operator.Type != tokens.SEND || call.Left.(*ast.Identifier).Name != "f" || len(call.Right.(*ast.List).Elements) != 1 {
+		t.Fatalf("Expected f <- (1,), got %#v", call)
+	}
+
+	parts := when.Cases[0].Body.Statements[0].(*ast.InterpolatedString).Parts
+	interpolation := parts[0].(*ast.Interpolation)
+	if interpolation.FormatSpec != ">5" || interpolation.Expression.(*ast.Identifier).Name != "x" {
+		t.Fatalf("Expected {x:>5}, got %#v", interpolation)
+	}
+	if text := parts[1].(*ast.String).Value; text != "!" {
+		t.Fatalf("Expected text \"!\", got %#v", text)
+	}
+	if len(when.Else.Statements) != 0 {
+		t.Fatalf("Expected empty else statements, got %d statements", len(when.Else.Statements))
+	}
+}
+
+// Records the nodes visited by "ast.Walk", with ")" for the end of each node's children
+type recordingVisitor struct {
+	visited *[]string
+}
+
+func (v recordingVisitor) Visit(n ast.Node) ast.Visitor {
+	if n == nil {
+		*v.visited = append(*v.visited, ")")
+		return nil
+	}
+
+	name := strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast.")
+	*v.visited = append(*v.visited, fmt.Sprintf("%s@%d", name, n.Line()))
+	return v
+}
+
+func TestAST_Walk(t *testing.T) {
+	statements := getTypedAST(t, "x = 1 + y;\nfor i in x {\n  print <- (i,);\n};")
+
+	visited := []string{}
+	for _, statement := range statements {
+		ast.Walk(recordingVisitor{visited: &visited}, statement)
+	}
+
+	expected := []string{
+		"Assignment@1", "Identifier@1", ")", "BinaryExpression@1", "Number@1", ")", "Identifier@1", ")", ")", ")",
+		"ForLoop@2", "Identifier@2", ")", "Identifier@2", ")",
+		"BlockStatements@0", "BinaryExpression@3", "BuiltinFunction@3", ")", "List@3", "Identifier@3", ")", ")", ")", ")", ")",
+	}
+	if !reflect.DeepEqual(expected, visited) {
+		t.Fatalf("Expected visited nodes: %v, actual: %v", expected, visited)
+	}
+}
+
+func TestAST_Inspect(t *testing.T) {
+	statements := getTypedAST(t, "f = func(n) { n + m; };\nf <- (k,);")
+
+	// Find identifiers outside of function bodies
+	identifiers := []string{}
+	for _, statement := range statements {
+		ast.Inspect(statement, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.Function:
+				return false
+			case *ast.Identifier:
+				identifiers = append(identifiers, n.Name)
+			}
+			return true
+		})
+	}
+
+	expected := []string{"f", "f", "k"}
+	if !reflect.DeepEqual(expected, identifiers) {
+		t.Fatalf("Expected identifiers: %v, actual: %v", expected, identifiers)
+	}
+}
+
+func TestAST_ConversionErrors(t *testing.T) {
+	tests := []struct {
+		Node  node.Node
+		Error string
+	}{
+		{
+			Node:  CreateMonad(CreateNumber("1").Ptr()),
+			Error: "cannot convert Monad to an AST node",
+		},
+		{
+			Node:  CreateBlockStatements([]node.Node{}),
+			Error: "block statements can only be converted as part of another node",
+		},
+		{
+			Node:  CreateList([]node.Node{CreateMap([]node.Node{})}),
+			Error: "cannot convert Map to an AST node",
+		},
+		{
+			Node:  node.CreateReturnStatement(TEST_LINE_NUM, CreateBlockStatements([]node.Node{})),
+			Error: "block statements can only be converted as part of another node",
+		},
+	}
+
+	for i, test := range tests {
+		_, err := ast.FromNodes([]node.Node{test.Node})
+		if err == nil {
+			t.Fatalf("Test #%d: expected error %#v", i, test.Error)
+		}
+		if err.Error() != test.Error {
+			t.Fatalf("Test #%d: expected error: %#v, actual error: %#v", i, test.Error, err.Error())
+		}
+	}
+}
@@ -1,11 +1,17 @@
 package tests
 
 import (
+	"boomerang/evaluator"
 	"boomerang/node"
 	"boomerang/tokens"
 	"boomerang/utils"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestBuiltin_Len(t *testing.T) {
@@ -30,6 +36,10 @@ func TestBuiltin_Len(t *testing.T) {
 			Sequence: CreateRawString("hello, world!"),
 			Length:   "13",
 		},
+		{
+			Sequence: CreateRawString("héllo, wörld!"),
+			Length:   "13",
+		},
 		{
 			Sequence: CreateRawString(""),
 			Length:   "0",
@@ -135,9 +145,9 @@ func TestBuiltin_UnwrapAll(t *testing.T) {
 	}{
 		{
 			BlockStatementReturnValues: CreateList([]node.Node{
-				CreateBlockStatementReturnValue(nil),
-				CreateBlockStatementReturnValue(nil),
-				CreateBlockStatementReturnValue(nil),
+				CreateMonadExpression(nil),
+				CreateMonadExpression(nil),
+				CreateMonadExpression(nil),
 			}),
 			ExpectedResult: CreateList([]node.Node{
 				CreateNumber("-1"),
@@ -147,9 +157,9 @@ func TestBuiltin_UnwrapAll(t *testing.T) {
 		},
 		{
 			BlockStatementReturnValues: CreateList([]node.Node{
-				CreateBlockStatementReturnValue(CreateNumber("5").Ptr()),
-				CreateBlockStatementReturnValue(CreateNumber("10").Ptr()),
-				CreateBlockStatementReturnValue(CreateNumber("15").Ptr()),
+				CreateMonadExpression(CreateNumber("5").Ptr()),
+				CreateMonadExpression(CreateNumber("10").Ptr()),
+				CreateMonadExpression(CreateNumber("15").Ptr()),
 			}),
 			ExpectedResult: CreateList([]node.Node{
 				CreateNumber("5"),
@@ -159,9 +169,9 @@ func TestBuiltin_UnwrapAll(t *testing.T) {
 		},
 		{
 			BlockStatementReturnValues: CreateList([]node.Node{
-				CreateBlockStatementReturnValue(CreateNumber("5").Ptr()),
-				CreateBlockStatementReturnValue(nil),
-				CreateBlockStatementReturnValue(CreateNumber("15").Ptr()),
+				CreateMonadExpression(CreateNumber("5").Ptr()),
+				CreateMonadExpression(nil),
+				CreateMonadExpression(CreateNumber("15").Ptr()),
 			}),
 			ExpectedResult: CreateList([]node.Node{
 				CreateNumber("5"),
@@ -221,6 +231,12 @@ func TestBuiltin_Slice(t *testing.T) {
 			EndIndex:      CreateNumber("4"),
 			ExpectedValue: CreateRawString("ello"),
 		},
+		{
+			Collection:    CreateRawString("日本語のテキスト"),
+			StartIndex:    CreateNumber("1"),
+			EndIndex:      CreateNumber("3"),
+			ExpectedValue: CreateRawString("本語の"),
+		},
 	}
 
 	for i, test := range tests {
@@ -355,7 +371,7 @@ func TestBuiltin_Random(t *testing.T) {
 		t.Run(testName, func(t *testing.T) {
 			ast := []node.Node{
 				CreateFunctionCall(
-					CreateBuiltinFunctionIdentifier("random"),
+					CreateBuiltinFunctionIdentifier("random.int"),
 					[]node.Node{
 						CreateNumber(utils.IntToString(test.Min)),
 						CreateNumber(utils.IntToString(test.Max)),
@@ -478,11 +494,11 @@ func TestBuiltin_IsSuccess(t *testing.T) {
 		ReturnValue node.Node
 	}{
 		{
-			Param:       CreateMonad(nil),
+			Param:       CreateMonadExpression(nil),
 			ReturnValue: CreateBooleanFalse(),
 		},
 		{
-			Param:       CreateMonad(CreateNumber("5").Ptr()),
+			Param:       CreateMonadExpression(CreateNumber("5").Ptr()),
 			ReturnValue: CreateBooleanTrue(),
 		},
 	}
@@ -693,7 +709,7 @@ func TestBuiltin_RandomErrors(t *testing.T) {
 
 		ast := []node.Node{
 			CreateFunctionCall(
-				CreateBuiltinFunctionIdentifier("random"),
+				CreateBuiltinFunctionIdentifier("random.int"),
 				test.Arguments,
 			),
 		}
@@ -998,3 +1014,1410 @@ func TestBuiltin_EnumerateErrors(t *testing.T) {
 		AssertErrorEqual(t, i, expectedError, actualError)
 	}
 }
+
+func TestBuiltin_Strings(t *testing.T) {
+
+	tests := []struct {
+		Source         string
+		ExpectedResult node.Node
+	}{
+		{
+			Source:         `"hello, " + "wörld";`,
+			ExpectedResult: CreateRawString("hello, wörld"),
+		},
+		{
+			Source: `strings.split <- ("a,b,c", ",");`,
+			ExpectedResult: CreateList([]node.Node{
+				CreateRawString("a"),
+				CreateRawString("b"),
+				CreateRawString("c"),
+			}),
+		},
+		{
+			Source: `strings.split <- ("héé", "");`,
+			ExpectedResult: CreateList([]node.Node{
+				CreateRawString("h"),
+				CreateRawString("é"),
+				CreateRawString("é"),
+			}),
+		},
+		{
+			Source:         `strings.join <- (("a", "b", "c"), "-");`,
+			ExpectedResult: CreateRawString("a-b-c"),
+		},
+		{
+			Source:         `strings.join <- ((), "-");`,
+			ExpectedResult: CreateRawString(""),
+		},
+		{
+			Source:         `strings.trim <- ("  hello world  ",);`,
+			ExpectedResult: CreateRawString("hello world"),
+		},
+		{
+			Source:         `strings.upper <- ("héllo",);`,
+			ExpectedResult: CreateRawString("HÉLLO"),
+		},
+		{
+			Source:         `strings.lower <- ("ÀÉÎ",);`,
+			ExpectedResult: CreateRawString("àéî"),
+		},
+		{
+			Source:         `strings.replace <- ("a-b-c", "-", "+");`,
+			ExpectedResult: CreateRawString("a+b+c"),
+		},
+		{
+			Source:         `strings.contains <- ("hello", "ell");`,
+			ExpectedResult: CreateBooleanTrue(),
+		},
+		{
+			Source:         `strings.contains <- ("hello", "world");`,
+			ExpectedResult: CreateBooleanFalse(),
+		},
+		{
+			Source:         `strings.starts_with <- ("hello", "he");`,
+			ExpectedResult: CreateBooleanTrue(),
+		},
+		{
+			Source:         `strings.ends_with <- ("hello", "he");`,
+			ExpectedResult: CreateBooleanFalse(),
+		},
+		{
+			Source:         `strings.index_of <- ("héllo", "l");`,
+			ExpectedResult: CreateMonad(CreateNumber("2").Ptr()),
+		},
+		{
+			Source:         `strings.index_of <- ("hello", "z");`,
+			ExpectedResult: CreateMonad(nil),
+		},
+		{
+			Source:         `strings.repeat <- ("ab", 3);`,
+			ExpectedResult: CreateRawString("ababab"),
+		},
+		{
+			Source:         `strings.pad_left <- ("é", 3, "0");`,
+			ExpectedResult: CreateRawString("00é"),
+		},
+		{
+			Source:         `strings.pad_right <- ("abc", 5, "★");`,
+			ExpectedResult: CreateRawString("abc★★"),
+		},
+		{
+			Source:         `strings.pad_right <- ("abcdef", 3, " ");`,
+			ExpectedResult: CreateRawString("abcdef"),
+		},
+		{
+			Source:         `strings.to_string <- (1.5,);`,
+			ExpectedResult: CreateRawString("1.5"),
+		},
+		{
+			Source:         `strings.to_string <- ((1, "a"),);`,
+			ExpectedResult: CreateRawString("(1, \"a\")"),
+		},
+		{
+			Source:         `strings.to_string <- ("a",);`,
+			ExpectedResult: CreateRawString("a"),
+		},
+		{
+			Source:         `strings.to_number <- (" 3.25 ",);`,
+			ExpectedResult: CreateMonad(CreateNumber("3.25").Ptr()),
+		},
+		{
+			Source:         `strings.to_number <- ("three",);`,
+			ExpectedResult: CreateMonad(nil),
+		},
+		{
+			// Names of string functions are not reserved
+			Source:         `join = strings.join; replace = "-"; join <- (("a", "b"), replace);`,
+			ExpectedResult: CreateRawString("a-b"),
+		},
+	}
+
+	for i, test := range tests {
+		actualResults := getEvaluatorResults(getParserAST(test.Source))
+		actualResult := actualResults[len(actualResults)-1]
+		AssertNodeEqual(t, i, test.ExpectedResult, actualResult)
+	}
+}
+
+func TestBuiltin_StringErrors(t *testing.T) {
+
+	tests := []struct {
+		Source string
+		Error  string
+	}{
+		{
+			Source: `strings.split <- ("a,b", 1);`,
+			Error:  "error at line 1: expected String, got Number (\"1\")",
+		},
+		{
+			Source: `strings.join <- (("a", 1), ",");`,
+			Error:  "error at line 1: expected String, got Number",
+		},
+		{
+			Source: `strings.repeat <- ("a", -1);`,
+			Error:  "error at line 1: repeat count must be a non-negative integer",
+		},
+		{
+			Source: `strings.repeat <- ("ab", 9007199254740992);`,
+			Error:  "error at line 1: repeat result cannot be longer than 10000000 characters",
+		},
+		{
+			Source: `strings.pad_left <- ("a", 9007199254740992, " ");`,
+			Error:  "error at line 1: pad width cannot be more than 10000000, got 9007199254740992",
+		},
+		{
+			Source: `strings.pad_left <- ("a", 3, "ab");`,
+			Error:  "error at line 1: pad character must be a single character, got \"ab\"",
+		},
+		{
+			Source: `strings.upper <- ("a", "b");`,
+			Error:  "error at line 1: incorrect number of arguments. expected 1, got 2",
+		},
+		{
+			Source: `strings = 1;`,
+			Error:  "error at line 1: invalid type for assignment: BuiltinVariable (\"strings\")",
+		},
+	}
+
+	for i, test := range tests {
+		actualError := getEvaluatorError(t, getParserAST(test.Source))
+		AssertErrorEqual(t, i, test.Error, actualError)
+	}
+}
+
+func TestBuiltin_Lists(t *testing.T) {
+
+	tests := []struct {
+		Source         string
+		ExpectedResult node.Node
+	}{
+		{
+			Source: `lists.map <- ((1, 2, 3), func(x) { return x * 2; });`,
+			ExpectedResult: CreateList([]node.Node{
+				CreateNumber("2"),
+				CreateNumber("4"),
+				CreateNumber("6"),
+			}),
+		},
+		{
+			Source: `lists.map <- (("a", "bc"), len);`,
+			ExpectedResult: CreateList([]node.Node{
+				CreateNumber("1"),
+				CreateNumber("2"),
+			}),
+		},
+		{
+			Source: `is_even = func(x) { return x % 2 == 0; }; lists.filter <- ((1, 2, 3, 4), is_even);`,
+			ExpectedResult: CreateList([]node.Node{
+				CreateNumber("2"),
+				CreateNumber("4"),
+			}),
+		},
+		{
+			Source:         `lists.reduce <- ((1, 2, 3, 4), func(a, b) { return a + b; });`,
+			ExpectedResult: CreateNumber("10"),
+		},
+		{
+			Source:         `lists.fold <- (("a", "b"), func(a, b) { return a + b; }, ">");`,
+			ExpectedResult: CreateRawString(">ab"),
+		},
+		{
+			Source:         `lists.fold <- ((), func(a, b) { return a + b; }, 0);`,
+			ExpectedResult: CreateNumber("0"),
+		},
+		{
+			Source: `lists.sort <- ((3, 1, 2, 1.5),);`,
+			ExpectedResult: CreateList([]node.Node{
+				CreateNumber("1"),
+				CreateNumber("1.5"),
+				CreateNumber("2"),
+				CreateNumber("3"),
+			}),
+		},
+		{
+			// Sorting is stable, so "bb" stays before "aa"
+			Source: `lists.sort <- (("ccc", "bb", "a", "aa"), len);`,
+			ExpectedResult: CreateList([]node.Node{
+				CreateRawString("a"),
+				CreateRawString("bb"),
+				CreateRawString("aa"),
+				CreateRawString("ccc"),
+			}),
+		},
+		{
+			Source: `lists.sort <- ((true, false),);`,
+			ExpectedResult: CreateList([]node.Node{
+				CreateBooleanFalse(),
+				CreateBooleanTrue(),
+			}),
+		},
+		{
+			Source: `lists.reverse <- ((1, 2, 3),);`,
+			ExpectedResult: CreateList([]node.Node{
+				CreateNumber("3"),
+				CreateNumber("2"),
+				CreateNumber("1"),
+			}),
+		},
+		{
+			Source:         `lists.reverse <- ("héllo",);`,
+			ExpectedResult: CreateRawString("olléh"),
+		},
+		{
+			Source: `lists.zip <- ((1, 2, 3), ("a", "b"));`,
+			ExpectedResult: CreateList([]node.Node{
+				CreateList([]node.Node{CreateNumber("1"), CreateRawString("a")}),
+				CreateList([]node.Node{CreateNumber("2"), CreateRawString("b")}),
+			}),
+		},
+		{
+			Source: `lists.flatten <- (((1, 2), 3, ((4,),)),);`,
+			ExpectedResult: CreateList([]node.Node{
+				CreateNumber("1"),
+				CreateNumber("2"),
+				CreateNumber("3"),
+				CreateList([]node.Node{CreateNumber("4")}),
+			}),
+		},
+		{
+			Source: `lists.unique <- ((1, 2, 1, "1", (1,), (1,)),);`,
+			ExpectedResult: CreateList([]node.Node{
+				CreateNumber("1"),
+				CreateNumber("2"),
+				CreateRawString("1"),
+				CreateList([]node.Node{CreateNumber("1")}),
+			}),
+		},
+		{
+			Source:         `lists.any <- ((false, true),);`,
+			ExpectedResult: CreateBooleanTrue(),
+		},
+		{
+			Source:         `lists.any <- ((),);`,
+			ExpectedResult: CreateBooleanFalse(),
+		},
+		{
+			Source:         `lists.all <- ((),);`,
+			ExpectedResult: CreateBooleanTrue(),
+		},
+		{
+			Source:         `lists.all <- ((2, 4, 5), func(x) { return x % 2 == 0; });`,
+			ExpectedResult: CreateBooleanFalse(),
+		},
+		{
+			// "any" stops at the first match, so the function is not called for "0"
+			Source:         `lists.any <- ((1, 0), func(x) { return 1 / x == 1; });`,
+			ExpectedResult: CreateBooleanTrue(),
+		},
+		{
+			Source:         `lists.sum <- ((1, 2, 3.5),);`,
+			ExpectedResult: CreateNumber("6.5"),
+		},
+		{
+			Source:         `lists.sum <- ((),);`,
+			ExpectedResult: CreateNumber("0"),
+		},
+		{
+			Source:         `lists.min <- ((3, 1, 2),);`,
+			ExpectedResult: CreateNumber("1"),
+		},
+		{
+			Source:         `lists.max <- (("a", "c", "b"),);`,
+			ExpectedResult: CreateRawString("c"),
+		},
+		{
+			Source:         `lists.max <- (("aa", "b", "cc"), len);`,
+			ExpectedResult: CreateRawString("aa"),
+		},
+		{
+			Source:         `lists.find <- ((1, 2, 3), func(x) { return 1 < x; });`,
+			ExpectedResult: CreateMonad(CreateNumber("2").Ptr()),
+		},
+		{
+			Source:         `lists.find <- ((1, 2, 3), func(x) { return x == 5; });`,
+			ExpectedResult: CreateMonad(nil),
+		},
+		{
+			Source: `lists.group_by <- ((1, 2, 3, 4), func(x) { return x % 2 == 0; });`,
+			ExpectedResult: CreateList([]node.Node{
+				CreateList([]node.Node{
+					CreateBooleanFalse(),
+					CreateList([]node.Node{CreateNumber("1"), CreateNumber("3")}),
+				}),
+				CreateList([]node.Node{
+					CreateBooleanTrue(),
+					CreateList([]node.Node{CreateNumber("2"), CreateNumber("4")}),
+				}),
+			}),
+		},
+		{
+			// Names of list functions are not reserved
+			Source:         `sum = lists.sum; find = 2; sum <- ((find, 3),);`,
+			ExpectedResult: CreateNumber("5"),
+		},
+	}
+
+	for i, test := range tests {
+		actualResults := getEvaluatorResults(getParserAST(test.Source))
+		actualResult := actualResults[len(actualResults)-1]
+		AssertNodeEqual(t, i, test.ExpectedResult, actualResult)
+	}
+}
+
+func TestBuiltin_ListErrors(t *testing.T) {
+
+	tests := []struct {
+		Source string
+		Error  string
+	}{
+		{
+			Source: `lists.map <- ((1, 2), 5);`,
+			Error:  "error at line 1: expected Function or BuiltinFunction, got Number",
+		},
+		{
+			Source: `lists.map <- ((1, 2), func(x) {});`,
+			Error:  "error at line 1: function passed to \"lists.map\" did not return a value",
+		},
+		{
+			Source: `lists.filter <- ((1, 2), func(x) { return x; });`,
+			Error:  "error at line 1: expected Boolean, got Number",
+		},
+		{
+			Source: `lists.reduce <- ((), func(a, b) { return a + b; });`,
+			Error:  "error at line 1: cannot reduce an empty list",
+		},
+		{
+			Source: `lists.sort <- ((1, "a"),);`,
+			Error:  "error at line 1: cannot compare types String and Number",
+		},
+		{
+			Source: `lists.sort <- (((1,), (2,)),);`,
+			Error:  "error at line 1: cannot compare values of type List",
+		},
+		{
+			Source: `lists.sort <- ();`,
+			Error:  "error at line 1: incorrect number of arguments. expected 1 to 2, got 0",
+		},
+		{
+			Source: `lists.zip <- ((1, 2),);`,
+			Error:  "error at line 1: incorrect number of arguments. expected at least 2, got 1",
+		},
+		{
+			Source: `lists.sum <- ((1, "2"),);`,
+			Error:  "error at line 1: expected Number, got String",
+		},
+		{
+			Source: `lists.min <- ((),);`,
+			Error:  "error at line 1: lists.min requires a non-empty list",
+		},
+		{
+			Source: `lists.all <- ((true, 1),);`,
+			Error:  "error at line 1: expected Boolean, got Number",
+		},
+		{
+			Source: `lists.reverse <- (1,);`,
+			Error:  "error at line 1: expected List or String, got Number",
+		},
+		{
+			Source: `lists = 1;`,
+			Error:  "error at line 1: invalid type for assignment: BuiltinVariable (\"lists\")",
+		},
+	}
+
+	for i, test := range tests {
+		actualError := getEvaluatorError(t, getParserAST(test.Source))
+		AssertErrorEqual(t, i, test.Error, actualError)
+	}
+}
+
+func TestBuiltin_Math(t *testing.T) {
+
+	tests := []struct {
+		Source         string
+		ExpectedResult node.Node
+	}{
+		{Source: `math.sqrt <- (16,);`, ExpectedResult: CreateNumber("4")},
+		{Source: `math.pow <- (2, 10);`, ExpectedResult: CreateNumber("1024")},
+		{Source: `math.abs <- (-2.5,);`, ExpectedResult: CreateNumber("2.5")},
+		{Source: `math.floor <- (-2.5,);`, ExpectedResult: CreateNumber("-3")},
+		{Source: `math.ceil <- (2.1,);`, ExpectedResult: CreateNumber("3")},
+		{Source: `math.round <- (2.5,);`, ExpectedResult: CreateNumber("3")},
+		{Source: `math.round <- (3.14159, 2);`, ExpectedResult: CreateNumber("3.14")},
+		{Source: `math.round <- (1234, -2);`, ExpectedResult: CreateNumber("1200")},
+		{Source: `math.exp <- (0,);`, ExpectedResult: CreateNumber("1")},
+		{Source: `math.log <- (math.e,);`, ExpectedResult: CreateNumber("1")},
+		{Source: `math.log <- (8, 2);`, ExpectedResult: CreateNumber("3")},
+		{Source: `math.log10 <- (1000,);`, ExpectedResult: CreateNumber("3")},
+		{Source: `math.log2 <- (0.5,);`, ExpectedResult: CreateNumber("-1")},
+		{Source: `math.sin <- (0,);`, ExpectedResult: CreateNumber("0")},
+		{Source: `math.cos <- (0,);`, ExpectedResult: CreateNumber("1")},
+		{Source: `math.tan <- (0,);`, ExpectedResult: CreateNumber("0")},
+		{Source: `math.asin <- (1,) == math.pi / 2;`, ExpectedResult: CreateBooleanTrue()},
+		{Source: `math.acos <- (1,);`, ExpectedResult: CreateNumber("0")},
+		{Source: `math.atan <- (1,) == math.pi / 4;`, ExpectedResult: CreateBooleanTrue()},
+		{Source: `math.atan2 <- (1, 0) == math.pi / 2;`, ExpectedResult: CreateBooleanTrue()},
+		{Source: `math.min <- (3, -1, 2);`, ExpectedResult: CreateNumber("-1")},
+		{Source: `math.max <- (3, -1, 2);`, ExpectedResult: CreateNumber("3")},
+		{Source: `math.clamp <- (15, 0, 10);`, ExpectedResult: CreateNumber("10")},
+		{Source: `math.clamp <- (-5, 0, 10);`, ExpectedResult: CreateNumber("0")},
+		{Source: `math.gcd <- (12, -18);`, ExpectedResult: CreateNumber("6")},
+		{Source: `math.lcm <- (4, 6);`, ExpectedResult: CreateNumber("12")},
+		{Source: `math.lcm <- (0, 6);`, ExpectedResult: CreateNumber("0")},
+		{Source: `math.is_integer <- (4,);`, ExpectedResult: CreateBooleanTrue()},
+		{Source: `math.is_integer <- (4.5,);`, ExpectedResult: CreateBooleanFalse()},
+		{Source: `math.is_integer <- (math.inf,);`, ExpectedResult: CreateBooleanFalse()},
+		{Source: `math.is_nan <- (math.nan,);`, ExpectedResult: CreateBooleanTrue()},
+		{Source: `math.is_inf <- (-(math.inf),);`, ExpectedResult: CreateBooleanTrue()},
+		{Source: `math.pi == pi;`, ExpectedResult: CreateBooleanTrue()},
+		{Source: `math.e;`, ExpectedResult: CreateNumber("2.718281828459045")},
+		{
+			// Math functions can be passed to other builtins
+			Source:         `lists.map <- ((1.2, 2.7), math.round);`,
+			ExpectedResult: CreateList([]node.Node{CreateNumber("1"), CreateNumber("3")}),
+		},
+		{
+			// Names of math functions and constants are not reserved
+			Source:         `e = 1; floor = math.floor; floor <- (e + 0.5,);`,
+			ExpectedResult: CreateNumber("1"),
+		},
+		{
+			Source:         `math;`,
+			ExpectedResult: CreateRawString("<module math>"),
+		},
+	}
+
+	for i, test := range tests {
+		actualResults := getEvaluatorResults(getParserAST(test.Source))
+		actualResult := actualResults[len(actualResults)-1]
+
+		// The math module is compared by its string representation so every member doesn't need to be listed
+		if actualResult.Type == node.MODULE {
+			actualResult = CreateRawString(actualResult.String())
+		}
+		AssertNodeEqual(t, i, test.ExpectedResult, actualResult)
+	}
+}
+
+func TestBuiltin_MathErrors(t *testing.T) {
+
+	tests := []struct {
+		Source string
+		Error  string
+	}{
+		{
+			Source: `math = 1;`,
+			Error:  "error at line 1: invalid type for assignment: BuiltinVariable (\"math\")",
+		},
+		{
+			Source: `math.cube <- (2,);`,
+			Error:  "error at line 1: module math has no exported member \"cube\"",
+		},
+		{
+			Source: `math.sqrt <- (-1,);`,
+			Error:  "error at line 1: cannot take the square root of a negative number (-1)",
+		},
+		{
+			Source: `math.log <- (0,);`,
+			Error:  "error at line 1: math.log is only defined for positive numbers, got 0",
+		},
+		{
+			Source: `math.log <- (8, 1);`,
+			Error:  "error at line 1: logarithm base cannot be 1",
+		},
+		{
+			Source: `math.log10 <- (-1,);`,
+			Error:  "error at line 1: math.log10 is only defined for positive numbers, got -1",
+		},
+		{
+			Source: `math.asin <- (2,);`,
+			Error:  "error at line 1: math.asin is only defined from -1 to 1, got 2",
+		},
+		{
+			Source: `math.round <- (1.5, 0.5);`,
+			Error:  "error at line 1: expected an integer, got 0.5",
+		},
+		{
+			Source: `math.round <- (1.5, 400);`,
+			Error:  "error at line 1: math.round cannot round 1.5 to 400 digits",
+		},
+		{
+			Source: `math.round <- (1.5, -400);`,
+			Error:  "error at line 1: math.round cannot round 1.5 to -400 digits",
+		},
+		{
+			Source: `math.gcd <- (1.5, 2);`,
+			Error:  "error at line 1: expected an integer, got 1.5",
+		},
+		{
+			Source: `math.lcm <- (4503599627370496, 3);`,
+			Error:  "error at line 1: math.lcm of 4503599627370496 and 3 is too large",
+		},
+		{
+			Source: `math.lcm <- (9223372036854775807, 2);`,
+			Error:  "error at line 1: expected an integer, got 9223372036854776000",
+		},
+		{
+			Source: `math.clamp <- (1, 10, 0);`,
+			Error:  "error at line 1: clamp lower bound (10) is greater than upper bound (0)",
+		},
+		{
+			Source: `math.min <- ();`,
+			Error:  "error at line 1: math.min requires at least one number",
+		},
+		{
+			Source: `math.sqrt <- (1, 2);`,
+			Error:  "error at line 1: incorrect number of arguments. expected 1, got 2",
+		},
+	}
+
+	for i, test := range tests {
+		actualError := getEvaluatorError(t, getParserAST(test.Source))
+		AssertErrorEqual(t, i, test.Error, actualError)
+	}
+}
+
+func TestBuiltin_RandomSeed(t *testing.T) {
+	// Evaluators with the same seed produce the same random values
+	source := `
+	random.int <- (0, 1000);
+	random.float <- ();
+	random.choice <- ((1, 2, 3, 4, 5),);
+	random.shuffle <- ((1, 2, 3, 4, 5),);
+	random.sample <- ((1, 2, 3, 4, 5), 3);
+	random.normal <- (0, 1);
+	random.exponential <- (1,);
+	`
+
+	firstResults := getSeededEvaluatorResults(getParserAST(source), 42)
+	secondResults := getSeededEvaluatorResults(getParserAST(source), 42)
+	AssertNodesEqual(t, 0, firstResults, secondResults)
+}
+
+func TestBuiltin_RandomFunctions(t *testing.T) {
+
+	tests := []struct {
+		Source         string
+		ExpectedResult node.Node
+	}{
+		{Source: `x = random.float <- (); ((0 < x) or (x == 0)) and (x < 1);`, ExpectedResult: CreateBooleanTrue()},
+		{Source: `random.choice <- ((7,),);`, ExpectedResult: CreateNumber("7")},
+		{Source: `c = random.choice <- ((1, 2, 3),); ((c == 1) or (c == 2)) or (c == 3);`, ExpectedResult: CreateBooleanTrue()},
+		{
+			// Shuffling returns a permutation of the list
+			Source:         `lists.sort <- (random.shuffle <- ((3, 1, 5, 2, 4),),);`,
+			ExpectedResult: CreateList([]node.Node{CreateNumber("1"), CreateNumber("2"), CreateNumber("3"), CreateNumber("4"), CreateNumber("5")}),
+		},
+		{
+			// The original list is not changed
+			Source:         `numbers = (1, 2, 3); shuffled = random.shuffle <- (numbers,); numbers;`,
+			ExpectedResult: CreateList([]node.Node{CreateNumber("1"), CreateNumber("2"), CreateNumber("3")}),
+		},
+		{Source: `random.shuffle <- ((),);`, ExpectedResult: CreateList([]node.Node{})},
+		{
+			// Samples are chosen without replacement
+			Source:         `s = random.sample <- ((1, 2, 3, 4, 5), 5); lists.sort <- (s,);`,
+			ExpectedResult: CreateList([]node.Node{CreateNumber("1"), CreateNumber("2"), CreateNumber("3"), CreateNumber("4"), CreateNumber("5")}),
+		},
+		{Source: `len <- (lists.unique <- (random.sample <- ((1, 2, 3, 4, 5), 3),),);`, ExpectedResult: CreateNumber("3")},
+		{Source: `random.sample <- ((1, 2, 3), 0);`, ExpectedResult: CreateList([]node.Node{})},
+		{Source: `random.normal <- (5, 0);`, ExpectedResult: CreateNumber("5")},
+		{Source: `x = random.exponential <- (0.5,); (0 < x) or (x == 0);`, ExpectedResult: CreateBooleanTrue()},
+		{
+			// Names of random functions are not reserved
+			Source:         `choice = random.choice; sample = (4,); choice <- (sample,);`,
+			ExpectedResult: CreateNumber("4"),
+		},
+	}
+
+	for i, test := range tests {
+		actualResults := getEvaluatorResults(getParserAST(test.Source))
+		AssertNodeEqual(t, i, test.ExpectedResult, actualResults[len(actualResults)-1])
+	}
+}
+
+func TestBuiltin_RandomFunctionErrors(t *testing.T) {
+
+	tests := []struct {
+		Source string
+		Error  string
+	}{
+		{
+			Source: `random.choice <- ((),);`,
+			Error:  "error at line 1: cannot choose from an empty list",
+		},
+		{
+			Source: `random.choice <- ("abc",);`,
+			Error:  "error at line 1: expected List, got String (\"abc\")",
+		},
+		{
+			Source: `random.shuffle <- (1,);`,
+			Error:  "error at line 1: expected List, got Number (\"1\")",
+		},
+		{
+			Source: `random.sample <- ((1, 2, 3), 4);`,
+			Error:  "error at line 1: sample size must be between 0 and the length of the list (3), got 4",
+		},
+		{
+			Source: `random.sample <- ((1, 2, 3), -1);`,
+			Error:  "error at line 1: sample size must be between 0 and the length of the list (3), got -1",
+		},
+		{
+			Source: `random.sample <- ((1, 2, 3), 1.5);`,
+			Error:  "error at line 1: expected an integer, got 1.5",
+		},
+		{
+			Source: `random.normal <- (0, -1);`,
+			Error:  "error at line 1: standard deviation cannot be negative, got -1",
+		},
+		{
+			Source: `random.exponential <- (0,);`,
+			Error:  "error at line 1: rate must be greater than 0, got 0",
+		},
+		{
+			Source: `random.float <- (1,);`,
+			Error:  "error at line 1: incorrect number of arguments. expected 0, got 1",
+		},
+		{
+			Source: `random = 1;`,
+			Error:  "error at line 1: invalid type for assignment: BuiltinVariable (\"random\")",
+		},
+	}
+
+	for i, test := range tests {
+		actualError := getEvaluatorError(t, getParserAST(test.Source))
+		AssertErrorEqual(t, i, test.Error, actualError)
+	}
+}
+
+func TestBuiltin_JSON(t *testing.T) {
+
+	tests := []struct {
+		Source         string
+		ExpectedResult node.Node
+	}{
+		{
+			Source: `json_parse <- (r"""{"name": "app", "port": 8080, "debug": false, "tags": ["a", "b"], "extra": null}""",);`,
+			ExpectedResult: CreateMap([]node.Node{
+				CreateMapEntry("name", CreateRawString("app")),
+				CreateMapEntry("port", CreateNumber("8080")),
+				CreateMapEntry("debug", CreateBooleanFalse()),
+				CreateMapEntry("tags", CreateList([]node.Node{CreateRawString("a"), CreateRawString("b")})),
+				CreateMapEntry("extra", CreateMonad(nil)),
+			}),
+		},
+		{Source: `json_parse <- ("[1.5, -2e3, true]",);`, ExpectedResult: CreateList([]node.Node{CreateNumber("1.5"), CreateNumber("-2000"), CreateBooleanTrue()})},
+		{Source: `json_parse <- (r""" "café\n" """,);`, ExpectedResult: CreateRawString("café\n")},
+		{Source: `json_parse <- ("null",);`, ExpectedResult: CreateMonad(nil)},
+		{
+			// If a key appears more than once, the last value is used
+			Source:         `json_parse <- (r"""{"a": 1, "b": 2, "a": 3}""",);`,
+			ExpectedResult: CreateMap([]node.Node{CreateMapEntry("a", CreateNumber("3")), CreateMapEntry("b", CreateNumber("2"))}),
+		},
+		{Source: `json_stringify <- ((1, "two", true, json_parse <- ("null",)),);`, ExpectedResult: CreateRawString(`[1,"two",true,null]`)},
+		{Source: `json_stringify <- ("<a & b> \"quoted\"\n",);`, ExpectedResult: CreateRawString(`"<a & b> \"quoted\"\n"`)},
+		{Source: `json_stringify <- ((1, (2, 3)), 2);`, ExpectedResult: CreateRawString("[\n  1,\n  [\n    2,\n    3\n  ]\n]")},
+		{Source: `json_stringify <- ((), 2);`, ExpectedResult: CreateRawString("[]")},
+		{
+			// A monad with a value is converted to its value
+			Source:         `json_stringify <- (map_get <- (json_parse <- (r"""{"a": 1}""",), "a"),);`,
+			ExpectedResult: CreateRawString("1"),
+		},
+		{
+			// Keys keep their original order
+			Source:         `s = r"""{"b":1,"a":[{"c":null}]}"""; json_stringify <- (json_parse <- (s,),) == s;`,
+			ExpectedResult: CreateBooleanTrue(),
+		},
+		{Source: `json_stringify <- (json_parse <- (r"""{"a": {"b": []}}""",), 1);`, ExpectedResult: CreateRawString("{\n \"a\": {\n  \"b\": []\n }\n}")},
+	}
+
+	for i, test := range tests {
+		actualResults := getEvaluatorResults(getParserAST(test.Source))
+		AssertNodeEqual(t, i, test.ExpectedResult, actualResults[len(actualResults)-1])
+	}
+}
+
+func TestBuiltin_Maps(t *testing.T) {
+
+	tests := []struct {
+		Source         string
+		ExpectedResult node.Node
+	}{
+		{Source: `config @ "port";`, ExpectedResult: CreateNumber("8080")},
+		{Source: `len <- (config,);`, ExpectedResult: CreateNumber("2")},
+		{Source: `map_get <- (config, "name");`, ExpectedResult: CreateMonad(CreateRawString("app").Ptr())},
+		{Source: `map_get <- (config, "missing");`, ExpectedResult: CreateMonad(nil)},
+		{Source: `map_keys <- (config,);`, ExpectedResult: CreateList([]node.Node{CreateRawString("name"), CreateRawString("port")})},
+		{Source: `map_values <- (config,);`, ExpectedResult: CreateList([]node.Node{CreateRawString("app"), CreateNumber("8080")})},
+		{
+			Source:         `map_set <- (config, "port", 9090);`,
+			ExpectedResult: CreateMap([]node.Node{CreateMapEntry("name", CreateRawString("app")), CreateMapEntry("port", CreateNumber("9090"))}),
+		},
+		{
+			Source: `map_set <- (config, "debug", true);`,
+			ExpectedResult: CreateMap([]node.Node{
+				CreateMapEntry("name", CreateRawString("app")),
+				CreateMapEntry("port", CreateNumber("8080")),
+				CreateMapEntry("debug", CreateBooleanTrue()),
+			}),
+		},
+		{
+			// "map_set" does not change the original map
+			Source:         `updated = map_set <- (config, "port", 1); config @ "port";`,
+			ExpectedResult: CreateNumber("8080"),
+		},
+		{
+			// Maps are equal if they have the same entries in any order
+			Source:         `config == json_parse <- (r"""{"port": 8080, "name": "app"}""",);`,
+			ExpectedResult: CreateBooleanTrue(),
+		},
+		{Source: `config == map_set <- (config, "port", 1);`, ExpectedResult: CreateBooleanFalse()},
+		{Source: `config;`, ExpectedResult: CreateRawString(`{"name": "app", "port": 8080}`)},
+	}
+
+	for i, test := range tests {
+		source := `config = json_parse <- (r"""{"name": "app", "port": 8080}""",); ` + test.Source
+		actualResults := getEvaluatorResults(getParserAST(source))
+		actualResult := actualResults[len(actualResults)-1]
+
+		// Maps are compared by their string representation when testing how they are displayed
+		if test.ExpectedResult.Type == node.STRING {
+			actualResult = CreateRawString(actualResult.String())
+		}
+		AssertNodeEqual(t, i, test.ExpectedResult, actualResult)
+	}
+}
+
+func TestBuiltin_JSONErrors(t *testing.T) {
+
+	tests := []struct {
+		Source string
+		Error  string
+	}{
+		{
+			Source: `json_parse <- (r"""{"a": }""",);`,
+			Error:  "error at line 1: invalid JSON at offset 7: missing value after object key",
+		},
+		{
+			Source: `json_parse <- ("[1, 2",);`,
+			Error:  "error at line 1: invalid JSON at offset 5: unexpected end of JSON input",
+		},
+		{
+			Source: `json_parse <- ("",);`,
+			Error:  "error at line 1: invalid JSON at offset 0: unexpected end of JSON input",
+		},
+		{
+			Source: `json_parse <- ("1  2",);`,
+			Error:  "error at line 1: invalid JSON at offset 3: unexpected data after JSON value",
+		},
+		{
+			Source: `json_parse <- ("[1,]",);`,
+			Error:  "error at line 1: invalid JSON at offset 3: invalid character ',' looking for beginning of value",
+		},
+		{
+			Source: `json_parse <- (r"""{1: 2}""",);`,
+			Error:  "error at line 1: invalid JSON at offset 2: object member name must be a string",
+		},
+		{
+			Source: `json_parse <- (1,);`,
+			Error:  "error at line 1: expected String, got Number (\"1\")",
+		},
+		{
+			Source: `json_stringify <- (math.inf,);`,
+			Error:  "error at line 1: cannot convert Number (\"+Inf\") to JSON",
+		},
+		{
+			Source: `json_stringify <- ((1, print),);`,
+			Error:  "error at line 1: cannot convert BuiltinFunction (\"print\") to JSON",
+		},
+		{
+			Source: `json_stringify <- ((), -1);`,
+			Error:  "error at line 1: indent must be between 0 and 16, got -1",
+		},
+		{
+			Source: `json_stringify <- ((1,), 9007199254740992);`,
+			Error:  "error at line 1: indent must be between 0 and 16, got 9007199254740992",
+		},
+		{
+			Source: `json_stringify <- ();`,
+			Error:  "error at line 1: incorrect number of arguments. expected 1 to 2, got 0",
+		},
+		{
+			Source: `m = json_parse <- ("\{}",); m @ "a";`,
+			Error:  "error at line 1: key \"a\" not found in map",
+		},
+		{
+			Source: `m = json_parse <- ("\{}",); m @ 0;`,
+			Error:  "error at line 1: invalid types for index: Map (\"\") and Number (\"0\")",
+		},
+		{
+			Source: `map_get <- ((1, 2), "a");`,
+			Error:  "error at line 1: expected Map, got List (\"\")",
+		},
+		{
+			Source: `m = json_parse <- ("\{}",); map_set <- (m, 1, 2);`,
+			Error:  "error at line 1: expected String, got Number (\"1\")",
+		},
+	}
+
+	for i, test := range tests {
+		actualError := getEvaluatorError(t, getParserAST(test.Source))
+		AssertErrorEqual(t, i, test.Error, actualError)
+	}
+}
+
+func TestBuiltin_Files(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "data.txt"), []byte("one\ntwo\nthree\n"), 0644); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := os.Mkdir(filepath.Join(root, "logs"), 0755); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// Lines can be longer than the default buffer size of "bufio.Scanner" (64KB)
+	if err := os.WriteFile(filepath.Join(root, "logs", "long.txt"), []byte(strings.Repeat("a", 100_000)+"\n"), 0644); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tests := []struct {
+		Source         string
+		ExpectedResult node.Node
+	}{
+		{Source: `read_file <- ("data.txt",);`, ExpectedResult: CreateMonad(CreateRawString("one\ntwo\nthree\n").Ptr())},
+		{Source: `read_file <- ("missing.txt",);`, ExpectedResult: CreateMonad(nil)},
+		{Source: `read_file <- ("logs",);`, ExpectedResult: CreateMonad(nil)},
+		{Source: `read_lines <- ("data.txt", func(line) { return true; });`, ExpectedResult: CreateMonad(CreateNumber("3").Ptr())},
+		{
+			// Reading stops when the function returns false
+			Source:         `read_lines <- ("data.txt", func(line) { return line != "two"; });`,
+			ExpectedResult: CreateMonad(CreateNumber("2").Ptr()),
+		},
+		{Source: `read_lines <- ("missing.txt", func(line) { return true; });`, ExpectedResult: CreateMonad(nil)},
+		{Source: `read_lines <- ("logs", func(line) { return true; });`, ExpectedResult: CreateMonad(nil)},
+		{Source: `read_lines <- ("logs/long.txt", func(line) { return true; });`, ExpectedResult: CreateMonad(CreateNumber("1").Ptr())},
+		{Source: `write_file <- ("logs/new.txt", "héllo");`, ExpectedResult: CreateMonad(CreateNumber("6").Ptr())},
+		{
+			Source:         `write_file <- ("out.txt", "a"); write_file <- ("out.txt", "b"); read_file <- ("out.txt",);`,
+			ExpectedResult: CreateMonad(CreateRawString("b").Ptr()),
+		},
+		{
+			Source:         `append_file <- ("log.txt", "a\n"); append_file <- ("log.txt", "b\n"); read_file <- ("log.txt",);`,
+			ExpectedResult: CreateMonad(CreateRawString("a\nb\n").Ptr()),
+		},
+		{Source: `write_file <- ("missing/new.txt", "a");`, ExpectedResult: CreateMonad(nil)},
+		{
+			Source: `write_file <- ("b.txt", ""); write_file <- ("a.txt", ""); list_dir <- (".",);`,
+			ExpectedResult: CreateMonad(CreateList([]node.Node{
+				CreateRawString("a.txt"),
+				CreateRawString("b.txt"),
+				CreateRawString("data.txt"),
+				CreateRawString("log.txt"),
+				CreateRawString("logs"),
+				CreateRawString("out.txt"),
+			}).Ptr()),
+		},
+		{Source: `list_dir <- ("data.txt",);`, ExpectedResult: CreateMonad(nil)},
+		{Source: `file_exists <- ("data.txt",);`, ExpectedResult: CreateBooleanTrue()},
+		{Source: `file_exists <- ("logs",);`, ExpectedResult: CreateBooleanTrue()},
+		{Source: `file_exists <- ("missing.txt",);`, ExpectedResult: CreateBooleanFalse()},
+		{
+			Source:         `write_file <- ("temp.txt", "a"); remove_file <- ("temp.txt",); file_exists <- ("temp.txt",);`,
+			ExpectedResult: CreateBooleanFalse(),
+		},
+		{Source: `write_file <- ("temp.txt", "a"); remove_file <- ("temp.txt",);`, ExpectedResult: CreateMonad(CreateBooleanTrue().Ptr())},
+		{Source: `remove_file <- ("missing.txt",);`, ExpectedResult: CreateMonad(nil)},
+		{Source: `remove_file <- ("logs",);`, ExpectedResult: CreateMonad(nil)},
+		{
+			// Absolute paths in the root directory are allowed
+			Source:         fmt.Sprintf(`file_exists <- (%#v,);`, filepath.Join(root, "data.txt")),
+			ExpectedResult: CreateBooleanTrue(),
+		},
+		{Source: `file_exists <- ("logs/../data.txt",);`, ExpectedResult: CreateBooleanTrue()},
+	}
+
+	for i, test := range tests {
+		actualResults, err := evaluateWithFileRoot(t, getParserAST(test.Source), root)
+		if err != nil {
+			t.Fatalf("Test #%d: %s", i, err.Error())
+		}
+		AssertNodeEqual(t, i, test.ExpectedResult, actualResults[len(actualResults)-1])
+	}
+}
+
+func TestBuiltin_FileErrors(t *testing.T) {
+	directory := t.TempDir()
+	root := filepath.Join(directory, "root")
+	if err := os.Mkdir(root, 0755); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := os.WriteFile(filepath.Join(directory, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// Symbolic links cannot be used to leave the root directory
+	if err := os.Symlink(directory, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// Writing to a link whose target does not exist would create the target outside the root directory
+	if err := os.Symlink(filepath.Join(directory, "created.txt"), filepath.Join(root, "dangling")); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tests := []struct {
+		Source string
+		Error  string
+	}{
+		{
+			Source: `read_file <- ("../secret.txt",);`,
+			Error:  "error at line 1: path \"../secret.txt\" is outside the allowed directory",
+		},
+		{
+			Source: fmt.Sprintf(`read_file <- (%#v,);`, filepath.Join(directory, "secret.txt")),
+			Error:  fmt.Sprintf("error at line 1: path %#v is outside the allowed directory", filepath.Join(directory, "secret.txt")),
+		},
+		{
+			Source: `read_file <- ("link/secret.txt",);`,
+			Error:  "error at line 1: path \"link/secret.txt\" is outside the allowed directory",
+		},
+		{
+			Source: `write_file <- ("link/new.txt", "a");`,
+			Error:  "error at line 1: path \"link/new.txt\" is outside the allowed directory",
+		},
+		{
+			Source: `write_file <- ("dangling", "a");`,
+			Error:  "error at line 1: path \"dangling\" is outside the allowed directory",
+		},
+		{
+			Source: `append_file <- ("dangling", "a");`,
+			Error:  "error at line 1: path \"dangling\" is outside the allowed directory",
+		},
+		{
+			Source: `remove_file <- ("link/secret.txt",);`,
+			Error:  "error at line 1: path \"link/secret.txt\" is outside the allowed directory",
+		},
+		{
+			Source: `list_dir <- ("..",);`,
+			Error:  "error at line 1: path \"..\" is outside the allowed directory",
+		},
+		{
+			Source: `read_file <- (1,);`,
+			Error:  "error at line 1: expected String, got Number (\"1\")",
+		},
+		{
+			Source: `write_file <- ("a.txt", 1);`,
+			Error:  "error at line 1: expected String, got Number (\"1\")",
+		},
+		{
+			Source: `read_lines <- ("a.txt",);`,
+			Error:  "error at line 1: incorrect number of arguments. expected 2, got 1",
+		},
+		{
+			Source: `read_lines <- ("a.txt", 1);`,
+			Error:  "error at line 1: expected Function or BuiltinFunction, got Number",
+		},
+		{
+			Source: `write_file <- ("a.txt", "a"); read_lines <- ("a.txt", func(line) { return 1; });`,
+			Error:  "error at line 1: expected Boolean, got Number",
+		},
+	}
+
+	for i, test := range tests {
+		_, err := evaluateWithFileRoot(t, getParserAST(test.Source), root)
+		if err == nil {
+			t.Fatalf("Test #%d: error is nil", i)
+		}
+		AssertErrorEqual(t, i, test.Error, err.Error())
+	}
+
+	if _, err := os.Lstat(filepath.Join(directory, "created.txt")); err == nil {
+		t.Fatal("Expected the target of the dangling link to not be created")
+	}
+}
+
+func TestBuiltin_ReadLinesOutput(t *testing.T) {
+	// Lines are passed to the function without their line endings, and can be longer than the default buffer size of
+	// "bufio.Scanner" (64KB)
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "data.txt"), []byte("one\r\n"+strings.Repeat("a", 100_000)+"\nthree"), 0644); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	source := `read_lines <- ("data.txt", func(line) { print <- (len <- (line,),); return true; });`
+	AssertExpectedOutput(t, 0, "3\n100000\n5\n", func() {
+		if _, err := evaluateWithFileRoot(t, getParserAST(source), root); err != nil {
+			t.Fatal(err.Error())
+		}
+	})
+}
+
+func TestBuiltin_RemoveFileLinks(t *testing.T) {
+	// Removing a symbolic link removes the link instead of its target, even if the target is outside the root directory
+	directory := t.TempDir()
+	root := filepath.Join(directory, "root")
+	if err := os.Mkdir(root, 0755); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	targets := []string{filepath.Join(root, "target.txt"), filepath.Join(directory, "outside.txt")}
+	links := []string{"link.txt", "outside_link.txt"}
+	for i, target := range targets {
+		if err := os.WriteFile(target, []byte("a"), 0644); err != nil {
+			t.Fatal(err.Error())
+		}
+		if err := os.Symlink(target, filepath.Join(root, links[i])); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	for i, link := range links {
+		actualResults, err := evaluateWithFileRoot(t, getParserAST(fmt.Sprintf(`remove_file <- (%#v,);`, link)), root)
+		if err != nil {
+			t.Fatalf("Test #%d: %s", i, err.Error())
+		}
+		AssertNodeEqual(t, i, CreateMonad(CreateBooleanTrue().Ptr()), actualResults[0])
+
+		if _, err := os.Lstat(filepath.Join(root, link)); err == nil {
+			t.Fatalf("Test #%d: expected the link to be removed", i)
+		}
+		if _, err := os.Stat(targets[i]); err != nil {
+			t.Fatalf("Test #%d: expected the target of the link to not be removed", i)
+		}
+	}
+}
+
+func TestBuiltin_FilesDisabled(t *testing.T) {
+	// Filesystem access is disabled if no root directory is set
+	actualError := getEvaluatorError(t, getParserAST(`file_exists <- ("data.txt",);`))
+	AssertErrorEqual(t, 0, "error at line 1: filesystem access is disabled", actualError)
+}
+
+func TestBuiltin_Process(t *testing.T) {
+	t.Setenv("BOOMERANG_TEST_VALUE", "hello")
+
+	tests := []struct {
+		Source         string
+		ExpectedResult node.Node
+	}{
+		{Source: `process.args;`, ExpectedResult: CreateList([]node.Node{CreateRawString("input.txt"), CreateRawString("--verbose")})},
+		{Source: `process.args @ 0;`, ExpectedResult: CreateRawString("input.txt")},
+		{Source: `process.env_get <- ("BOOMERANG_TEST_VALUE",);`, ExpectedResult: CreateMonad(CreateRawString("hello").Ptr())},
+		{Source: `process.env_get <- ("BOOMERANG_TEST_MISSING",);`, ExpectedResult: CreateMonad(nil)},
+		{Source: `process.env_set <- ("BOOMERANG_TEST_VALUE", "world");`, ExpectedResult: CreateRawString("world")},
+		{
+			Source:         `process.env_set <- ("BOOMERANG_TEST_VALUE", "world"); process.env_get <- ("BOOMERANG_TEST_VALUE",);`,
+			ExpectedResult: CreateMonad(CreateRawString("world").Ptr()),
+		},
+		{
+			// Names of process functions are not reserved
+			Source:         `args = process.args; exit = 1; len <- (args,) + exit;`,
+			ExpectedResult: CreateNumber("3"),
+		},
+	}
+
+	for i, test := range tests {
+		evaluatorObj := evaluator.NewEvaluator(getTypedAST(t, test.Source))
+		evaluatorObj.SetArgs([]string{"input.txt", "--verbose"})
+
+		actualResults, err := evaluatorObj.Evaluate()
+		if err != nil {
+			t.Fatalf("Test #%d: %s", i, err.Error())
+		}
+		AssertNodeEqual(t, i, test.ExpectedResult, actualResults[len(actualResults)-1])
+	}
+}
+
+func TestBuiltin_ArgsNotSet(t *testing.T) {
+	actualResults := getEvaluatorResults(getParserAST(`process.args;`))
+	AssertNodeEqual(t, 0, CreateList([]node.Node{}), actualResults[0])
+}
+
+func TestBuiltin_Exit(t *testing.T) {
+
+	tests := []struct {
+		Source   string
+		ExitCode int
+	}{
+		{Source: `process.exit <- ();`, ExitCode: 0},
+		{Source: `process.exit <- (3,); process.exit <- (4,);`, ExitCode: 3},
+		{
+			// "exit" stops the program from inside functions and loops
+			Source: `
+			func check(n) {
+				when {
+					n == 2 { process.exit <- (n,); }
+				};
+				return n;
+			};
+			for i in range <- (0, 5) { check <- (i,); };
+			`,
+			ExitCode: 2,
+		},
+	}
+
+	for i, test := range tests {
+		evaluatorObj := evaluator.NewEvaluator(getTypedAST(t, test.Source))
+		_, err := evaluatorObj.Evaluate()
+
+		var exitError *evaluator.ExitError
+		if !errors.As(err, &exitError) {
+			t.Fatalf("Test #%d: expected an exit error, got %v", i, err)
+		}
+		if exitError.Code != test.ExitCode {
+			t.Fatalf("Test #%d: expected exit code %d, got %d", i, test.ExitCode, exitError.Code)
+		}
+	}
+}
+
+func TestBuiltin_ProcessErrors(t *testing.T) {
+
+	tests := []struct {
+		Source string
+		Error  string
+	}{
+		{
+			Source: `process.exit <- (256,);`,
+			Error:  "error at line 1: exit code must be between 0 and 255, got 256",
+		},
+		{
+			Source: `process.exit <- (1.5,);`,
+			Error:  "error at line 1: expected an integer, got 1.5",
+		},
+		{
+			Source: `process.exit <- (1, 2);`,
+			Error:  "error at line 1: incorrect number of arguments. expected 0 to 1, got 2",
+		},
+		{
+			Source: `process.env_get <- (1,);`,
+			Error:  "error at line 1: expected String, got Number (\"1\")",
+		},
+		{
+			Source: `process.env_set <- ("", "value");`,
+			Error:  "error at line 1: cannot set environment variable \"\"",
+		},
+		{
+			Source: `process = (1,);`,
+			Error:  "error at line 1: invalid type for assignment: BuiltinVariable (\"process\")",
+		},
+	}
+
+	for i, test := range tests {
+		actualError := getEvaluatorError(t, getParserAST(test.Source))
+		AssertErrorEqual(t, i, test.Error, actualError)
+	}
+}
+
+func TestBuiltin_Time(t *testing.T) {
+
+	tests := []struct {
+		Source         string
+		ExpectedResult node.Node
+	}{
+		{Source: `time.now <- ();`, ExpectedResult: CreateNumber("1700000000.5")},
+		{Source: `time.format <- (time.now <- (),);`, ExpectedResult: CreateRawString("2023-11-14T22:13:20.5Z")},
+		{Source: `time.monotonic <- ();`, ExpectedResult: CreateNumber("0")},
+		{Source: `time.sleep <- (1.5,); time.monotonic <- ();`, ExpectedResult: CreateNumber("1.5")},
+		{Source: `time.sleep <- (60,); time.format <- (time.now <- (),);`, ExpectedResult: CreateRawString("2023-11-14T22:14:20.5Z")},
+		{Source: `time.format <- (0,);`, ExpectedResult: CreateRawString("1970-01-01T00:00:00Z")},
+		{Source: `time.format <- (-1.25,);`, ExpectedResult: CreateRawString("1969-12-31T23:59:58.75Z")},
+		{Source: `time.parse <- ("2024-01-31T12:30:00Z",);`, ExpectedResult: CreateMonad(CreateNumber("1706704200").Ptr())},
+		{Source: `time.parse <- ("2024-01-31T14:30:00.25+02:00",);`, ExpectedResult: CreateMonad(CreateNumber("1706704200.25").Ptr())},
+		{Source: `time.parse <- ("2024-01-31T12:30:00",);`, ExpectedResult: CreateMonad(CreateNumber("1706704200").Ptr())},
+		{Source: `time.parse <- ("2024-01-31",);`, ExpectedResult: CreateMonad(CreateNumber("1706659200").Ptr())},
+		{Source: `time.parse <- ("2024-02-30",);`, ExpectedResult: CreateMonad(nil)},
+		{Source: `time.parse <- ("yesterday",);`, ExpectedResult: CreateMonad(nil)},
+		{Source: `time.parse_duration <- ("1h30m",);`, ExpectedResult: CreateMonad(CreateNumber("5400").Ptr())},
+		{Source: `time.parse_duration <- ("250ms",);`, ExpectedResult: CreateMonad(CreateNumber("0.25").Ptr())},
+		{Source: `time.parse_duration <- ("soon",);`, ExpectedResult: CreateMonad(nil)},
+		{Source: `time.format_duration <- (5400.5,);`, ExpectedResult: CreateRawString("1h30m0.5s")},
+		{
+			// Timestamps are equal to the same number written as a literal
+			Source:         `(unwrap <- (time.parse <- ("2024-01-31T12:30:00Z",), 0)) == 1706704200;`,
+			ExpectedResult: CreateBooleanTrue(),
+		},
+		{
+			// Days that do not exist are normalized
+			Source:         `t = unwrap <- (time.parse <- ("2024-01-31",), 0); time.format <- (time.add_date <- (t, 0, 1, 0),);`,
+			ExpectedResult: CreateRawString("2024-03-02T00:00:00Z"),
+		},
+		{
+			Source:         `t = unwrap <- (time.parse <- ("2024-01-31",), 0); time.format <- (time.add_date <- (t, -1, 0, 1),);`,
+			ExpectedResult: CreateRawString("2023-02-01T00:00:00Z"),
+		},
+		{
+			Source: `time.parts <- (time.now <- (),);`,
+			ExpectedResult: CreateMap([]node.Node{
+				CreateMapEntry("year", CreateNumber("2023")),
+				CreateMapEntry("month", CreateNumber("11")),
+				CreateMapEntry("day", CreateNumber("14")),
+				CreateMapEntry("hour", CreateNumber("22")),
+				CreateMapEntry("minute", CreateNumber("13")),
+				CreateMapEntry("second", CreateNumber("20.5")),
+				CreateMapEntry("weekday", CreateNumber("2")),
+				CreateMapEntry("day_of_year", CreateNumber("318")),
+			}),
+		},
+		{
+			// Timestamps are numbers, so they can be used with arithmetic operators
+			Source:         `time.format <- (time.now <- () + 86400,);`,
+			ExpectedResult: CreateRawString("2023-11-15T22:13:20.5Z"),
+		},
+	}
+
+	for i, test := range tests {
+		clock := &fakeClock{now: time.Unix(1700000000, 500000000)}
+		actualResults := evaluateWithClock(t, getParserAST(test.Source), clock)
+		AssertNodeEqual(t, i, test.ExpectedResult, actualResults[len(actualResults)-1])
+	}
+}
+
+func TestBuiltin_TimeErrors(t *testing.T) {
+
+	tests := []struct {
+		Source string
+		Error  string
+	}{
+		{
+			Source: `time.sleep <- (-1,);`,
+			Error:  "error at line 1: cannot sleep for a negative duration (-1)",
+		},
+		{
+			Source: `time.sleep <- (1e10,);`,
+			Error:  "error at line 1: time.sleep duration is out of range (about 292 years), got 1e+10 seconds",
+		},
+		{
+			Source: `time.format_duration <- (-(math.inf),);`,
+			Error:  "error at line 1: time.format_duration duration is out of range (about 292 years), got -Inf seconds",
+		},
+		{
+			Source: `time.format <- ("2024-01-01",);`,
+			Error:  "error at line 1: expected Number, got String (\"2024-01-01\")",
+		},
+		{
+			Source: `time.parse <- (0,);`,
+			Error:  "error at line 1: expected String, got Number (\"0\")",
+		},
+		{
+			Source: `time.add_date <- (0, 1.5, 0, 0);`,
+			Error:  "error at line 1: expected an integer, got 1.5",
+		},
+		{
+			Source: `time.now <- (1,);`,
+			Error:  "error at line 1: incorrect number of arguments. expected 0, got 1",
+		},
+		{
+			Source: `time = 1;`,
+			Error:  "error at line 1: invalid type for assignment: BuiltinVariable (\"time\")",
+		},
+	}
+
+	for i, test := range tests {
+		actualError := getEvaluatorError(t, getParserAST(test.Source))
+		AssertErrorEqual(t, i, test.Error, actualError)
+	}
+}
+
+func TestBuiltin_Regex(t *testing.T) {
+
+	tests := []struct {
+		Source         string
+		ExpectedResult node.Node
+	}{
+		{Source: `regex_match <- ("order-123", r"\d+");`, ExpectedResult: CreateBooleanTrue()},
+		{Source: `regex_match <- ("order-123", r"^\d+$");`, ExpectedResult: CreateBooleanFalse()},
+		{
+			Source: `regex_groups <- ("contact: me@example.com", r"(\w+)@([\w.]+)");`,
+			ExpectedResult: CreateMonad(CreateList([]node.Node{
+				CreateRawString("me@example.com"),
+				CreateRawString("me"),
+				CreateRawString("example.com"),
+			}).Ptr()),
+		},
+		{
+			// Groups that did not match are empty strings
+			Source:         `regex_groups <- ("ab", r"a(x)?(b)");`,
+			ExpectedResult: CreateMonad(CreateList([]node.Node{CreateRawString("ab"), CreateRawString(""), CreateRawString("b")}).Ptr()),
+		},
+		{Source: `regex_groups <- ("abc", r"\d");`, ExpectedResult: CreateMonad(nil)},
+		{
+			Source:         `regex_find_all <- ("a1 b22 c333", r"\d+");`,
+			ExpectedResult: CreateList([]node.Node{CreateRawString("1"), CreateRawString("22"), CreateRawString("333")}),
+		},
+		{
+			Source: `regex_find_all <- ("a=1, b=2", r"(\w)=(\d)");`,
+			ExpectedResult: CreateList([]node.Node{
+				CreateList([]node.Node{CreateRawString("a=1"), CreateRawString("a"), CreateRawString("1")}),
+				CreateList([]node.Node{CreateRawString("b=2"), CreateRawString("b"), CreateRawString("2")}),
+			}),
+		},
+		{Source: `regex_find_all <- ("abc", r"\d");`, ExpectedResult: CreateList([]node.Node{})},
+		{Source: `regex_replace <- ("a1b22", r"\d+", "#");`, ExpectedResult: CreateRawString("a#b#")},
+		{
+			Source:         `regex_replace <- ("2024-01-31", r"(\d+)-(\d+)-(\d+)", "$3/$2/$1");`,
+			ExpectedResult: CreateRawString("31/01/2024"),
+		},
+		{
+			Source:         `regex_replace <- ("John Smith", r"(?P<first>\w+) (?P<last>\w+)", r"${last}, ${first}");`,
+			ExpectedResult: CreateRawString("Smith, John"),
+		},
+		{Source: `regex_replace <- ("hello world", r"\w+", strings.upper);`, ExpectedResult: CreateRawString("HELLO WORLD")},
+		{
+			// Functions get the whole match and its groups when the pattern has groups
+			Source:         `regex_replace <- ("a=1, b=2", r"(\w)=(\d)", func(m) { return "{m @ 2}={m @ 1}"; });`,
+			ExpectedResult: CreateRawString("1=a, 2=b"),
+		},
+		{
+			Source:         `regex_split <- ("a, b;c  d", r"[,;\s]+");`,
+			ExpectedResult: CreateList([]node.Node{CreateRawString("a"), CreateRawString("b"), CreateRawString("c"), CreateRawString("d")}),
+		},
+		{
+			// Compiled patterns are reused
+			Source:         `lists.map <- (("a1", "b", "c3"), func(s) { return regex_match <- (s, r"\d"); });`,
+			ExpectedResult: CreateList([]node.Node{CreateBooleanTrue(), CreateBooleanFalse(), CreateBooleanTrue()}),
+		},
+		{
+			// Patterns removed from the cache when it is full are compiled again
+			Source:         `for i in range <- (0, 300) { regex_match <- ("x{i}", "^x{i}$"); }; regex_match <- ("x0", "^x0$");`,
+			ExpectedResult: CreateBooleanTrue(),
+		},
+	}
+
+	for i, test := range tests {
+		actualResults := getEvaluatorResults(getParserAST(test.Source))
+		AssertNodeEqual(t, i, test.ExpectedResult, actualResults[len(actualResults)-1])
+	}
+}
+
+func TestBuiltin_RegexErrors(t *testing.T) {
+
+	tests := []struct {
+		Source string
+		Error  string
+	}{
+		{
+			Source: `regex_match <- ("abc", "(");`,
+			Error:  "error at line 1: invalid regular expression \"(\": error parsing regexp: missing closing ): `(`",
+		},
+		{
+			Source: `regex_match <- (1, "a");`,
+			Error:  "error at line 1: expected String, got Number (\"1\")",
+		},
+		{
+			Source: `regex_replace <- ("abc", "b", 1);`,
+			Error:  "error at line 1: expected String, Function or BuiltinFunction, got Number",
+		},
+		{
+			Source: `regex_replace <- ("abc", "b", func(m) { return 1; });`,
+			Error:  "error at line 1: expected String, got Number",
+		},
+		{
+			Source: `regex_replace <- ("abc", "b", func(m) { m; });`,
+			Error:  "error at line 1: function passed to \"regex_replace\" did not return a value",
+		},
+	}
+
+	for i, test := range tests {
+		actualError := getEvaluatorError(t, getParserAST(test.Source))
+		AssertErrorEqual(t, i, test.Error, actualError)
+	}
+}
@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"boomerang/cst"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var cstSources = []string{
+	"",
+	"   \n# only a comment",
+	"x = 1;",
+	"x   =  1 ;  # set x\r\n\r\n## block\ncomment ##  y = x+2;\n",
+	"print <- (\"a { x :>5 } b {y}\" ,) ;",
+	"s = r\"\"\"raw\n{not interpolated}\"\"\";\nt = \"\"\"multi\n{ s }\nline\"\"\";",
+	"f = func(a, b = 2) {\n    # comment in a block\n    return a + b;  ## trailing ##\n};\n\nf <- (1,);",
+	"when {\n  x < 1 { 1; }\n  else { 2; }\n};\nfor i in (1, 2) { print <- (i,); };",
+	"import \"geometry.bmg\" as g; export g;\nwhile true { break; };\t\n",
+	"values = (0xff, 1_000, .5e2, -(3), not true);\nm = g.square <- (2,) @ 0;",
+	"func f(a : Number, b: List[ String ] = (\"x\",)) -> Monad[Number] { a; };\ntotal:Number = 1;",
+	tokenizerBenchmarkBlock,
+}
+
+func TestCST_RoundTrip(t *testing.T) {
+	sources := append(append([]string{}, cstSources...), getIntegrationSources(t)...)
+
+	for i, source := range sources {
+		tree, err := cst.Parse(source)
+		if err != nil {
+			t.Fatalf("Test #%d: %s", i, err.Error())
+		}
+
+		if actual := tree.Text(); source != actual {
+			t.Fatalf("Test #%d: expected text: %#v, actual text: %#v", i, source, actual)
+		}
+	}
+}
+
+func TestCST_Lower(t *testing.T) {
+	for i, source := range cstSources {
+		tree, err := cst.Parse(source)
+		if err != nil {
+			t.Fatalf("Test #%d: %s", i, err.Error())
+		}
+
+		actualAST, err := cst.Lower(tree)
+		if err != nil {
+			t.Fatalf("Test #%d: %s", i, err.Error())
+		}
+
+		AssertNodesEqual(t, i, getParserAST(source), actualAST)
+	}
+}
+
+func TestCST_Trivia(t *testing.T) {
+	source := "x = 1; # one\n\n## two ##\ny;"
+	tree, err := cst.Parse(source)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	allTokens := tree.Tokens()
+	semicolon, y := allTokens[3], allTokens[4]
+
+	expectedTrailing := []cst.Trivia{
+		{Kind: cst.WHITESPACE, Text: " "},
+		{Kind: cst.INLINE_COMMENT, Text: "# one"},
+	}
+	assertTriviaEqual(t, expectedTrailing, semicolon.Trailing)
+
+	expectedLeading := []cst.Trivia{
+		{Kind: cst.NEWLINE, Text: "\n"},
+		{Kind: cst.NEWLINE, Text: "\n"},
+		{Kind: cst.BLOCK_COMMENT, Text: "## two ##"},
+		{Kind: cst.NEWLINE, Text: "\n"},
+	}
+	assertTriviaEqual(t, expectedLeading, y.Leading)
+}
+
+func TestCST_Structure(t *testing.T) {
+	tests := []struct {
+		Source   string
+		Expected string
+	}{
+		{
+			Source:   "x = (1 + 2) * 3;",
+			Expected: `Program(Statement(Assign(Identifier("x") "=" BinaryExpression(GroupedExpression("(" BinaryExpression(Number("1") "+" Number("2")) ")") "*" Number("3"))) ";") "")`,
+		},
+		{
+			Source:   "f = func(a) { return a; };",
+			Expected: `Program(Statement(Assign(Identifier("f") "=" Function("func" "(" "a" ")" "{" BlockStatements(Statement(Return("return" Identifier("a")) ";") "}"))) ";") "")`,
+		},
+		{
+			Source:   `"a{x:>2}";`,
+			Expected: `Program(Statement(String("\"a{" Identifier("x") ":>2" "}\"") ";") "")`,
+		},
+		{
+			Source:   "x: List[Number] = (1,);",
+			Expected: `Program(Statement(Assign(Identifier("x") ":" TypeAnnotation("List" "[" TypeAnnotation("Number") "]") "=" List("(" Number("1") "," ")")) ";") "")`,
+		},
+	}
+
+	for i, test := range tests {
+		tree, err := cst.Parse(test.Source)
+		if err != nil {
+			t.Fatalf("Test #%d: %s", i, err.Error())
+		}
+
+		if actual := cstStructure(tree); test.Expected != actual {
+			t.Fatalf("Test #%d: expected: %s, actual: %s", i, test.Expected, actual)
+		}
+	}
+}
+
+func TestCST_Errors(t *testing.T) {
+	sources := []string{
+		"x = ;",
+		"x = 1",
+		"x = \"unfinished",
+	}
+
+	for i, source := range sources {
+		_, err := cst.Parse(source)
+		if err == nil {
+			t.Fatalf("Test #%d: an error was expected, but no errors occurred", i)
+		}
+
+		expectedError := getParserError(t, source)
+		if expectedError != err.Error() {
+			t.Fatalf("Test #%d: expected error: %#v, actual error: %#v", i, expectedError, err.Error())
+		}
+	}
+}
+
+func cstStructure(n *cst.Node) string {
+	// Display a tree with the text of its tokens (without trivia) and the kinds of its other nodes
+	if n.Token != nil {
+		return fmt.Sprintf("%#v", n.Token.Text)
+	}
+
+	children := []string{}
+	for _, child := range n.Children {
+		children = append(children, cstStructure(child))
+	}
+	return fmt.Sprintf("%s(%s)", n.Kind, strings.Join(children, " "))
+}
+
+func assertTriviaEqual(t *testing.T, expected []cst.Trivia, actual []cst.Trivia) {
+	if len(expected) != len(actual) {
+		t.Fatalf("Expected trivia: %#v, actual trivia: %#v", expected, actual)
+	}
+
+	for i := range expected {
+		if expected[i] != actual[i] {
+			t.Fatalf("Expected trivia: %#v, actual trivia: %#v", expected, actual)
+		}
+	}
+}
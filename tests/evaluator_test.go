@@ -30,6 +30,26 @@ func TestEvaluator_Numbers(t *testing.T) {
 	}
 }
 
+func TestEvaluator_EqualNumberLiterals(t *testing.T) {
+	// Number literals written in different ways are equal if they are the same number
+	sources := []string{
+		"0xff == 255;",
+		"1e2 == 100.0;",
+		"09 == 9;",
+		"1.50 == 1.5;",
+		"1_000 == 500 * 2;",
+		".5 == 1 / 2;",
+		// Large whole numbers can be used as integers
+		"1_000_000 % 7 == 1;",
+		"(range <- (1e6, 1e6 + 2)) @ 1 == 1_000_001;",
+	}
+
+	for i, source := range sources {
+		actualResults := getEvaluatorResults(getParserAST(source))
+		AssertNodeEqual(t, i, node.CreateBooleanTrue(1), actualResults[0])
+	}
+}
+
 func TestEvaluator_Booleans(t *testing.T) {
 
 	booleans := []string{
@@ -103,63 +123,85 @@ func TestEvaluator_Bang(t *testing.T) {
 func TestEvaluator_Strings(t *testing.T) {
 
 	tests := []struct {
-		InputSource  string
+		Parts        []node.Node
 		OutputSource string
-		Params       []node.Node
 	}{
 		{
-			InputSource:  "hello, world!",
+			Parts: []node.Node{
+				CreateRawString("hello, world!"),
+			},
 			OutputSource: "hello, world!",
-			Params:       []node.Node{},
 		},
 		{
-			InputSource:  "the time is <0>:<1>",
-			OutputSource: "the time is 12:45",
-			Params: []node.Node{
-				CreateNumber("12"),
-				CreateNumber("45"),
+			Parts: []node.Node{
+				CreateRawString("the time is "),
+				CreateInterpolation(CreateNumber("12"), ""),
+				CreateRawString(":"),
+				CreateInterpolation(CreateNumber("45"), ""),
 			},
+			OutputSource: "the time is 12:45",
 		},
 		{
-			InputSource:  "the result is <0>",
-			OutputSource: "the result is 13",
-			Params: []node.Node{
-				node.CreateBinaryExpression(
-					CreateNumber("7"),
-					tokens.PLUS_TOKEN,
-					CreateNumber("6"),
+			Parts: []node.Node{
+				CreateRawString("the result is "),
+				CreateInterpolation(
+					node.CreateBinaryExpression(
+						CreateNumber("7"),
+						tokens.PLUS_TOKEN,
+						CreateNumber("6"),
+					),
+					"",
 				),
 			},
+			OutputSource: "the result is 13",
 		},
 		{
-			InputSource:  "Hello, my name is <0>, and I am <1> years old!",
-			OutputSource: "Hello, my name is John, and I am 5 years old!",
-			Params: []node.Node{
-				CreateRawString("John"),
-				node.CreateBinaryExpression(
-					CreateNumber("3"),
-					tokens.PLUS_TOKEN,
-					CreateNumber("2"),
+			Parts: []node.Node{
+				CreateRawString("Hello, my name is "),
+				CreateInterpolation(CreateRawString("John"), ""),
+				CreateRawString(", and I am "),
+				CreateInterpolation(
+					node.CreateBinaryExpression(
+						CreateNumber("3"),
+						tokens.PLUS_TOKEN,
+						CreateNumber("2"),
+					),
+					"",
 				),
+				CreateRawString(" years old!"),
 			},
+			OutputSource: "Hello, my name is John, and I am 5 years old!",
 		},
 		{
-			InputSource:  "My numbers are <0>!",
+			Parts: []node.Node{
+				CreateRawString("My numbers are "),
+				CreateInterpolation(
+					CreateList([]node.Node{
+						CreateNumber("1"),
+						CreateNumber("2"),
+						CreateNumber("3"),
+						CreateNumber("4"),
+					}),
+					"",
+				),
+				CreateRawString("!"),
+			},
 			OutputSource: "My numbers are (1, 2, 3, 4)!",
-			Params: []node.Node{
-				CreateList([]node.Node{
-					CreateNumber("1"),
-					CreateNumber("2"),
-					CreateNumber("3"),
-					CreateNumber("4"),
-				}),
+		},
+		{
+			// Text that looks like an interpolation placeholder is left as-is
+			Parts: []node.Node{
+				CreateRawString("<1> <0> "),
+				CreateInterpolation(CreateRawString("<1>"), ""),
+				CreateInterpolation(CreateNumber("5"), ""),
 			},
+			OutputSource: "<1> <0> <1>5",
 		},
 	}
 
 	for i, test := range tests {
 		ast := []node.Node{
-			CreateString(test.InputSource, test.Params),
+			CreateInterpolatedString(test.Parts),
 		}
 
 		actualResults := getEvaluatorResults(ast)
@@ -170,6 +212,81 @@ func TestEvaluator_Strings(t *testing.T) {
 	}
 }
 
+func TestEvaluator_StringInterpolation(t *testing.T) {
+	tests := []struct {
+		Source string
+		Output string
+	}{
+		{Source: `x = 3.14159; "{x:.2f}";`, Output: "3.14"},
+		{Source: `x = 3.14159; "{x:.0f}";`, Output: "3"},
+		{Source: `"{1500:e}";`, Output: "1.500000e+03"},
+		{Source: `"{0.256:.1%}";`, Output: "25.6%"},
+		{Source: `"{42:d}";`, Output: "42"},
+		{Source: `"{1000 * 1000:d}";`, Output: "1000000"},
+		{Source: `n = 42; "[{n:>8}]";`, Output: "[      42]"},
+		{Source: `n = 42; "[{n:8}]";`, Output: "[      42]"},
+		{Source: `"[{"ab":8}]";`, Output: "[ab      ]"},
+		{Source: `"[{"ab":*^7}]";`, Output: "[**ab***]"},
+		{Source: `"[{"héllo":_<7}]";`, Output: "[héllo__]"},
+		{Source: `"[{2.5:0>8.2f}]";`, Output: "[00002.50]"},
+		{Source: `"[{"too long":3}]";`, Output: "[too long]"},
+		{Source: `"{func() { return 5; } <- ()}";`, Output: "Monad{5}"},
+		{Source: `name = "Jo"; "{"<{name}>" + "!"}";`, Output: "<Jo>!"},
+		{Source: `"{(1, 2)} <0> {3}";`, Output: "(1, 2) <0> 3"},
+	}
+
+	for i, test := range tests {
+		actualResults := getEvaluatorResults(getParserAST(test.Source))
+		actualResult := actualResults[len(actualResults)-1]
+		AssertNodeEqual(t, i, CreateRawString(test.Output), actualResult)
+	}
+}
+
+func TestEvaluator_StringInterpolationErrors(t *testing.T) {
+	tests := []struct {
+		Source string
+		Error  string
+	}{
+		{
+			Source: `"{1:q}";`,
+			Error:  "error at line 1: invalid format specifier \"q\"",
+		},
+		{
+			Source: `"{"abc":.2f}";`,
+			Error:  "error at line 1: format specifier \".2f\" can only be used with type Number, got String",
+		},
+		{
+			Source: `"{1.5:d}";`,
+			Error:  "error at line 1: format type \"d\" requires an integer, got 1.5",
+		},
+		{
+			Source: `"{1:99999999999999999999}";`,
+			Error:  "error at line 1: format width cannot be greater than 10000, got 99999999999999999999",
+		},
+		{
+			Source: `"{1:1000000000}";`,
+			Error:  "error at line 1: format width cannot be greater than 10000, got 1000000000",
+		},
+		{
+			Source: `"{1:.1000000000f}";`,
+			Error:  "error at line 1: format precision cannot be greater than 100, got 1000000000",
+		},
+		{
+			Source: `"{1:.99999999999999999999e}";`,
+			Error:  "error at line 1: format precision cannot be greater than 100, got 99999999999999999999",
+		},
+		{
+			Source: "x = 1;\n\"\"\"first line\n{y}\"\"\";",
+			Error:  "error at line 3: undefined identifier: y",
+		},
+	}
+
+	for i, test := range tests {
+		actualError := getEvaluatorError(t, getParserAST(test.Source))
+		AssertErrorEqual(t, i, test.Error, actualError)
+	}
+}
+
 func TestEvaluator_Parameters(t *testing.T) {
 
 	tests := []struct {
@@ -607,6 +724,96 @@ func TestEvaluator_Function(t *testing.T) {
 	AssertNodesEqual(t, 0, ast, actualResults)
 }
 
+func TestEvaluator_NamedFunction(t *testing.T) {
+
+	tests := []struct {
+		Source         string
+		ExpectedResult node.Node
+	}{
+		{
+			Source: `
+			func factorial(n) {
+				when n {
+					is 0 { return 1; }
+				};
+				return n * (unwrap <- (factorial <- (n - 1,), 0));
+			};
+			unwrap <- (factorial <- (5,), 0);`,
+			ExpectedResult: node.CreateNumber(8, "120"),
+		},
+		{
+			// "is_even" calls "is_odd" before "is_odd" is declared
+			Source: `
+			func is_even(n) {
+				when n {
+					is 0 { return true; }
+				};
+				return unwrap <- (is_odd <- (n - 1,), false);
+			};
+			func is_odd(n) {
+				when n {
+					is 0 { return false; }
+				};
+				return unwrap <- (is_even <- (n - 1,), false);
+			};
+			unwrap <- (is_even <- (10,), false);`,
+			ExpectedResult: node.CreateBooleanTrue(14),
+		},
+		{
+			// Named functions can refer to themselves after the name they were declared with is reassigned
+			Source: `
+			count = func count_down(n) {
+				when n {
+					is 0 { return 0; }
+				};
+				return unwrap <- (count_down <- (n - 1,), -1);
+			};
+			count_down = 5;
+			unwrap <- (count <- (3,), -1);`,
+			ExpectedResult: node.CreateNumber(9, "0"),
+		},
+	}
+
+	for i, test := range tests {
+		actualResults := getEvaluatorResults(getParserAST(test.Source))
+		actualResult := actualResults[len(actualResults)-1]
+		AssertNodeEqual(t, i, test.ExpectedResult, actualResult)
+	}
+}
+
+func TestEvaluator_NamedFunctionBuiltinError(t *testing.T) {
+	ast := []node.Node{
+		CreateNamedFunction("print", []node.Node{}, []node.Node{}),
+	}
+
+	actualError := getEvaluatorError(t, ast)
+	AssertErrorEqual(t, 0, "error at line 1: \"print\" is a builtin function or variable", actualError)
+}
+
+func TestEvaluator_TypeAnnotations(t *testing.T) {
+	// Annotations are only used by the type checker, which runs before the evaluator (see the type checker tests)
+	tests := []struct {
+		Source         string
+		ExpectedResult node.Node
+	}{
+		{
+			Source: `
+			func add(a: Number, b: Number = 2) -> Number {
+				return a + b;
+			};
+			total: Number = unwrap <- (add <- (1,), 0);
+			total;`,
+			ExpectedResult: node.CreateNumber(6, "3"),
+		},
+	}
+
+	for i, test := range tests {
+		actualResults := getEvaluatorResults(getParserAST(test.Source))
+		actualResult := actualResults[len(actualResults)-1]
+		AssertNodeEqual(t, i, test.ExpectedResult, actualResult)
+	}
+}
+
 func TestEvaluator_FunctionCall(t *testing.T) {
 
 	keywordArgsFunction := CreateFunction(
@@ -890,6 +1097,16 @@ func TestEvaluator_ListIndex(t *testing.T) {
 			Index:         CreateNumber("2"),
 			ExpectedValue: CreateRawString("l"),
 		},
+		{
+			Collection:    CreateRawString("héllo"),
+			Index:         CreateNumber("1"),
+			ExpectedValue: CreateRawString("é"),
+		},
+		{
+			Collection:    CreateRawString("日本語"),
+			Index:         CreateNumber("2"),
+			ExpectedValue: CreateRawString("語"),
+		},
 	}
 
 	for i, test := range tests {
@@ -1662,11 +1879,11 @@ func TestEvaluator_AddInvalidTypesError(t *testing.T) {
 		node.CreateBinaryExpression(
 			CreateRawString("hello"),
 			CreateTokenFromToken(tokens.PLUS_TOKEN),
-			CreateRawString(" world!"),
+			CreateNumber("1"),
 		),
 	}
 	actualError := getEvaluatorError(t, ast)
-	expectedError := "error at line 1: cannot add types String (\"hello\") and String (\" world!\")"
+	expectedError := "error at line 1: cannot add types String (\"hello\") and Number (\"1\")"
 
 	AssertErrorEqual(t, 0, expectedError, actualError)
 }
@@ -1873,6 +2090,26 @@ func TestEvaluator_InvalidLoopStatementsError(t *testing.T) {
 	}
 }
 
+func TestEvaluator_ExportNotGlobalError(t *testing.T) {
+	ast := []node.Node{
+		CreateFunctionCall(
+			CreateFunction(
+				[]node.Node{},
+				[]node.Node{
+					node.CreateExportStatement(
+						TEST_LINE_NUM,
+						CreateAssignmentNode(CreateIdentifier("a"), CreateNumber("1")),
+					),
+				},
+			),
+			[]node.Node{},
+		),
+	}
+
+	actualError := getEvaluatorError(t, ast)
+	AssertErrorEqual(t, 0, "error at line 1: export statements only allowed in the global scope", actualError)
+}
+
 func TestEvaluator_VariableError(t *testing.T) {
 
 	for i, identifier := range evaluator.GetBuiltinNames() {
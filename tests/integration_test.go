@@ -6,7 +6,9 @@ import (
 	"boomerang/parser"
 	"boomerang/tokens"
 	"boomerang/utils"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -195,6 +197,79 @@ func TestIntegration_EditList(t *testing.T) {
 	AssertNodesEqual(t, 0, expectedResult, actualResults)
 }
 
+func TestIntegration_Modules(t *testing.T) {
+
+	var actualResults []node.Node
+	expectedOutput := "\"loading constants\"\n"
+
+	// Modules are only evaluated once, even when imported multiple times
+	AssertExpectedOutput(t, 0, expectedOutput, func() {
+		actualResults = evaluateSource(t, "modules/main.bmg")
+	})
+
+	expectedResults := []node.Node{
+		node.CreateNumber(4, "12.56"),
+		node.CreateRawString(5, "cm"),
+		node.CreateNumber(6, "4"),
+		node.CreateNumber(7, "6.28"),
+		node.CreateNumber(8, "3.14"),
+	}
+	AssertNodesEqual(t, 1, expectedResults, actualResults)
+}
+
+func TestIntegration_ModuleErrors(t *testing.T) {
+
+	cycleA, err := filepath.Abs("integration_tests/modules/cycle_a.bmg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cycleB, err := filepath.Abs("integration_tests/modules/cycle_b.bmg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	geometry, err := filepath.Abs("integration_tests/modules/geometry.bmg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		Source string
+		Error  string
+	}{
+		{
+			Source: "import \"integration_tests/modules/cycle_a.bmg\" as a;",
+			// Errors are only labeled with the module they happened in
+			Error: fmt.Sprintf(
+				"in module %#v: error at line 1: import cycle detected: %s -> %s -> %s",
+				cycleB, cycleA, cycleB, cycleA,
+			),
+		},
+		{
+			Source: "import \"integration_tests/modules/geometry.bmg\" as geometry;\ngeometry.circle_area <- (\"a\",);",
+			Error:  fmt.Sprintf("in module %#v: error at line 5: cannot multiply types String (\"a\") and String (\"a\")", geometry),
+		},
+		{
+			Source: "import \"does_not_exist.bmg\" as a;",
+			Error:  "error at line 1: module \"does_not_exist.bmg\" not found",
+		},
+		{
+			Source: "import \"integration_tests/modules/geometry.bmg\" as geometry;\ngeometry.square;",
+			Error:  fmt.Sprintf("error at line 2: module %s has no exported member \"square\"", geometry),
+		},
+		{
+			Source: "import \"integration_tests/modules/geometry.bmg\" as print;",
+			Error:  "error at line 1: \"print\" is a builtin function or variable",
+		},
+	}
+
+	for i, test := range tests {
+		actualError := getEvaluatorError(t, getParserAST(test.Source))
+		AssertErrorEqual(t, i, test.Error, actualError)
+	}
+}
+
 func evaluateSource(t *testing.T, testFileName string) []node.Node {
 
 	path := strings.Join([]string{INTEGRATION_TESTS_DIRECTORY, testFileName}, string(os.PathSeparator))
@@ -206,15 +281,31 @@ func evaluateSource(t *testing.T, testFileName string) []node.Node {
 		t.Fatal(err)
 	}
 
-	ast, err := parserObj.Parse()
+	statements, err := parserObj.ParseTyped()
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	evaluatorObj := evaluator.NewEvaluator(*ast)
+	evaluatorObj := evaluator.NewEvaluator(statements)
 	results, err := evaluatorObj.Evaluate()
 	if err != nil {
 		t.Fatal(err)
 	}
 	return results
 }
+
+func getIntegrationSources(t *testing.T) []string {
+	// The source code of every program in the integration tests, including modules
+	files, _ := filepath.Glob(filepath.Join(INTEGRATION_TESTS_DIRECTORY, "*.bmg"))
+	moduleFiles, _ := filepath.Glob(filepath.Join(INTEGRATION_TESTS_DIRECTORY, "modules", "*.bmg"))
+
+	sources := []string{}
+	for _, file := range append(files, moduleFiles...) {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		sources = append(sources, string(source))
+	}
+	return sources
+}
@@ -0,0 +1,279 @@
+package tests
+
+import (
+	"boomerang/lint"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func getLintProblems(t *testing.T, source string, config lint.Config) []string {
+	problems, err := lint.Lint("test.bmg", source, config)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	actual := []string{}
+	for _, problem := range problems {
+		actual = append(actual, problem.String())
+	}
+	return actual
+}
+
+func TestLint_Rules(t *testing.T) {
+	tests := []struct {
+		Source   string
+		Problems []string
+	}{
+		{
+			Source: "f = func() { 1; };\nf <- ();\nx = f <- ();\nunwrap <- (x, 0);\nfunc() { 1; } <- ();",
+			Problems: []string{
+				"test.bmg:2: warning: result of function call is discarded (unwrap it or check it with \"is_success\") [unused-monad]",
+				"test.bmg:5: warning: result of function call is discarded (unwrap it or check it with \"is_success\") [unused-monad]",
+			},
+		},
+		{
+			// The last statement in a case is the value of the when-expression, so it is only discarded with the when-expression
+			Source: "f = func() { 1; };\nx = when { true { f <- (); } };\nwhen { true { f <- (); } };\nprint <- (x,);",
+			Problems: []string{
+				"test.bmg:3: warning: result of function call is discarded (unwrap it or check it with \"is_success\") [unused-monad]",
+			},
+		},
+		{
+			Source: "x = true;\nx == true; x != true;\nfalse == x; x != false;",
+			Problems: []string{
+				"test.bmg:2: warning: comparison with \"== true\" (use the value instead) [compare-boolean]",
+				"test.bmg:2: warning: comparison with \"!= true\" (use \"not\" instead) [compare-boolean]",
+				"test.bmg:3: warning: comparison with \"== false\" (use \"not\" instead) [compare-boolean]",
+				"test.bmg:3: warning: comparison with \"!= false\" (use the value instead) [compare-boolean]",
+			},
+		},
+		{
+			Source: "f = func() {\n  return 1;\n  2;\n  3;\n};\nwhile true {\n  break;\n  continue;\n};\nf;",
+			Problems: []string{
+				"test.bmg:3: warning: unreachable statement after return [unreachable-code]",
+				"test.bmg:8: warning: unreachable statement after break [unreachable-code]",
+			},
+		},
+		{
+			Source: "for i in (1, 2) { i; };\nvalues = for i in (1, 2) { i; };\nprint <- (values,);",
+			Problems: []string{
+				"test.bmg:1: info: list created by for-loop is discarded [discarded-for-result]",
+			},
+		},
+		{
+			Source: "f = func() {\n  when { true {\n    for i in (1,) {\n      while false {\n        when { true { i; } };\n      };\n    };\n  } };\n};\nf;",
+			Problems: []string{
+				"test.bmg:3: info: list created by for-loop is discarded [discarded-for-result]",
+				"test.bmg:5: warning: blocks are nested 5 deep (maximum is 4) [deep-nesting]",
+			},
+		},
+		{
+			Source: "len = 1;\nx = 1;\nprint <- (y,);",
+			Problems: []string{
+				"test.bmg:1: error: variable \"len\" has the same name as a builtin function or variable [builtin-name]",
+				"test.bmg:2: warning: unused variable \"x\" [unused-name]",
+				"test.bmg:3: error: undefined identifier: y [undefined-name]",
+			},
+		},
+		{
+			Source: "x: Number = \"a\";\ny: Text = 1;\nprint <- (x, y);",
+			Problems: []string{
+				"test.bmg:1: error: cannot assign String to variable \"x\" of type Number [type-mismatch]",
+				"test.bmg:2: error: unknown type \"Text\" [unknown-type]",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		actual := getLintProblems(t, test.Source, lint.DefaultConfig())
+		if !reflect.DeepEqual(test.Problems, actual) {
+			t.Fatalf("Test #%d: expected problems: %#v, actual problems: %#v", i, test.Problems, actual)
+		}
+	}
+}
+
+func TestLint_Suppression(t *testing.T) {
+	tests := []struct {
+		Source   string
+		Problems []string
+	}{
+		{
+			Source:   "x = true;\nx == true; # lint:ignore compare-boolean",
+			Problems: []string{},
+		},
+		{
+			Source:   "x = true;\n# lint:ignore compare-boolean\nx == true;",
+			Problems: []string{},
+		},
+		{
+			Source:   "## lint:ignore\n##\nprint <- (y == true,);",
+			Problems: []string{},
+		},
+		{
+			// Only the rules in the comment are suppressed, and only on one line
+			Source: "x = true; # lint:ignore unused-name, unreachable-code\nx == true;  # lint:ignore unused-name\nx == false;",
+			Problems: []string{
+				"test.bmg:2: warning: comparison with \"== true\" (use the value instead) [compare-boolean]",
+				"test.bmg:3: warning: comparison with \"== false\" (use \"not\" instead) [compare-boolean]",
+			},
+		},
+		{
+			// Comments in strings are not comments
+			Source: "x = \"# lint:ignore\"; x == true;",
+			Problems: []string{
+				"test.bmg:1: warning: comparison with \"== true\" (use the value instead) [compare-boolean]",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		actual := getLintProblems(t, test.Source, lint.DefaultConfig())
+		if !reflect.DeepEqual(test.Problems, actual) {
+			t.Fatalf("Test #%d: expected problems: %#v, actual problems: %#v", i, test.Problems, actual)
+		}
+	}
+}
+
+func TestLint_Config(t *testing.T) {
+	config := lint.DefaultConfig()
+	config.MaxDepth = 1
+	if err := config.SetSeverity("compare-boolean", lint.ERROR); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := config.SetSeverity("unused-name", lint.OFF); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	actual := getLintProblems(t, "x = true;\nx == true;\nwhile x { when { x { 1; } }; };\ny = 1;", config)
+	expected := []string{
+		"test.bmg:2: error: comparison with \"== true\" (use the value instead) [compare-boolean]",
+		"test.bmg:3: warning: blocks are nested 2 deep (maximum is 1) [deep-nesting]",
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected problems: %#v, actual problems: %#v", expected, actual)
+	}
+}
+
+func TestLint_ConfigErrors(t *testing.T) {
+	tests := []struct {
+		Rule     string
+		Severity string
+		Error    string
+	}{
+		{
+			Rule:     "missing-rule",
+			Severity: lint.ERROR,
+			Error:    "unknown rule \"missing-rule\"",
+		},
+		{
+			Rule:     "compare-boolean",
+			Severity: "fatal",
+			Error:    "invalid severity \"fatal\" for rule \"compare-boolean\" (expected error, warning, info, off)",
+		},
+	}
+
+	for i, test := range tests {
+		config := lint.DefaultConfig()
+		err := config.SetSeverity(test.Rule, test.Severity)
+		if err == nil {
+			t.Fatalf("Test #%d: expected error %#v", i, test.Error)
+		}
+		AssertErrorEqual(t, i, test.Error, err.Error())
+	}
+}
+
+func TestLint_ParseError(t *testing.T) {
+	_, err := lint.Lint("test.bmg", "x = ;", lint.DefaultConfig())
+	if err == nil {
+		t.Fatal("Expected error to not be nil")
+	}
+	AssertErrorEqual(t, 0, "error at line 1: invalid prefix: SEMICOLON (\";\")", err.Error())
+}
+
+func TestLint_JSON(t *testing.T) {
+	problems, err := lint.Lint("test.bmg", "x = true;\nx == true;", lint.DefaultConfig())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var buffer bytes.Buffer
+	if err := lint.WriteJSON(&buffer, problems); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := `[
+  {
+    "path": "test.bmg",
+    "line": 2,
+    "rule": "compare-boolean",
+    "severity": "warning",
+    "message": "comparison with \"== true\" (use the value instead)"
+  }
+]
+`
+	if expected != buffer.String() {
+		t.Fatalf("Expected JSON: %s, actual JSON: %s", expected, buffer.String())
+	}
+}
+
+func TestLint_SARIF(t *testing.T) {
+	problems, err := lint.Lint("test.bmg", "for i in (1,) { i; };", lint.DefaultConfig())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var buffer bytes.Buffer
+	if err := lint.WriteSARIF(&buffer, problems); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buffer.Bytes(), &log); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Expected one SARIF 2.1.0 run, got: %s", buffer.String())
+	}
+	if rules := log.Runs[0].Tool.Driver.Rules; len(rules) != len(lint.Rules()) {
+		t.Fatalf("Expected %d rules, got %d", len(lint.Rules()), len(rules))
+	}
+
+	results := log.Runs[0].Results
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+
+	result := results[0]
+	location := result.Locations[0].PhysicalLocation
+	if result.RuleID != "discarded-for-result" || result.Level != "note" || location.ArtifactLocation.URI != "test.bmg" || location.Region.StartLine != 1 {
+		t.Fatalf("Unexpected result: %s", buffer.String())
+	}
+}
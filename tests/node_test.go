@@ -3,7 +3,14 @@ package tests
 import (
 	"boomerang/node"
 	"boomerang/tokens"
+	"boomerang/utils"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -77,6 +84,17 @@ func TestNode_Equals(t *testing.T) {
 			),
 			IsEqual: true,
 		},
+		{
+			// Map entries can be in any order
+			First:   CreateMap([]node.Node{CreateMapEntry("a", CreateNumber("1")), CreateMapEntry("b", CreateNumber("2"))}),
+			Second:  CreateMap([]node.Node{CreateMapEntry("b", CreateNumber("2")), CreateMapEntry("a", CreateNumber("1"))}),
+			IsEqual: true,
+		},
+		{
+			First:   CreateMap([]node.Node{CreateMapEntry("a", CreateNumber("1"))}),
+			Second:  CreateMap([]node.Node{CreateMapEntry("b", CreateNumber("1"))}),
+			IsEqual: false,
+		},
 	}
 
 	for i, test := range tests {
@@ -378,6 +396,13 @@ func TestNode_String(t *testing.T) {
 			}),
 			String: "(1, 2, 3)",
 		},
+		{
+			Node: CreateMap([]node.Node{
+				CreateMapEntry("name", CreateRawString("app")),
+				CreateMapEntry("tags", CreateList([]node.Node{CreateNumber("1")})),
+			}),
+			String: "{\"name\": \"app\", \"tags\": (1)}",
+		},
 		{
 			Node: CreateList([]node.Node{
 				CreateNumber("1"),
@@ -392,6 +417,15 @@ func TestNode_String(t *testing.T) {
 			}),
 			String: "(1, 2, 3, (5, 7, 6), 4)",
 		},
+		{
+			Node: CreateInterpolatedString([]node.Node{
+				CreateRawString("total: "),
+				CreateInterpolation(CreateIdentifier("total"), ".2f"),
+				CreateRawString(" for "),
+				CreateInterpolation(CreateIdentifier("name"), ""),
+			}),
+			String: "\"total: {total:.2f} for {name}\"",
+		},
 		{
 			Node: CreateFunction(
 				[]node.Node{
@@ -403,6 +437,17 @@ func TestNode_String(t *testing.T) {
 			),
 			String: "func(a,b,c){...}",
 		},
+		{
+			Node: CreateNamedFunction(
+				"add",
+				[]node.Node{
+					CreateIdentifier("a"),
+					CreateIdentifier("b"),
+				},
+				[]node.Node{},
+			),
+			String: "func add(a,b){...}",
+		},
 		{
 			Node:   CreateBuiltinFunctionIdentifier("print"),
 			String: "<built-in function print>",
@@ -658,3 +703,311 @@ func TestNode_TestCreateBuiltinVariableIdentifier(t *testing.T) {
 	expectedNode := node.Node{Type: node.BUILTIN_VARIABLE, Value: "variable", LineNum: TEST_LINE_NUM}
 	AssertNodeEqual(t, 0, expectedNode, actualNode)
 }
+
+func TestNode_Walk(t *testing.T) {
+	root := getParserAST("x = -1;")[0]
+
+	// Each node is recorded with its depth and index, and its parent's type when it is visited after its parameters
+	visited := []string{}
+	node.Walk(
+		root,
+		func(c *node.Cursor) bool {
+			visited = append(visited, fmt.Sprintf("pre %s %d %d", c.Node.Type, c.Depth(), c.Index))
+			return true
+		},
+		func(c *node.Cursor) {
+			parentType := "none"
+			if parent, ok := c.Parent(); ok {
+				parentType = parent.Type
+			}
+			visited = append(visited, fmt.Sprintf("post %s %s", c.Node.Type, parentType))
+		},
+	)
+
+	expected := []string{
+		"pre Assign 0 -1",
+		"pre Identifier 1 0",
+		"post Identifier Assign",
+		"pre UnaryExpression 1 1",
+		"pre MINUS 2 0",
+		"post MINUS UnaryExpression",
+		"pre Number 2 1",
+		"post Number UnaryExpression",
+		"post UnaryExpression Assign",
+		"post Assign none",
+	}
+	if !reflect.DeepEqual(expected, visited) {
+		t.Fatalf("Expected: %v, actual: %v", expected, visited)
+	}
+}
+
+func TestNode_Inspect(t *testing.T) {
+	root := getParserAST("f = func(a) { a + b; } <- (c,);")[0]
+
+	// Find identifiers that are not in a function, with the types of the nodes containing them
+	found := []string{}
+	node.Inspect(root, func(c *node.Cursor) bool {
+		if c.Node.Type == node.FUNCTION {
+			return false
+		}
+
+		if c.Node.Type == node.IDENTIFIER {
+			path := []string{}
+			for _, parent := range c.Parents {
+				path = append(path, parent.Type)
+			}
+			found = append(found, fmt.Sprintf("%s in %s", c.Node.Value, strings.Join(path, "/")))
+		}
+		return true
+	})
+
+	expected := []string{
+		"f in Assign",
+		"c in Assign/BinaryExpression/List",
+	}
+	if !reflect.DeepEqual(expected, found) {
+		t.Fatalf("Expected: %v, actual: %v", expected, found)
+	}
+}
+
+func TestNode_Rewrite(t *testing.T) {
+	original := getParserAST("x = (1 + 2) * y;")[0]
+	originalString := original.String()
+
+	// Rename "y" and replace additions of two numbers with their sums
+	actual := node.Rewrite(original, func(c *node.Cursor) node.Node {
+		n := c.Node
+		switch {
+		case n.Type == node.IDENTIFIER && n.Value == "y":
+			return node.CreateIdentifier(n.LineNum, "z")
+
+		case n.Type == node.BIN_EXPR && n.GetParam(node.OPERATOR).Type == tokens.PLUS:
+			left, right := n.GetParam(node.LEFT), n.GetParam(node.RIGHT)
+			if left.Type == node.NUMBER && right.Type == node.NUMBER {
+				sum := *utils.ConvertStringToInteger(left.Value) + *utils.ConvertStringToInteger(right.Value)
+				return node.CreateNumber(n.LineNum, fmt.Sprint(sum))
+			}
+		}
+		return n
+	})
+
+	expected := getParserAST("x = 3 * z;")[0]
+	AssertNodeEqual(t, 0, expected, actual)
+
+	if originalString != original.String() {
+		t.Fatalf("Expected original tree to be unchanged: %s, actual: %s", originalString, original.String())
+	}
+}
+
+func TestNode_UpdateLineNumbers(t *testing.T) {
+	// Updating line numbers must not change nodes that share parameters with the updated node
+	shared := CreateList([]node.Node{CreateNumber("1"), CreateNumber("2")})
+	updated := shared
+	updated.UpdateLineNumbers(5)
+
+	expected := node.CreateList(5, []node.Node{node.CreateNumber(5, "1"), node.CreateNumber(5, "2")})
+	AssertNodeEqual(t, 0, expected, updated)
+	AssertNodeEqual(t, 1, CreateList([]node.Node{CreateNumber("1"), CreateNumber("2")}), shared)
+}
+
+func TestNode_JSON(t *testing.T) {
+	source := "x = 1;\n\"a {x}\";"
+	actual, err := json.Marshal(getParserAST(source))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := `[` +
+		`{"type":"Assign","line":1,"params":[` +
+		`{"key":"Identifier","type":"Identifier","value":"x","line":1},` +
+		`{"key":"Expression","type":"Number","value":"1","line":1}]},` +
+		`{"type":"String","line":2,"params":[` +
+		`{"type":"String","value":"a ","line":2,"params":[]},` +
+		`{"type":"Interpolation","line":2,"params":[{"key":"Expression","type":"Identifier","value":"x","line":2}]}]}` +
+		`]`
+	if expected != string(actual) {
+		t.Fatalf("Expected JSON: %s, actual JSON: %s", expected, string(actual))
+	}
+}
+
+func TestNode_JSONRoundTrip(t *testing.T) {
+	sources := append(append([]string{}, astSources...), getIntegrationSources(t)...)
+
+	for i, source := range sources {
+		expected := getParserAST(source)
+		data, err := json.Marshal(expected)
+		if err != nil {
+			t.Fatalf("Test #%d: %s", i, err.Error())
+		}
+
+		actual := []node.Node{}
+		if err := json.Unmarshal(data, &actual); err != nil {
+			t.Fatalf("Test #%d: %s", i, err.Error())
+		}
+
+		// Parameters that are nil and empty are different, so the trees must be deeply equal
+		if !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("Test #%d: expected AST: %v, actual AST: %v", i, expected, actual)
+		}
+
+		// Loaded trees must also produce the same JSON
+		reencoded, err := json.Marshal(actual)
+		if err != nil {
+			t.Fatalf("Test #%d: %s", i, err.Error())
+		}
+		if string(data) != string(reencoded) {
+			t.Fatalf("Test #%d: expected JSON: %s, actual JSON: %s", i, string(data), string(reencoded))
+		}
+	}
+}
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+func TestNode_JSONGolden(t *testing.T) {
+	// Compare the AST for a program to its snapshot, and evaluate the AST loaded from the snapshot
+	source, err := os.ReadFile(filepath.Join(INTEGRATION_TESTS_DIRECTORY, "edit_list.bmg"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	goldenPath := filepath.Join(INTEGRATION_TESTS_DIRECTORY, "edit_list.ast.json")
+
+	actual, err := json.MarshalIndent(getParserAST(string(source)), "", "  ")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	actual = append(actual, '\n')
+
+	if *updateGolden {
+		if err := os.WriteFile(goldenPath, actual, 0644); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if string(expected) != string(actual) {
+		t.Fatalf("AST does not match %s (run \"go test ./tests -run TestNode_JSONGolden -update\" to update it)", goldenPath)
+	}
+
+	loaded := []node.Node{}
+	if err := json.Unmarshal(expected, &loaded); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	AssertNodesEqual(t, 0, getEvaluatorResults(getParserAST(string(source))), getEvaluatorResults(loaded))
+}
+
+func TestNode_JSONErrors(t *testing.T) {
+	tests := []struct {
+		JSON  string
+		Error string
+	}{
+		{
+			JSON:  `[{"value":"1","line":1}]`,
+			Error: "node on line 1 has no type",
+		},
+		{
+			JSON:  `[{"type":"Assign","line":2,"params":[{"key":"Expression","type":"Identifier","value":"x","line":2},{"type":"Number","value":"1","line":2}]}]`,
+			Error: "invalid parameter \"Expression\" at index 0 in Assign node on line 2",
+		},
+		{
+			JSON:  `[{"type":"BinaryExpression","line":3,"params":[{"type":"Number","value":"1","line":3}]}]`,
+			Error: "BinaryExpression node on line 3 needs 3 parameters, got 1",
+		},
+		{
+			JSON:  `[{"type":"Return","line":4}]`,
+			Error: "Return node on line 4 needs 1 parameters, got 0",
+		},
+		{
+			JSON:  `[{"type":"Number","value":1,"line":1}]`,
+			Error: "json: cannot unmarshal number into Go struct field jsonNode.value of type string",
+		},
+		{
+			JSON:  `[{"type":"Bogus","line":1}]`,
+			Error: "Bogus node on line 1 is not a statement",
+		},
+		{
+			JSON:  `[{"type":"Return","line":1,"params":[{"type":"Bogus","line":1}]}]`,
+			Error: "invalid Bogus node at index 0 in Return node on line 1",
+		},
+		{
+			JSON:  `[{"type":"Function","line":1,"params":[{"type":"Number","value":"1","line":1},{"type":"BlockStatements","line":1,"params":[]}]}]`,
+			Error: "invalid Number node at index 0 in Function node on line 1",
+		},
+		{
+			JSON:  `[{"type":"UnaryExpression","line":1,"params":[{"type":"PLUS","value":"+","line":1},{"type":"Number","value":"1","line":1}]}]`,
+			Error: "invalid PLUS node at index 0 in UnaryExpression node on line 1",
+		},
+		{
+			JSON:  `[{"type":"Number","value":"1","line":1,"params":[{"type":"Number","value":"2","line":1}]}]`,
+			Error: "invalid Number node at index 0 in Number node on line 1",
+		},
+		{
+			JSON:  `[{"type":"List","line":1,"params":[{"key":"Expression","type":"Number","value":"1","line":1}]}]`,
+			Error: "invalid parameter \"Expression\" at index 0 in List node on line 1",
+		},
+		{
+			JSON:  `[{"type":"Number","value":"abc","line":1}]`,
+			Error: "invalid number \"abc\" on line 1",
+		},
+		{
+			JSON:  `[{"type":"Number","value":"1.0","line":1}]`,
+			Error: "number \"1.0\" on line 1 must be written as \"1\"",
+		},
+		{
+			JSON:  `[{"type":"Boolean","value":"yes","line":1}]`,
+			Error: "invalid boolean \"yes\" on line 1",
+		},
+	}
+
+	for i, test := range tests {
+		actual := []node.Node{}
+		err := json.Unmarshal([]byte(test.JSON), &actual)
+		if err == nil {
+			t.Fatalf("Test #%d: expected error %#v", i, test.Error)
+		}
+		if err.Error() != test.Error {
+			t.Fatalf("Test #%d: expected error: %#v, actual error: %#v", i, test.Error, err.Error())
+		}
+	}
+}
+
+func TestNode_Tree(t *testing.T) {
+	nodes := getParserAST("x = 1;\nprint <- (\"a\\n\",);")
+
+	expected := `Assign (line 1)
+  Identifier: Identifier "x" (line 1)
+  Expression: Number "1" (line 1)
+BinaryExpression (line 2)
+  Left: BuiltinFunction "print" (line 2)
+  Operator: SEND "<-" (line 2)
+  Right: List (line 2)
+    String "a\n" (line 2)
+`
+	if actual := node.Tree(nodes); expected != actual {
+		t.Fatalf("Expected tree:\n%s\nactual tree:\n%s", expected, actual)
+	}
+}
+
+func TestNode_DOT(t *testing.T) {
+	nodes := getParserAST("x = \"a\\\"b\";\ny;")
+
+	expected := `digraph AST {
+  node [shape=box, fontname="monospace"];
+  n0 [label="Program"];
+  n1 [label="Assign\n(line 1)"];
+  n0 -> n1;
+  n2 [label="Identifier\n\"x\"\n(line 1)"];
+  n1 -> n2 [label="Identifier"];
+  n3 [label="String\n\"a\\\"b\"\n(line 1)"];
+  n1 -> n3 [label="Expression"];
+  n4 [label="Identifier\n\"y\"\n(line 2)"];
+  n0 -> n4;
+}
+`
+	if actual := node.DOT(nodes); expected != actual {
+		t.Fatalf("Expected graph:\n%s\nactual graph:\n%s", expected, actual)
+	}
+}
@@ -25,6 +25,40 @@ func TestParser_Numbers(t *testing.T) {
 	}
 }
 
+func TestParser_NumberLiterals(t *testing.T) {
+	// Number literals are converted to the same form as every other number, so equal numbers have the same value
+	tests := []struct {
+		Source   string
+		Expected string
+	}{
+		{Source: "1.50", Expected: "1.5"},
+		{Source: ".5", Expected: "0.5"},
+		{Source: "09", Expected: "9"},
+		{Source: "100.0", Expected: "100"},
+		{Source: "1.", Expected: "1"},
+		{Source: "1_000_000", Expected: "1000000"},
+		{Source: "3.141_592", Expected: "3.141592"},
+		{Source: "1e6", Expected: "1000000"},
+		{Source: "1.5E-3", Expected: "0.0015"},
+		{Source: ".5e+2", Expected: "50"},
+		{Source: "1e-400", Expected: "0"},
+		{Source: "0xff", Expected: "255"},
+		{Source: "0XAb", Expected: "171"},
+		{Source: "0o17", Expected: "15"},
+		{Source: "0b1010_1010", Expected: "170"},
+		{Source: "0x10000000000000000", Expected: "18446744073709552000"},
+	}
+
+	for i, test := range tests {
+		actualAST := getParserAST(fmt.Sprintf("%s;", test.Source))
+		expectedAST := []node.Node{
+			CreateNumber(test.Expected),
+		}
+
+		AssertNodesEqual(t, i, expectedAST, actualAST)
+	}
+}
+
 func TestParser_Booleans(t *testing.T) {
 	tests := []struct {
 		Source       string
@@ -55,55 +89,164 @@ func TestParser_Strings(t *testing.T) {
 	plusToken := tokens.PLUS_TOKEN
 	plusToken.LineNumber = 1
 
+	onePlusOne := node.CreateBinaryExpression(
+		CreateNumber("1"),
+		plusToken,
+		CreateNumber("1"),
+	)
+
 	tests := []struct {
-		InputSource  string
-		OutputSource string
-		Params       []node.Node
+		InputSource string
+		Expected    node.Node
 	}{
 		{
-			InputSource:  "hello, world!",
-			OutputSource: "hello, world!",
-			Params:       []node.Node{},
+			InputSource: "hello, world!",
+			Expected:    CreateRawString("hello, world!"),
 		},
 		{
-			InputSource:  "My age is {55}",
-			OutputSource: "My age is <0>",
-			Params: []node.Node{
-				CreateNumber("55"),
-			},
+			InputSource: "My age is {55}",
+			Expected: CreateInterpolatedString([]node.Node{
+				CreateRawString("My age is "),
+				CreateInterpolation(CreateNumber("55"), ""),
+			}),
 		},
 		{
-			InputSource:  "{1 + 1} {1 + 1} {1 + 1}",
-			OutputSource: "<0> <1> <2>",
-			Params: []node.Node{
-				node.CreateBinaryExpression(
-					CreateNumber("1"),
-					plusToken,
-					CreateNumber("1"),
-				),
-				node.CreateBinaryExpression(
-					CreateNumber("1"),
-					plusToken,
-					CreateNumber("1"),
-				),
-				node.CreateBinaryExpression(
-					CreateNumber("1"),
-					plusToken,
-					CreateNumber("1"),
+			InputSource: "{1 + 1} {1 + 1} {1 + 1}",
+			Expected: CreateInterpolatedString([]node.Node{
+				CreateInterpolation(onePlusOne, ""),
+				CreateRawString(" "),
+				CreateInterpolation(onePlusOne, ""),
+				CreateRawString(" "),
+				CreateInterpolation(onePlusOne, ""),
+			}),
+		},
+		{
+			// Strings in an interpolated expression
+			InputSource: `{"a" + "{1}"}!`,
+			Expected: CreateInterpolatedString([]node.Node{
+				CreateInterpolation(
+					node.CreateBinaryExpression(
+						CreateRawString("a"),
+						plusToken,
+						CreateInterpolatedString([]node.Node{CreateInterpolation(CreateNumber("1"), "")}),
+					),
+					"",
 				),
-			},
+				CreateRawString("!"),
+			}),
+		},
+		{
+			InputSource: "{x:.2f}|{y:*^8}",
+			Expected: CreateInterpolatedString([]node.Node{
+				CreateInterpolation(CreateIdentifier("x"), ".2f"),
+				CreateRawString("|"),
+				CreateInterpolation(CreateIdentifier("y"), "*^8"),
+			}),
 		},
 	}
 
 	for i, test := range tests {
 		actualAST := getParserAST(fmt.Sprintf("\"%s\";", test.InputSource))
-		expectedAST := []node.Node{
-			node.CreateString(1, test.OutputSource, test.Params),
-		}
+		expectedAST := []node.Node{test.Expected}
 		AssertNodesEqual(t, i, expectedAST, actualAST)
 	}
 }
 
+func TestParser_EscapedStrings(t *testing.T) {
+	tests := []struct {
+		Source   string
+		Expected node.Node
+	}{
+		{
+			Source:   `"tab\there\nquote \" backslash \\";`,
+			Expected: CreateRawString("tab\there\nquote \" backslash \\"),
+		},
+		{
+			Source:   `"\u{48}\u{e9}\u{1F600}";`,
+			Expected: CreateRawString("Hé😀"),
+		},
+		{
+			Source: `"\{not interpolated\} {1}";`,
+			Expected: CreateInterpolatedString([]node.Node{
+				CreateRawString("{not interpolated} "),
+				CreateInterpolation(CreateNumber("1"), ""),
+			}),
+		},
+		{
+			Source: `"\\{1}";`,
+			Expected: CreateInterpolatedString([]node.Node{
+				CreateRawString("\\"),
+				CreateInterpolation(CreateNumber("1"), ""),
+			}),
+		},
+		{
+			// Expressions in multi-line strings have the line number they appear on
+			Source: "\"\"\"line 1\n\"line 2\" {1}\"\"\";",
+			Expected: CreateInterpolatedString([]node.Node{
+				CreateRawString("line 1\n\"line 2\" "),
+				node.CreateInterpolation(2, node.CreateNumber(2, "1"), ""),
+			}),
+		},
+	}
+
+	for i, test := range tests {
+		actualAST := getParserAST(test.Source)
+		expectedAST := []node.Node{test.Expected}
+		AssertNodesEqual(t, i, expectedAST, actualAST)
+	}
+}
+
+func TestParser_RawStrings(t *testing.T) {
+	tests := []struct {
+		Source string
+		Output string
+	}{
+		{Source: `r"C:\temp\new";`, Output: `C:\temp\new`},
+		{Source: `r"{1 + 1}";`, Output: `{1 + 1}`},
+		{Source: "r\"\"\"a \"quoted\"\nline\"\"\";", Output: "a \"quoted\"\nline"},
+	}
+
+	for i, test := range tests {
+		actualAST := getParserAST(test.Source)
+		expectedAST := []node.Node{CreateRawString(test.Output)}
+		AssertNodesEqual(t, i, expectedAST, actualAST)
+	}
+}
+
+func TestParser_StringInterpolationErrors(t *testing.T) {
+	tests := []struct {
+		Source string
+		Error  string
+	}{
+		{
+			Source: `"value: {1`,
+			Error:  "error at line 1: did not find ending } for string interpolation",
+		},
+		{
+			Source: `"{}";`,
+			Error:  "error at line 1: missing expression in string interpolation",
+		},
+		{
+			Source: `"{1 2}";`,
+			Error:  "error at line 1: expected } after expression in string interpolation, got NUMBER (\"2\")",
+		},
+		{
+			Source: `"{1:>8";`,
+			Error:  "error at line 1: did not find ending } for format specifier",
+		},
+		{
+			// Errors in interpolated expressions report the line number the error is on
+			Source: "x = 1;\n\"\"\"first line\nsecond line {x +}\"\"\";",
+			Error:  "error at line 3: invalid prefix: STRING_END (\"\")",
+		},
+	}
+
+	for i, test := range tests {
+		actualError := getParserError(t, test.Source)
+		AssertErrorEqual(t, i, test.Error, actualError)
+	}
+}
+
 func TestParser_TestParameters(t *testing.T) {
 
 	tests := []struct {
@@ -188,6 +331,64 @@ func TestParser_Bang(t *testing.T) {
 	AssertNodesEqual(t, 0, expectedAST, actualAST)
 }
 
+func TestParser_UnaryOperatorPrecedence(t *testing.T) {
+	tests := []struct {
+		Source      string
+		ExpectedAST node.Node
+	}{
+		{
+			// Unary operators only apply to the value directly after them, including before member access
+			Source: "-geometry.pi;",
+			ExpectedAST: node.CreateBinaryExpression(
+				node.CreateUnaryExpression(
+					CreateTokenFromToken(tokens.MINUS_TOKEN),
+					CreateIdentifier("geometry"),
+				),
+				CreateTokenFromToken(tokens.PERIOD_TOKEN),
+				CreateIdentifier("pi"),
+			),
+		},
+		{
+			Source: "-values @ 0;",
+			ExpectedAST: node.CreateBinaryExpression(
+				node.CreateUnaryExpression(
+					CreateTokenFromToken(tokens.MINUS_TOKEN),
+					CreateIdentifier("values"),
+				),
+				CreateTokenFromToken(tokens.AT_TOKEN),
+				CreateNumber("0"),
+			),
+		},
+		{
+			Source: "-a * b;",
+			ExpectedAST: node.CreateBinaryExpression(
+				node.CreateUnaryExpression(
+					CreateTokenFromToken(tokens.MINUS_TOKEN),
+					CreateIdentifier("a"),
+				),
+				CreateTokenFromToken(tokens.ASTERISK_TOKEN),
+				CreateIdentifier("b"),
+			),
+		},
+		{
+			Source: "not a == b;",
+			ExpectedAST: node.CreateBinaryExpression(
+				node.CreateUnaryExpression(
+					CreateTokenFromToken(tokens.NOT_TOKEN),
+					CreateIdentifier("a"),
+				),
+				CreateTokenFromToken(tokens.EQ_TOKEN),
+				CreateIdentifier("b"),
+			),
+		},
+	}
+
+	for i, test := range tests {
+		actualAST := getParserAST(test.Source)
+		AssertNodesEqual(t, i, []node.Node{test.ExpectedAST}, actualAST)
+	}
+}
+
 func TestParser_BinaryExpression(t *testing.T) {
 
 	tests := []struct {
@@ -341,6 +542,27 @@ func TestParser_Function(t *testing.T) {
 	AssertNodesEqual(t, 0, expectedAST, actualAST)
 }
 
+func TestParser_NamedFunction(t *testing.T) {
+	actualAST := getParserAST("func add(a, b) { a + b; };")
+	expectedAST := []node.Node{
+		CreateNamedFunction(
+			"add",
+			[]node.Node{
+				CreateIdentifier("a"),
+				CreateIdentifier("b"),
+			},
+			[]node.Node{
+				node.CreateBinaryExpression(
+					CreateIdentifier("a"),
+					CreateTokenFromToken(tokens.PLUS_TOKEN),
+					CreateIdentifier("b"),
+				),
+			},
+		),
+	}
+	AssertNodesEqual(t, 0, expectedAST, actualAST)
+}
+
 func TestParser_FunctionNoParameters(t *testing.T) {
 	actualAST := getParserAST("func() { 3 + 4; };")
 	expectedAST := []node.Node{
@@ -649,6 +871,124 @@ func TestParser_ReturnStatement(t *testing.T) {
  * ERROR TESTS *
  * * * * * * * */
 
+func TestParser_ImportStatement(t *testing.T) {
+	actualAST := getParserAST("import \"lib/math.bmg\" as math;")
+	expectedAST := []node.Node{
+		node.CreateImportStatement(TEST_LINE_NUM, "lib/math.bmg", CreateIdentifier("math")),
+	}
+	AssertNodesEqual(t, 0, expectedAST, actualAST)
+}
+
+func TestParser_ExportStatement(t *testing.T) {
+	tests := []struct {
+		Source      string
+		ExpectedAST []node.Node
+	}{
+		{
+			Source: "export a = 1;",
+			ExpectedAST: []node.Node{
+				node.CreateExportStatement(
+					TEST_LINE_NUM,
+					CreateAssignmentNode(CreateIdentifier("a"), CreateNumber("1")),
+				),
+			},
+		},
+		{
+			Source: "export func f() {};",
+			ExpectedAST: []node.Node{
+				node.CreateExportStatement(
+					TEST_LINE_NUM,
+					CreateNamedFunction("f", []node.Node{}, []node.Node{}),
+				),
+			},
+		},
+		{
+			Source: "export a;",
+			ExpectedAST: []node.Node{
+				node.CreateExportStatement(TEST_LINE_NUM, CreateIdentifier("a")),
+			},
+		},
+	}
+
+	for i, test := range tests {
+		actualAST := getParserAST(test.Source)
+		AssertNodesEqual(t, i, test.ExpectedAST, actualAST)
+	}
+}
+
+func TestParser_MemberAccess(t *testing.T) {
+	actualAST := getParserAST("geometry.add <- (1, 2);")
+	expectedAST := []node.Node{
+		node.CreateBinaryExpression(
+			node.CreateBinaryExpression(
+				CreateIdentifier("geometry"),
+				CreateTokenFromToken(tokens.PERIOD_TOKEN),
+				CreateIdentifier("add"),
+			),
+			CreateTokenFromToken(tokens.SEND_TOKEN),
+			CreateList([]node.Node{
+				CreateNumber("1"),
+				CreateNumber("2"),
+			}),
+		),
+	}
+	AssertNodesEqual(t, 0, expectedAST, actualAST)
+}
+
+func TestParser_ModuleErrors(t *testing.T) {
+	tests := []struct {
+		Source string
+		Error  string
+	}{
+		{
+			Source: "export func() {};",
+			Error:  "error at line 1: invalid type for export: Function (\"\")",
+		},
+		{
+			Source: "import geometry;",
+			Error:  "error at line 1: expected token type STRING (\"\\\"(.*?)\\\"\"), got IDENTIFIER (\"geometry\")",
+		},
+		{
+			Source: "geometry.(a);",
+			Error:  `error at line 1: expected token type IDENTIFIER ("[\\p{L}_][\\p{L}\\p{Nd}_]*"), got OPEN_PAREN ("(")`,
+		},
+	}
+
+	for i, test := range tests {
+		actualError := getParserError(t, test.Source)
+		AssertErrorEqual(t, i, test.Error, actualError)
+	}
+}
+
+func TestParser_TypeAnnotationErrors(t *testing.T) {
+	tests := []struct {
+		Source string
+		Error  string
+	}{
+		{
+			Source: "x: = 1;",
+			Error:  `error at line 1: expected token type IDENTIFIER ("[\\p{L}_][\\p{L}\\p{Nd}_]*"), got ASSIGN ("=")`,
+		},
+		{
+			Source: "x: Number;",
+			Error:  "error at line 1: expected token type ASSIGN (\"=\"), got SEMICOLON (\";\")",
+		},
+		{
+			Source: "x: List[Number = 1;",
+			Error:  "error at line 1: expected token type CLOSED_BRACKET (\"]\"), got ASSIGN (\"=\")",
+		},
+		{
+			Source: "f = func() -> {};",
+			Error:  `error at line 1: expected token type IDENTIFIER ("[\\p{L}_][\\p{L}\\p{Nd}_]*"), got OPEN_CURLY_BRACKET ("{")`,
+		},
+	}
+
+	for i, test := range tests {
+		actualError := getParserError(t, test.Source)
+		AssertErrorEqual(t, i, test.Error, actualError)
+	}
+}
+
 func TestParser_UnexpectedTokenError(t *testing.T) {
 	actualError := getParserError(t, "1")
 	expectedError := "error at line 1: expected token type SEMICOLON (\";\"), got EOF (\"\")"
@@ -692,3 +1032,84 @@ func TestParser_WhenExpressionErrors(t *testing.T) {
 		AssertErrorEqual(t, 0, test.Error, actualError)
 	}
 }
+
+func TestParser_Trees(t *testing.T) {
+	// Compare parser output as indented trees (see "node.Tree"), which are easier to read than trees of nodes
+	tests := []struct {
+		Source string
+		Tree   string
+	}{
+		{
+			Source: "x = -(1 + 2) * y;",
+			Tree: `Assign (line 1)
+  Identifier: Identifier "x" (line 1)
+  Expression: BinaryExpression (line 1)
+    Left: UnaryExpression (line 1)
+      Operator: MINUS "-" (line 1)
+      Expression: BinaryExpression (line 1)
+        Left: Number "1" (line 1)
+        Operator: PLUS "+" (line 1)
+        Right: Number "2" (line 1)
+    Operator: ASTERISK "*" (line 1)
+    Right: Identifier "y" (line 1)
+`,
+		},
+		{
+			Source: "func f(a, b = 2) {\n  return a;\n};",
+			Tree: `Function "f" (line 1)
+  List: List (line 1)
+    Identifier "a" (line 1)
+    Assign (line 1)
+      Identifier: Identifier "b" (line 1)
+      Expression: Number "2" (line 1)
+  Statements: BlockStatements (line 0)
+    Return "return" (line 2)
+      Expression: Identifier "a" (line 2)
+`,
+		},
+		{
+			Source: "when x {\n  is 1 { \"{x:>3}\"; }\n};",
+			Tree: `When (line 1)
+  WhenValue: Identifier "x" (line 1)
+  WhenCases: WhenCases (line 1)
+    Case (line 2)
+      CaseValue: Number "1" (line 2)
+      CaseStatements: BlockStatements (line 0)
+        String (line 2)
+          Interpolation ">3" (line 2)
+            Expression: Identifier "x" (line 2)
+  WhenCasesDefault: BlockStatements (line 0)
+`,
+		},
+		{
+			// Annotations are the only parameter of an identifier, and a function's return type is its last parameter
+			Source: "func f(a: Number, b: List[String] = (\"x\",)) -> Number {\n  return a;\n};\nx: Boolean = true;",
+			Tree: `Function "f" (line 1)
+  List: List (line 1)
+    Identifier "a" (line 1)
+      TypeAnnotation "Number" (line 1)
+    Assign (line 1)
+      Identifier: Identifier "b" (line 1)
+        TypeAnnotation "List" (line 1)
+          TypeAnnotation "String" (line 1)
+      Expression: List (line 1)
+        String "x" (line 1)
+  Statements: BlockStatements (line 0)
+    Return "return" (line 2)
+      Expression: Identifier "a" (line 2)
+  TypeAnnotation "Number" (line 1)
+Assign (line 4)
+  Identifier: Identifier "x" (line 4)
+    TypeAnnotation "Boolean" (line 4)
+  Expression: Boolean "true" (line 4)
+`,
+		},
+	}
+
+	for i, test := range tests {
+		actual := node.Tree(getParserAST(test.Source))
+		if test.Tree != actual {
+			t.Fatalf("Test #%d: expected tree:\n%s\nactual tree:\n%s", i, test.Tree, actual)
+		}
+	}
+}
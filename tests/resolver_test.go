@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"boomerang/resolver"
+	"reflect"
+	"testing"
+)
+
+func getResolverDiagnostics(t *testing.T, source string) []string {
+	diagnostics := []string{}
+	for _, diagnostic := range resolver.Resolve(getTypedAST(t, source)).Diagnostics {
+		diagnostics = append(diagnostics, diagnostic.String())
+	}
+	return diagnostics
+}
+
+func TestResolver_Diagnostics(t *testing.T) {
+	tests := []struct {
+		Source      string
+		Diagnostics []string
+	}{
+		{
+			Source:      "x = 1;\nprint <- (x + y,);",
+			Diagnostics: []string{"error at line 2: undefined identifier: y"},
+		},
+		{
+			// Names must be assigned before they are used, even in rarely-taken branches
+			Source: "when {\n  false { print <- (z,); }\n};\nz = 1;\nprint <- (z,);",
+			Diagnostics: []string{
+				"error at line 2: undefined identifier: z",
+			},
+		},
+		{
+			// Function bodies can use names assigned after them
+			Source:      "f = func() { total + 1; };\ntotal = 1;\nf <- ();",
+			Diagnostics: []string{},
+		},
+		{
+			// Named functions are declared before the other statements in their block
+			Source:      "print <- (is_even <- (2,),);\nfunc is_even(n) { when n { is 0 { true; } else { is_odd <- (n - 1,); } }; };\nfunc is_odd(n) { not (is_even <- (n,)); };",
+			Diagnostics: []string{},
+		},
+		{
+			// Parameters, defaults and for-loop variables
+			Source:      "f = func(a, b = a + 1) {\n  for i in (a, b) { i; };\n};\nf <- (1,);",
+			Diagnostics: []string{},
+		},
+		{
+			// Variables assigned in a function are not available outside of it
+			Source:      "f = func() { inner = 1; inner; };\nf <- ();\ninner;",
+			Diagnostics: []string{"error at line 3: undefined identifier: inner"},
+		},
+		{
+			Source: "x = 1;\nf = func(a, _b) {\n  unused = 2;\n};\nf <- (1, 2);\nfor item in (1, 2) { 1; };",
+			Diagnostics: []string{
+				"warning at line 1: unused variable \"x\"",
+				"warning at line 2: unused parameter \"a\"",
+				"warning at line 3: unused variable \"unused\"",
+				"warning at line 6: unused variable \"item\"",
+			},
+		},
+		{
+			Source: "len = 1;\nf = func(print) { 1; };\nf <- (1,);\nfunc random() { 1; };\nimport \"a.bmg\" as process;",
+			Diagnostics: []string{
+				"error at line 1: variable \"len\" has the same name as a builtin function or variable",
+				"warning at line 2: parameter \"print\" has the same name as a builtin function or variable",
+				"error at line 4: function \"random\" has the same name as a builtin function or variable",
+				"error at line 5: import \"process\" has the same name as a builtin function or variable",
+			},
+		},
+		{
+			// Exported names are not unused, and module members are not variables
+			Source:      "import \"geometry.bmg\" as g;\nexport area = g.circle_area <- (1,);\nsides = 4;\nexport sides;\nexport func f() { 1; };",
+			Diagnostics: []string{},
+		},
+		{
+			Source:      "export missing;",
+			Diagnostics: []string{"error at line 1: undefined identifier: missing"},
+		},
+		{
+			// Resolution is lexical, so names found in the scope of a caller when the program runs are reported
+			Source:      "f = func() { z; };\ng = func() { z = 2; f <- (); };\ng <- ();",
+			Diagnostics: []string{"error at line 1: undefined identifier: z", "warning at line 2: unused variable \"z\""},
+		},
+		{
+			// A returned function is not reported, although it can't find "y" in the scope of its caller
+			Source:      "make = func() { y = 1; return func() { y; }; };\ng = unwrap <- (make <- (), 0);\ng <- ();",
+			Diagnostics: []string{},
+		},
+	}
+
+	for i, test := range tests {
+		actual := getResolverDiagnostics(t, test.Source)
+		if !reflect.DeepEqual(test.Diagnostics, actual) {
+			t.Fatalf("Test #%d: expected diagnostics: %#v, actual diagnostics: %#v", i, test.Diagnostics, actual)
+		}
+	}
+}
+
+func TestResolver_IntegrationPrograms(t *testing.T) {
+	// The integration test programs run without errors, so none of their names are undefined
+	for i, source := range getIntegrationSources(t) {
+		result := resolver.Resolve(getTypedAST(t, source))
+		if result.HasErrors() {
+			t.Fatalf("Test #%d: unexpected diagnostics: %v", i, result.Diagnostics)
+		}
+	}
+}
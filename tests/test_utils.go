@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"boomerang/ast"
 	"boomerang/evaluator"
 	"boomerang/node"
 	"boomerang/parser"
@@ -9,6 +10,7 @@ import (
 	"io"
 	"os"
 	"testing"
+	"time"
 )
 
 const TEST_LINE_NUM = 1
@@ -18,7 +20,7 @@ const TEST_LINE_NUM = 1
  * * * * * * * * * */
 
 func getEvaluatorResults(ast []node.Node) []node.Node {
-	evaluatorObj := evaluator.NewEvaluator(ast)
+	evaluatorObj := evaluator.NewEvaluator(convertToTypedAST(ast))
 	actualResults, err := evaluatorObj.Evaluate()
 	if err != nil {
 		panic(err.Error())
@@ -26,8 +28,51 @@ func getEvaluatorResults(ast []node.Node) []node.Node {
 	return actualResults
 }
 
+func getSeededEvaluatorResults(ast []node.Node, seed int64) []node.Node {
+	evaluatorObj := evaluator.NewEvaluator(convertToTypedAST(ast))
+	evaluatorObj.SetRandomSeed(seed)
+	actualResults, err := evaluatorObj.Evaluate()
+	if err != nil {
+		panic(err.Error())
+	}
+	return actualResults
+}
+
+func evaluateWithFileRoot(t *testing.T, ast []node.Node, fileRoot string) ([]node.Node, error) {
+	// Evaluate with filesystem access allowed in "fileRoot"
+	evaluatorObj := evaluator.NewEvaluator(convertToTypedAST(ast))
+	if err := evaluatorObj.SetFileRoot(fileRoot); err != nil {
+		t.Fatal(err.Error())
+	}
+	return evaluatorObj.Evaluate()
+}
+
+// A clock for testing time builtins. Sleeping moves the clock forward instead of waiting.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(duration time.Duration) {
+	c.now = c.now.Add(duration)
+}
+
+func evaluateWithClock(t *testing.T, ast []node.Node, clock evaluator.Clock) []node.Node {
+	evaluatorObj := evaluator.NewEvaluator(convertToTypedAST(ast))
+	evaluatorObj.SetClock(clock)
+
+	actualResults, err := evaluatorObj.Evaluate()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return actualResults
+}
+
 func getEvaluatorError(t *testing.T, ast []node.Node) string {
-	evaluatorObj := evaluator.NewEvaluator(ast)
+	evaluatorObj := evaluator.NewEvaluator(convertToTypedAST(ast))
 	_, err := evaluatorObj.Evaluate()
 
 	if err == nil {
@@ -36,6 +81,15 @@ func getEvaluatorError(t *testing.T, ast []node.Node) string {
 	return err.Error()
 }
 
+func convertToTypedAST(statements []node.Node) []ast.Statement {
+	// Convert the statements in a test to the typed AST the evaluator runs
+	typedStatements, err := ast.FromNodes(statements)
+	if err != nil {
+		panic(err.Error())
+	}
+	return typedStatements
+}
+
 func getParserAST(source string) []node.Node {
 	t := tokens.NewTokenizer(source)
 
@@ -95,8 +149,12 @@ func CreateBooleanFalse() node.Node {
 	return node.CreateBooleanFalse(TEST_LINE_NUM)
 }
 
-func CreateString(value string, params []node.Node) node.Node {
-	return node.CreateString(TEST_LINE_NUM, value, params)
+func CreateInterpolatedString(parts []node.Node) node.Node {
+	return node.CreateInterpolatedString(TEST_LINE_NUM, parts)
+}
+
+func CreateInterpolation(expression node.Node, formatSpec string) node.Node {
+	return node.CreateInterpolation(TEST_LINE_NUM, expression, formatSpec)
 }
 
 func CreateRawString(value string) node.Node {
@@ -107,6 +165,15 @@ func CreateMonad(value *node.Node) node.Node {
 	return node.CreateMonad(TEST_LINE_NUM, value)
 }
 
+func CreateMonadExpression(value *node.Node) node.Node {
+	// Monads are not part of the source code, so programs create them by calling a function that returns the value
+	statements := []node.Node{}
+	if value != nil {
+		statements = append(statements, CreateReturnStatement(*value))
+	}
+	return CreateFunctionCall(CreateFunction([]node.Node{}, statements), []node.Node{})
+}
+
 func CreateIdentifier(value string) node.Node {
 	return node.CreateIdentifier(TEST_LINE_NUM, value)
 }
@@ -123,6 +190,14 @@ func CreateList(values []node.Node) node.Node {
 	return node.CreateList(TEST_LINE_NUM, values)
 }
 
+func CreateMap(entries []node.Node) node.Node {
+	return node.CreateMap(TEST_LINE_NUM, entries)
+}
+
+func CreateMapEntry(key string, value node.Node) node.Node {
+	return node.CreateMapEntry(TEST_LINE_NUM, key, value)
+}
+
 func CreateAssignmentNode(variable node.Node, value node.Node) node.Node {
 	return node.CreateAssignmentNode(variable, value)
 }
@@ -135,6 +210,15 @@ func CreateFunction(parameters []node.Node, statements []node.Node) node.Node {
 	)
 }
 
+func CreateNamedFunction(name string, parameters []node.Node, statements []node.Node) node.Node {
+	return node.CreateNamedFunction(
+		TEST_LINE_NUM,
+		name,
+		parameters,
+		CreateBlockStatements(statements),
+	)
+}
+
 func CreateFunctionCall(function node.Node, callParams []node.Node) node.Node {
 	return node.CreateFunctionCall(TEST_LINE_NUM, function, callParams)
 }
@@ -2,12 +2,16 @@ package tests
 
 import (
 	"boomerang/tokens"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestTokenizer_Symbols(t *testing.T) {
-	tokenizer := getTokenizer("+-*/()=,{}<-[]==!=<%;")
+	tokenizer := getTokenizer("+-*/()=,{}<-[]==!=<%;.:->")
 	expectedTokens := []tokens.Token{
 		CreateTokenFromToken(tokens.PLUS_TOKEN),
 		CreateTokenFromToken(tokens.MINUS_TOKEN),
@@ -27,6 +31,9 @@ func TestTokenizer_Symbols(t *testing.T) {
 		CreateTokenFromToken(tokens.LT_TOKEN),
 		CreateTokenFromToken(tokens.MODULO_TOKEN),
 		CreateTokenFromToken(tokens.SEMICOLON_TOKEN),
+		CreateTokenFromToken(tokens.PERIOD_TOKEN),
+		CreateTokenFromToken(tokens.COLON_TOKEN),
+		CreateTokenFromToken(tokens.ARROW_TOKEN),
 	}
 
 	for i, expectedToken := range expectedTokens {
@@ -51,6 +58,9 @@ func TestTokenizer_Keywords(t *testing.T) {
 		{Type: tokens.BREAK, Literal: "break", LineNumber: TEST_LINE_NUM},
 		{Type: tokens.CONTINUE, Literal: "continue", LineNumber: TEST_LINE_NUM},
 		{Type: tokens.RETURN, Literal: "return", LineNumber: TEST_LINE_NUM},
+		{Type: tokens.IMPORT, Literal: "import", LineNumber: TEST_LINE_NUM},
+		{Type: tokens.AS, Literal: "as", LineNumber: TEST_LINE_NUM},
+		{Type: tokens.EXPORT, Literal: "export", LineNumber: TEST_LINE_NUM},
 	}
 
 	for i, expectedToken := range keywordTokens {
@@ -75,6 +85,19 @@ func TestTokenizer_Numbers(t *testing.T) {
 		"1.1",
 		".1",
 		"1234567890.0987654321",
+		"1.",
+		"007",
+		"1e6",
+		"1.5E-3",
+		".5e+2",
+		"1.e5",
+		"0xff",
+		"0XAb",
+		"0o17",
+		"0b1010",
+		"1_000_000",
+		"3.141_592",
+		"0b1010_1010",
 	}
 
 	for i, source := range numbers {
@@ -85,6 +108,41 @@ func TestTokenizer_Numbers(t *testing.T) {
 	}
 }
 
+func TestTokenizer_NumberErrors(t *testing.T) {
+	tests := []struct {
+		Source string
+		Error  string
+	}{
+		{Source: "1.2.3", Error: "error at line 1: invalid number 1.2.3"},
+		{Source: "1..2", Error: "error at line 1: invalid number 1..2"},
+		{Source: "x = 1;\ny = 0xfg;", Error: "error at line 2: invalid number 0xfg"},
+		{Source: "0x", Error: "error at line 1: invalid number 0x"},
+		{Source: "0b102", Error: "error at line 1: invalid number 0b102"},
+		{Source: "0o8", Error: "error at line 1: invalid number 0o8"},
+		{Source: "1e", Error: "error at line 1: invalid number 1e"},
+		{Source: "1e+;", Error: "error at line 1: invalid number 1e+"},
+		{Source: "1_", Error: "error at line 1: invalid number 1_"},
+		{Source: "1__000", Error: "error at line 1: invalid number 1__000"},
+		{Source: "1_.5", Error: "error at line 1: invalid number 1_.5"},
+		{Source: "12abc", Error: "error at line 1: invalid number 12abc"},
+		{Source: "1.5.foo", Error: "error at line 1: invalid number 1.5.foo"},
+		{Source: "1e400", Error: "error at line 1: number 1e400 is too large"},
+		{Source: "0x1" + strings.Repeat("0", 300), Error: "error at line 1: number 0x1" + strings.Repeat("0", 300) + " is too large"},
+	}
+
+	for i, test := range tests {
+		tokenizer := getTokenizer(test.Source)
+		_, err := tokenizer.All()
+		if err == nil {
+			t.Fatalf("Test #%d: an error was expected, but no errors occurred", i)
+		}
+
+		if test.Error != err.Error() {
+			t.Fatalf("Test #%d: expected error: %#v, actual error: %#v", i, test.Error, err.Error())
+		}
+	}
+}
+
 func TestTokenizer_Strings(t *testing.T) {
 
 	testStrings := []string{
@@ -140,6 +198,11 @@ func TestTokenizer_Identifiers(t *testing.T) {
 		"variable",
 		"varaible1",
 		"variable_23",
+		"_private",
+		"café",
+		"числа",
+		"变量2",
+		"x٣", // Arabic-Indic digit three
 	}
 
 	for i, variable := range variables {
@@ -150,6 +213,19 @@ func TestTokenizer_Identifiers(t *testing.T) {
 	}
 }
 
+func TestTokenizer_IdentifierNumberCharacters(t *testing.T) {
+	// Only decimal digits (\p{Nd}) can be in identifiers, not other number characters like superscripts
+	tokenizer := getTokenizer("x²")
+
+	token, _ := tokenizer.Next()
+	AssertTokenEqual(t, 0, CreateTokenFromValues(tokens.IDENTIFIER, "x"), *token)
+
+	_, err := tokenizer.Next()
+	if err == nil || err.Error() != "error at line 1: invalid character ²" {
+		t.Fatalf("Expected error: error at line 1: invalid character ², actual error: %v", err)
+	}
+}
+
 func TestTokenizer_InlineCommentEOF(t *testing.T) {
 	source := "# this is a comment"
 	tokenizer := getTokenizer(source)
@@ -205,6 +281,196 @@ func TestTokenizer_BlockCommentError(t *testing.T) {
 	}
 }
 
+func TestTokenizer_UnicodeStrings(t *testing.T) {
+	source := "\"héllo\" @ 1;"
+	expectedTokens := []tokens.Token{
+		CreateTokenFromValues(tokens.STRING, "héllo"),
+		CreateTokenFromToken(tokens.AT_TOKEN),
+		CreateTokenFromValues(tokens.NUMBER, "1"),
+	}
+
+	tokenizer := getTokenizer(source)
+	for i, expectedToken := range expectedTokens {
+		actualToken, _ := tokenizer.Next()
+		AssertTokenEqual(t, i, expectedToken, *actualToken)
+	}
+}
+
+func TestTokenizer_InvalidEncodingError(t *testing.T) {
+	source := "a = 1;\nb = \"\xff\";"
+	tokenizer := getTokenizer(source)
+
+	_, err := tokenizer.Next()
+	if err == nil {
+		t.Fatal("An error was expected, but no errors occurred")
+	}
+
+	actualError := err.Error()
+	expectedError := "error at line 2: invalid UTF-8 encoding at byte 12"
+	if expectedError != actualError {
+		t.Fatalf("Expected error: %#v, Actual error: %#v", expectedError, actualError)
+	}
+}
+
+func TestTokenizer_EscapedStrings(t *testing.T) {
+	// Escape sequences are left in the literal for the parser to decode
+	source := `"say \"hi\"\n" "a\\b" "\u{1F600} \{x\}";`
+	expectedTokens := []tokens.Token{
+		CreateTokenFromValues(tokens.STRING, `say \"hi\"\n`),
+		CreateTokenFromValues(tokens.STRING, `a\\b`),
+		CreateTokenFromValues(tokens.STRING, `\u{1F600} \{x\}`),
+		CreateTokenFromToken(tokens.SEMICOLON_TOKEN),
+	}
+
+	tokenizer := getTokenizer(source)
+	for i, expectedToken := range expectedTokens {
+		actualToken, _ := tokenizer.Next()
+		AssertTokenEqual(t, i, expectedToken, *actualToken)
+	}
+}
+
+func TestTokenizer_RawStrings(t *testing.T) {
+	source := `r"C:\temp\{x}" r"""say "hi" """ raw;`
+	expectedTokens := []tokens.Token{
+		CreateTokenFromValues(tokens.RAW_STRING, `C:\temp\{x}`),
+		CreateTokenFromValues(tokens.RAW_STRING, `say "hi" `),
+		CreateTokenFromValues(tokens.IDENTIFIER, "raw"),
+		CreateTokenFromToken(tokens.SEMICOLON_TOKEN),
+	}
+
+	tokenizer := getTokenizer(source)
+	for i, expectedToken := range expectedTokens {
+		actualToken, _ := tokenizer.Next()
+		AssertTokenEqual(t, i, expectedToken, *actualToken)
+	}
+}
+
+func TestTokenizer_MultilineStrings(t *testing.T) {
+	source := "\"\"\"first\n\"quoted\"\nthird\"\"\"\nx;"
+	expectedTokens := []tokens.Token{
+		CreateTokenWithLineNum(CreateTokenFromValues(tokens.STRING, "first\n\"quoted\"\nthird"), 1),
+		CreateTokenWithLineNum(CreateTokenFromValues(tokens.IDENTIFIER, "x"), 4),
+		CreateTokenWithLineNum(tokens.SEMICOLON_TOKEN, 4),
+	}
+
+	tokenizer := getTokenizer(source)
+	for i, expectedToken := range expectedTokens {
+		actualToken, _ := tokenizer.Next()
+		AssertTokenEqual(t, i, expectedToken, *actualToken)
+	}
+}
+
+func TestTokenizer_StringErrors(t *testing.T) {
+	tests := []struct {
+		Source string
+		Error  string
+	}{
+		{
+			Source: "\"hello",
+			Error:  "error at line 1: did not find ending \" while parsing string",
+		},
+		{
+			Source: "\"hello\nworld\"",
+			Error:  "error at line 1: did not find ending \" while parsing string (use \"\"\" for multi-line strings)",
+		},
+		{
+			Source: "x;\n\"\"\"hello\n\"",
+			Error:  "error at line 2: did not find ending \"\"\" while parsing string",
+		},
+		{
+			Source: "\"\"\"a\nb \\q\"\"\"",
+			Error:  "error at line 2: invalid escape sequence \\q",
+		},
+		{
+			Source: "\"\\u{110000}\"",
+			Error:  "error at line 1: invalid Unicode code point \"110000\" in escape sequence",
+		},
+		{
+			Source: "\"\\u1234\"",
+			Error:  "error at line 1: invalid Unicode escape sequence: expected \\u{XXXX}",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("Test #%d", i), func(t *testing.T) {
+			tokenizer := getTokenizer(test.Source)
+
+			var err error
+			for err == nil {
+				var token *tokens.Token
+				token, err = tokenizer.Next()
+				if err == nil && token.Type == tokens.EOF {
+					t.Fatal("An error was expected, but no errors occurred")
+				}
+			}
+
+			if test.Error != err.Error() {
+				t.Fatalf("Expected error: %#v, Actual error: %#v", test.Error, err.Error())
+			}
+		})
+	}
+}
+
+func TestTokenizer_InterpolatedStrings(t *testing.T) {
+	source := "\"a{x}b{f <- (\"{1}\",)}c{y:>8}\";"
+	expectedTokens := []tokens.Token{
+		CreateTokenFromValues(tokens.STRING_START, "a"),
+		CreateTokenFromValues(tokens.IDENTIFIER, "x"),
+		CreateTokenFromValues(tokens.STRING_MIDDLE, "b"),
+		CreateTokenFromValues(tokens.IDENTIFIER, "f"),
+		CreateTokenFromToken(tokens.SEND_TOKEN),
+		CreateTokenFromToken(tokens.OPEN_PAREN_TOKEN),
+		CreateTokenFromValues(tokens.STRING_START, ""),
+		CreateTokenFromValues(tokens.NUMBER, "1"),
+		CreateTokenFromValues(tokens.STRING_END, ""),
+		CreateTokenFromToken(tokens.COMMA_TOKEN),
+		CreateTokenFromToken(tokens.CLOSED_PAREN_TOKEN),
+		CreateTokenFromValues(tokens.STRING_MIDDLE, "c"),
+		CreateTokenFromValues(tokens.IDENTIFIER, "y"),
+		CreateTokenFromValues(tokens.FORMAT_SPEC, ">8"),
+		CreateTokenFromValues(tokens.STRING_END, ""),
+		CreateTokenFromToken(tokens.SEMICOLON_TOKEN),
+	}
+
+	tokenizer := getTokenizer(source)
+	for i, expectedToken := range expectedTokens {
+		actualToken, err := tokenizer.Next()
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		AssertTokenEqual(t, i, expectedToken, *actualToken)
+	}
+}
+
+func TestTokenizer_InterpolatedStringBraces(t *testing.T) {
+	// Curly braces in an interpolated expression do not end the expression
+	source := "\"\"\"{func() {\n1;\n} <- ()}\n\"\"\""
+	expectedTokens := []tokens.Token{
+		CreateTokenWithLineNum(CreateTokenFromValues(tokens.STRING_START, ""), 1),
+		CreateTokenWithLineNum(tokens.FUNCTION_TOKEN, 1),
+		CreateTokenWithLineNum(tokens.OPEN_PAREN_TOKEN, 1),
+		CreateTokenWithLineNum(tokens.CLOSED_PAREN_TOKEN, 1),
+		CreateTokenWithLineNum(tokens.OPEN_CURLY_BRACKET_TOKEN, 1),
+		CreateTokenWithLineNum(CreateTokenFromValues(tokens.NUMBER, "1"), 2),
+		CreateTokenWithLineNum(tokens.SEMICOLON_TOKEN, 2),
+		CreateTokenWithLineNum(tokens.CLOSED_CURLY_BRACKET_TOKEN, 3),
+		CreateTokenWithLineNum(tokens.SEND_TOKEN, 3),
+		CreateTokenWithLineNum(tokens.OPEN_PAREN_TOKEN, 3),
+		CreateTokenWithLineNum(tokens.CLOSED_PAREN_TOKEN, 3),
+		CreateTokenWithLineNum(CreateTokenFromValues(tokens.STRING_END, "\n"), 3),
+		CreateTokenWithLineNum(tokens.EOF_TOKEN, 4),
+	}
+
+	tokenizer := getTokenizer(source)
+	for i, expectedToken := range expectedTokens {
+		actualToken, err := tokenizer.Next()
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		AssertTokenEqual(t, i, expectedToken, *actualToken)
+	}
+}
+
 func TestTokenizer_Booleans(t *testing.T) {
 	/*
 		This test is because I originally had the boolean regex "true|false", but after appending "^", the regex
@@ -225,6 +491,129 @@ func TestTokenizer_Booleans(t *testing.T) {
 	}
 }
 
+func TestTokenizer_Positions(t *testing.T) {
+	source := "x = 1.5;\n  s = \"a{x}é\"; ## comment ## y\n\tz"
+	expectedPositions := []struct {
+		Literal string
+		Column  int
+		Offset  int
+	}{
+		{Literal: "x", Column: 1, Offset: 0},
+		{Literal: "=", Column: 3, Offset: 2},
+		{Literal: "1.5", Column: 5, Offset: 4},
+		{Literal: ";", Column: 8, Offset: 7},
+		{Literal: "s", Column: 3, Offset: 11},
+		{Literal: "=", Column: 5, Offset: 13},
+		{Literal: "a", Column: 7, Offset: 15},
+		{Literal: "x", Column: 10, Offset: 18},
+		{Literal: "é", Column: 11, Offset: 19},
+		{Literal: ";", Column: 15, Offset: 23},
+		{Literal: "y", Column: 31, Offset: 39},
+		{Literal: "z", Column: 2, Offset: 42},
+		{Literal: "", Column: 3, Offset: 43},
+	}
+
+	tokenizer := getTokenizer(source)
+	actualTokens, err := tokenizer.All()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(expectedPositions) != len(actualTokens) {
+		t.Fatalf("Expected %d tokens, got %d", len(expectedPositions), len(actualTokens))
+	}
+
+	for i, expected := range expectedPositions {
+		actual := actualTokens[i]
+		if expected.Literal != actual.Literal || expected.Column != actual.Column || expected.Offset != actual.Offset {
+			t.Fatalf(
+				"Test #%d: expected %#v at column %d, offset %d, got %#v at column %d, offset %d",
+				i,
+				expected.Literal,
+				expected.Column,
+				expected.Offset,
+				actual.Literal,
+				actual.Column,
+				actual.Offset,
+			)
+		}
+	}
+}
+
+func TestTokenizer_ReaderTokenizer(t *testing.T) {
+	// Reading one byte at a time splits tokens, escape sequences and multi-byte characters across reads
+	sources := []string{
+		tokenizerBenchmarkBlock,
+		"x = \"a{\"b{y:>5}\" + func(){ {1} }}c\\u{1F600}\";",
+		"\"\"\"\nmulti\n{x}\n\"\"\"; r\"\"\"raw\nstring\"\"\";",
+		"\"\\u{123 }\"",
+		"## unfinished",
+		"x = \"a\nb\";",
+		"1 $",
+		"",
+	}
+
+	for i, source := range sources {
+		stringTokenizer := getTokenizer(source)
+		expectedTokens, expectedErr := stringTokenizer.All()
+
+		readerTokenizer := tokens.NewReaderTokenizer(iotest.OneByteReader(strings.NewReader(source)))
+		actualTokens, actualErr := readerTokenizer.All()
+
+		if fmt.Sprint(expectedErr) != fmt.Sprint(actualErr) {
+			t.Fatalf("Test #%d: expected error: %v, actual error: %v", i, expectedErr, actualErr)
+		}
+
+		if len(expectedTokens) != len(actualTokens) {
+			t.Fatalf("Test #%d: expected %d tokens, got %d", i, len(expectedTokens), len(actualTokens))
+		}
+
+		for j, expectedToken := range expectedTokens {
+			if expectedToken != actualTokens[j] {
+				t.Fatalf("Test #%d: expected token: %#v, actual token: %#v", i, expectedToken, actualTokens[j])
+			}
+		}
+	}
+}
+
+func TestTokenizer_ReaderErrors(t *testing.T) {
+	tests := []struct {
+		Reader        io.Reader
+		ExpectedError string
+	}{
+		{
+			Reader:        iotest.OneByteReader(strings.NewReader("a = 1;\nb = \"\xff\";")),
+			ExpectedError: "error at line 2: invalid UTF-8 encoding at byte 12",
+		},
+		{
+			// Incomplete multi-byte character at the end of the source
+			Reader:        iotest.OneByteReader(strings.NewReader("a = \"\xc3")),
+			ExpectedError: "error at line 1: invalid UTF-8 encoding at byte 5",
+		},
+		{
+			Reader:        io.MultiReader(strings.NewReader("a = 1;\n"), iotest.ErrReader(errors.New("disk failure"))),
+			ExpectedError: "error at line 2: cannot read source: disk failure",
+		},
+	}
+
+	for i, test := range tests {
+		tokenizer := tokens.NewReaderTokenizer(test.Reader)
+		_, err := tokenizer.All()
+		if err == nil {
+			t.Fatalf("Test #%d: an error was expected, but no errors occurred", i)
+		}
+
+		if test.ExpectedError != err.Error() {
+			t.Fatalf("Test #%d: expected error: %#v, actual error: %#v", i, test.ExpectedError, err.Error())
+		}
+
+		// The error is returned again instead of any more tokens
+		if _, err := tokenizer.Next(); err == nil || test.ExpectedError != err.Error() {
+			t.Fatalf("Test #%d: expected error: %#v, actual error: %v", i, test.ExpectedError, err)
+		}
+	}
+}
+
 func getTokenizer(source string) tokens.Tokenizer {
 	return tokens.NewTokenizer(source)
 }
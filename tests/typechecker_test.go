@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"boomerang/ast"
+	"boomerang/typechecker"
+	"reflect"
+	"testing"
+)
+
+func getTypeDiagnostics(t *testing.T, source string) []string {
+	diagnostics := []string{}
+	for _, diagnostic := range typechecker.Check(getTypedAST(t, source)).Diagnostics {
+		diagnostics = append(diagnostics, diagnostic.String())
+	}
+	return diagnostics
+}
+
+func TestTypeChecker_Diagnostics(t *testing.T) {
+	tests := []struct {
+		Source      string
+		Diagnostics []string
+	}{
+		{
+			Source: "x = 1 + \"a\";\ny = -\"b\";\nz = not 1;",
+			Diagnostics: []string{
+				"error at line 1: cannot add types Number and String",
+				"error at line 2: invalid type for minus operator: String",
+				"error at line 3: invalid type for bang operator: Number",
+			},
+		},
+		{
+			// Code without annotations is only reported when the types are known
+			Source:      "f = func(a, b) { return a + b; };\nprint <- (f <- (1, \"a\"),);",
+			Diagnostics: []string{},
+		},
+		{
+			Source: "x: Number = \"a\";\ny: String = \"b\";\ny = 1;\nx: String = \"c\";",
+			Diagnostics: []string{
+				"error at line 1: cannot assign String to variable \"x\" of type Number",
+				"error at line 3: cannot assign Number to variable \"y\" of type String",
+				"error at line 4: variable \"x\" is already declared with type Number",
+			},
+		},
+		{
+			Source: "func add(a: Number, b: Number = \"2\") -> Number {\n  return a + b;\n};\nadd <- (\"1\",);\nadd <- ();\nadd <- (1, 2, 3);",
+			Diagnostics: []string{
+				"error at line 1: cannot use String as the default value of parameter \"b\" of type Number",
+				"error at line 4: cannot pass String to parameter \"a\" of type Number",
+				"error at line 5: parameter \"a\" does not have a value",
+				"error at line 6: expected 2 arguments, got 3",
+			},
+		},
+		{
+			Source:      "f = func() -> String {\n  return 1;\n};",
+			Diagnostics: []string{"error at line 2: cannot return Number from function with return type String"},
+		},
+		{
+			// Function calls return monads, which must be unwrapped before their values are used
+			Source: "f = func() { return 1; };\nx = (f <- ()) + 1;\ny = (unwrap <- (f <- (), 0)) + 1;\nz = unwrap <- (1, 0);",
+			Diagnostics: []string{
+				"error at line 2: cannot add types Monad[Number] and Number",
+				"error at line 4: expected Monad, got Number",
+			},
+		},
+		{
+			Source: "values: List[Number] = (1, 2);\nfor v in values { v + \"a\"; };\nvalues @ \"a\";\nfor c in \"abc\" { c; };\n1 in 2;",
+			Diagnostics: []string{
+				"error at line 2: cannot add types Number and String",
+				"error at line 3: invalid types for index: List[Number] and String",
+				"error at line 4: invalid type for for loop: String",
+				"error at line 5: right side of \"in\" must be a list. Actual type: Number",
+			},
+		},
+		{
+			// Variables from outer scopes are only known in functions if they are annotated
+			Source:      "a = 1;\nb = 1;\nb = 2;\nc: Number = 1;\nc = 2;\nf = func() { a + \"x\"; b + \"x\"; c + \"x\"; };\nf;",
+			Diagnostics: []string{"error at line 6: cannot add types Number and String"},
+		},
+		{
+			// Functions find names in the scope of their caller, so "x" in "f" is the string assigned in "g"
+			Source:      "x = 1;\nf = func() { return x + \"a\"; };\ng = func() { x = \"s\"; return unwrap <- (f <- (), \"\"); };\ng <- ();",
+			Diagnostics: []string{},
+		},
+		{
+			// Variables assigned values of different types can have either type
+			Source:      "x = 1;\nx = \"a\";\nx + \"b\";",
+			Diagnostics: []string{},
+		},
+		{
+			Source: "x: Foo = 1;\ny: Number[String] = 1;\nf = func(a: List[Bar]) {};\nf;",
+			Diagnostics: []string{
+				"error at line 1: unknown type \"Foo\"",
+				"error at line 2: type Number does not have an element type",
+				"error at line 3: unknown type \"Bar\"",
+			},
+		},
+		{
+			Source: "1 <- 2;\nx = 1;\nx.y;\n1 < \"a\";",
+			Diagnostics: []string{
+				"error at line 1: cannot use send on types Number and Number",
+				"error at line 3: cannot access member \"y\" on type Number",
+				"error at line 4: invalid types for less than: Number and String",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		actual := getTypeDiagnostics(t, test.Source)
+		if !reflect.DeepEqual(test.Diagnostics, actual) {
+			t.Fatalf("Test #%d: expected diagnostics: %#v, actual diagnostics: %#v", i, test.Diagnostics, actual)
+		}
+	}
+}
+
+func TestTypeChecker_Inference(t *testing.T) {
+	tests := []struct {
+		Source string
+		Type   string // Type of the last statement
+	}{
+		{
+			Source: "func add(a: Number, b: Number) -> Number { return a + b; };\nadd <- (1, 2);",
+			Type:   "Monad[Number]",
+		},
+		{
+			Source: "func add(a: Number, b: Number) -> Number { return a + b; };\nunwrap <- (add <- (1, 2), 0);",
+			Type:   "Number",
+		},
+		{
+			// Return types are inferred from "return" statements
+			Source: "f = func(a) { when a { is 1 { return \"one\"; } }; return \"other\"; };\nf <- (1,);",
+			Type:   "Monad[String]",
+		},
+		{
+			Source: "f = func() { print <- (1,); };\nf <- ();",
+			Type:   "Monad",
+		},
+		{
+			Source: "for i in range <- (0, 3) { i * 2; };",
+			Type:   "List[Monad[Number]]",
+		},
+		{
+			Source: "(1, 2) <- 3;",
+			Type:   "List[Number]",
+		},
+		{
+			Source: "when x { is 1 { \"a\"; } else { \"b\"; } };",
+			Type:   "Monad[String]",
+		},
+		{
+			Source: "(a, b) = (1, \"a\");\nb;",
+			Type:   "String",
+		},
+		{
+			Source: "x = 1;\nx = \"a\";\nx;",
+			Type:   "Any",
+		},
+		{
+			// Functions in builtin modules have the same return types as other builtins
+			Source: "lists.sum <- ((1, 2),);",
+			Type:   "Number",
+		},
+		{
+			Source: `strings.split <- ("a,b", ",");`,
+			Type:   "List[String]",
+		},
+	}
+
+	for i, test := range tests {
+		statements := getTypedAST(t, test.Source)
+		result := typechecker.Check(statements)
+
+		last, ok := statements[len(statements)-1].(ast.Expression)
+		if !ok {
+			t.Fatalf("Test #%d: expected an expression, got %T", i, statements[len(statements)-1])
+		}
+
+		if actual := result.Types[last].String(); test.Type != actual {
+			t.Fatalf("Test #%d: expected type %s, got %s", i, test.Type, actual)
+		}
+	}
+}
+
+func TestTypeChecker_IntegrationPrograms(t *testing.T) {
+	// The integration test programs run without errors, so they do not have type errors
+	for i, source := range getIntegrationSources(t) {
+		result := typechecker.Check(getTypedAST(t, source))
+		if result.HasErrors() {
+			t.Fatalf("Test #%d: unexpected diagnostics: %v", i, result.Diagnostics)
+		}
+	}
+}
+
+func TestTypeChecker_AnnotatedListAssignment(t *testing.T) {
+	// The parser only allows annotations on variables, so the annotation is added to the tree directly
+	statements := getTypedAST(t, "(a, b) = (1, 2);\na + \"x\";")
+	assignment, ok := statements[0].(*ast.Assignment)
+	if !ok {
+		t.Fatalf("Expected an assignment, got %T", statements[0])
+	}
+	assignment.Type = &ast.TypeAnnotation{Position: assignment.Position, Name: "Number"}
+
+	actual := []string{}
+	for _, diagnostic := range typechecker.Check(statements).Diagnostics {
+		actual = append(actual, diagnostic.String())
+	}
+
+	expected := []string{
+		"error at line 1: type annotations can only be used on variables",
+		"error at line 2: cannot add types Number and String",
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected diagnostics: %#v, actual diagnostics: %#v", expected, actual)
+	}
+}
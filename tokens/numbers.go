@@ -0,0 +1,73 @@
+package tokens
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+/*
+Number literals can be written in several ways, which all produce the same kind of number:
+
+	decimal      1, 1.5, .5, 1.
+	scientific   1e6, 1.5E-3, .5e+2
+	hexadecimal  0xff, 0XFF
+	octal        0o17
+	binary       0b1010
+
+Underscores can separate digits (e.g., 1_000_000 and 0b1010_1010), but only between two digits.
+*/
+var numberBases = map[byte]int{'x': 16, 'X': 16, 'o': 8, 'O': 8, 'b': 2, 'B': 2}
+
+func isDigitInBase(char byte, base int) bool {
+	switch base {
+	case 16:
+		return isDigit(char) || ('a' <= char && char <= 'f') || ('A' <= char && char <= 'F')
+	case 8:
+		return '0' <= char && char <= '7'
+	case 2:
+		return char == '0' || char == '1'
+	}
+	return isDigit(char)
+}
+
+/*
+FormatNumber returns the value of a number. Every number value is created by this function, so equal numbers always have
+the same value. Large numbers are written without an exponent (e.g., 1000000 instead of 1e+06), so whole numbers can be
+used as integers however large they are.
+*/
+func FormatNumber(value float64) string {
+	if math.Abs(value) >= 1 && math.Abs(value) < 1e21 {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+/*
+NumberValue converts a number literal accepted by the tokenizer to its number value (see "FormatNumber"), so literals for the same number have the same value (e.g., "1e2", "0x64", "100.0" and "0100"
+are all "100"). Returns an error if the number is too large.
+*/
+func NumberValue(literal string) (string, error) {
+	digits := strings.ReplaceAll(literal, "_", "")
+
+	if len(digits) > 2 && digits[0] == '0' {
+		if base, ok := numberBases[digits[1]]; ok {
+			integer, _ := new(big.Int).SetString(digits[2:], base)
+			value, _ := new(big.Float).SetInt(integer).Float64()
+			if math.IsInf(value, 0) {
+				return "", fmt.Errorf("number %s is too large", literal)
+			}
+			return FormatNumber(value), nil
+		}
+	}
+
+	// Very small numbers round to 0, so only overflow is an error
+	value, _ := strconv.ParseFloat(digits, 64)
+	if math.IsInf(value, 0) {
+		return "", fmt.Errorf("number %s is too large", literal)
+	}
+
+	return FormatNumber(value), nil
+}
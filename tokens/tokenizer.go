@@ -3,26 +3,150 @@ package tokens
 import (
 	"boomerang/utils"
 	"fmt"
-	"regexp"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type Tokenizer struct {
 	source            string
 	currentPos        int
 	currentLineNumber int
+	encodingChecked   bool            // Whether the source has been checked for invalid UTF-8
+	interpolations    []interpolation // Strings whose interpolated expressions are being tokenized, innermost last
+
+	// Positions are offsets in bytes from the start of the whole source, including text that has been discarded
+	offset         int // Number of bytes before "source" that were tokenized and discarded (only when streaming)
+	lineStart      int // Offset of the first character on the current line
+	tokenStart     int // Offset of the first character of the token being read
+	tokenLineStart int // Offset of the first character on the line where the token being read starts
+
+	// Streaming tokenizers (see "NewReaderTokenizer") read the source in chunks as tokens need it
+	reader         io.Reader // Source of more text, or nil when the rest of the source is in "source"
+	readErr        error     // Error from reading or decoding the source, returned by every following call to "Next"
+	pending        string    // Bytes at the end of the last chunk that are not a complete UTF-8 character yet
+	readLineNumber int       // Line number at the end of the text that has been read, for encoding errors
+}
+
+type interpolation struct {
+	delimiter       string // Delimiter of the string containing the expression
+	startLineNumber int    // Line number where the string starts
+	braceDepth      int    // Number of unclosed curly braces in the expression (e.g., from function literals)
 }
 
 const EOF_CHAR = 0 // end-of-file character
 
+const READ_SIZE = 64 * 1024 // Minimum number of bytes streaming tokenizers read at a time
+
+const (
+	STRING_DELIMITER           = `"`
+	MULTILINE_STRING_DELIMITER = `"""`
+	RAW_STRING_PREFIX          = 'r'
+)
+
 func NewTokenizer(source string) Tokenizer {
 	return Tokenizer{source: source, currentPos: 0, currentLineNumber: 1}
 }
 
+/*
+NewReaderTokenizer creates a tokenizer that reads source code from "reader" as it is needed, so the whole program does
+not have to be in memory. Text is discarded after it is tokenized. Apart from errors from the reader, it returns the
+same tokens and errors as "NewTokenizer" with the same source, except that invalid UTF-8 is reported when the tokenizer
+reaches it rather than before the first token.
+*/
+func NewReaderTokenizer(reader io.Reader) Tokenizer {
+	return Tokenizer{currentLineNumber: 1, encodingChecked: true, reader: reader, readLineNumber: 1}
+}
+
+func (t *Tokenizer) fill(numBytes int) {
+	// Read until there are at least "numBytes" bytes after the current position or the source has been read
+	for t.reader != nil && len(t.source)-t.currentPos < numBytes {
+		t.read()
+	}
+}
+
+func (t *Tokenizer) fillUntil(char byte) {
+	// Read until "char" is after the current position or the source has been read
+	for t.reader != nil && strings.IndexByte(t.source[t.currentPos:], char) == -1 {
+		t.read()
+	}
+}
+
+func (t *Tokenizer) read() {
+	/*
+		Read the next chunk of the source. The chunk size grows with the text that has not been discarded, so reading
+		a long token (e.g., a large multi-line string) takes linear time. Reading stops at the end of the source or the
+		first error.
+	*/
+	chunk := make([]byte, READ_SIZE+len(t.source))
+	numBytes, err := t.reader.Read(chunk)
+	atEnd := err != nil
+
+	// Only complete, valid characters are added to the source. Invalid UTF-8 stops reading.
+	text := t.pending + string(chunk[:numBytes])
+	t.pending = ""
+
+	pos := 0
+	for pos < len(text) {
+		if !atEnd && !utf8.FullRuneInString(text[pos:]) {
+			t.pending = text[pos:]
+			break
+		}
+
+		char, size := utf8.DecodeRuneInString(text[pos:])
+		if char == utf8.RuneError && size == 1 {
+			bytePos := t.offset + len(t.source) + pos
+			t.readErr = utils.CreateError(t.readLineNumber, "invalid UTF-8 encoding at byte %d", bytePos)
+			t.reader = nil
+			break
+		}
+
+		if char == '\n' {
+			t.readLineNumber += 1
+		}
+		pos += size
+	}
+	t.source += text[:pos]
+
+	if err != nil && err != io.EOF && t.readErr == nil {
+		t.readErr = utils.CreateError(t.readLineNumber, "cannot read source: %s", err.Error())
+	}
+	if atEnd {
+		t.reader = nil
+	}
+}
+
+func (t *Tokenizer) discardTokenized() {
+	// Drop text that has already been tokenized so memory use does not grow with the size of the source
+	t.offset += t.currentPos
+	t.source = t.source[t.currentPos:]
+	t.currentPos = 0
+}
+
+func (t *Tokenizer) atEnd() bool {
+	t.fill(1)
+	return t.currentPos >= len(t.source)
+}
+
+func (t *Tokenizer) hasPrefix(prefix string) bool {
+	t.fill(len(prefix))
+	return strings.HasPrefix(t.source[t.currentPos:], prefix)
+}
+
+func (t *Tokenizer) newLine() {
+	// Called when the current character is a newline
+	t.currentLineNumber += 1
+	t.lineStart = t.offset + t.currentPos + 1
+}
+
 func (t *Tokenizer) createToken(tokenType string, literal string) Token {
 	return Token{Type: tokenType, Literal: literal, LineNumber: t.currentLineNumber}
 }
 
 func (t *Tokenizer) current() byte {
+	t.fill(1)
 	if t.currentPos < len(t.source) {
 		return t.source[t.currentPos]
 	}
@@ -30,6 +154,7 @@ func (t *Tokenizer) current() byte {
 }
 
 func (t *Tokenizer) peek() byte {
+	t.fill(2)
 	nextCharIndex := t.currentPos + 1
 	if nextCharIndex < len(t.source) {
 		return t.source[nextCharIndex]
@@ -37,14 +162,38 @@ func (t *Tokenizer) peek() byte {
 	return EOF_CHAR
 }
 
+func (t *Tokenizer) currentRune() (rune, int) {
+	// Decode the (possibly multi-byte) character at the current position. Returns the character and its size in bytes.
+	t.fill(utf8.UTFMax)
+	if t.currentPos < len(t.source) {
+		return utf8.DecodeRuneInString(t.source[t.currentPos:])
+	}
+	return EOF_CHAR, 0
+}
+
 func (t *Tokenizer) advance() {
 	t.currentPos += 1
 }
 
+func (t *Tokenizer) checkEncoding() error {
+	// Source code must be valid UTF-8. Report the line containing the first invalid byte.
+	t.encodingChecked = true
+
+	for pos := 0; pos < len(t.source); {
+		char, size := utf8.DecodeRuneInString(t.source[pos:])
+		if char == utf8.RuneError && size == 1 {
+			lineNum := strings.Count(t.source[:pos], "\n") + 1
+			return utils.CreateError(lineNum, "invalid UTF-8 encoding at byte %d", pos)
+		}
+		pos += size
+	}
+	return nil
+}
+
 func (t *Tokenizer) skipWhitespace() {
 	for t.current() == ' ' || t.current() == '\t' || t.current() == '\n' || t.current() == '\r' {
 		if t.current() == '\n' {
-			t.currentLineNumber += 1
+			t.newLine()
 		}
 		t.advance()
 	}
@@ -64,7 +213,7 @@ func (t *Tokenizer) skipBlockComment() (*Token, error) {
 		}
 
 		if t.current() == '\n' {
-			t.currentLineNumber += 1
+			t.newLine()
 		}
 
 		t.advance()
@@ -72,47 +221,316 @@ func (t *Tokenizer) skipBlockComment() (*Token, error) {
 
 	t.advance()
 	t.advance()
-	return t.Next()
+	return t.next()
 }
 
 func (t *Tokenizer) skipInlineComment() (*Token, error) {
 	for t.current() != '\n' && t.current() != EOF_CHAR {
 		t.advance()
 	}
-	return t.Next()
+	return t.next()
 }
 
 func (t *Tokenizer) isIdentifier(allowDigits bool) bool {
 	/* Identifiers (e.g., variables) can include digits in the name but can't start with digits. When 'allowDigits' is false,
-	 * only letters and underscores are allowed. When 'allowDigits' is true, digits are allowed.
+	 * only letters and underscores are allowed. When 'allowDigits' is true, digits are allowed. Letters and decimal digits
+	 * from any language are allowed (e.g., "café" and "числа"), matching the IDENTIFIER pattern in "tokenData".
 	 */
-	char := t.current()
+	char, _ := t.currentRune()
 
-	isIdentifierWithoutDigits := 'a' <= char && char <= 'z' || 'A' <= char && char <= 'Z' || char == '_'
+	isIdentifierWithoutDigits := unicode.IsLetter(char) || char == '_'
 	if allowDigits {
-		return isIdentifierWithoutDigits || '0' <= char && char <= '9'
+		return isIdentifierWithoutDigits || unicode.IsDigit(char)
 	}
 	return isIdentifierWithoutDigits
 }
 
 func (t *Tokenizer) readIdentifier() string {
 	startPos := t.currentPos
-	endPos := startPos
 	for t.isIdentifier(true) {
-		endPos += 1
+		_, size := t.currentRune()
+		t.currentPos += size
+	}
+	return t.source[startPos:t.currentPos]
+}
+
+func (t *Tokenizer) readString() (*Token, error) {
+	/*
+		Read a string literal. Strings are delimited by either a single double quote ("...") or three double quotes
+		("""..."""). Only strings delimited by three double quotes can span multiple lines. Strings prefixed with "r"
+		are raw strings, where backslashes and curly braces have no special meaning.
+
+		When a string contains an expression between curly braces, only the text before the expression is read, and a
+		STRING_START token is returned. The tokens in the expression are then returned by "Next" like any other tokens,
+		followed by STRING_MIDDLE or STRING_END tokens for the remaining text (see "readInterpolationToken").
+	*/
+	startLineNumber := t.currentLineNumber
+
+	isRaw := t.current() == RAW_STRING_PREFIX
+	if isRaw {
+		t.advance()
+	}
+
+	delimiter := STRING_DELIMITER
+	if t.hasPrefix(MULTILINE_STRING_DELIMITER) {
+		delimiter = MULTILINE_STRING_DELIMITER
+	}
+	t.currentPos += len(delimiter)
+
+	literal, isEnd, err := t.readStringSegment(delimiter, startLineNumber, isRaw)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenType := STRING
+	if isRaw {
+		tokenType = RAW_STRING
+	} else if !isEnd {
+		tokenType = STRING_START
+		t.interpolations = append(t.interpolations, interpolation{delimiter: delimiter, startLineNumber: startLineNumber})
+	}
+
+	return &Token{Type: tokenType, Literal: literal, LineNumber: startLineNumber}, nil
+}
+
+func (t *Tokenizer) readStringSegment(delimiter string, startLineNumber int, isRaw bool) (string, bool, error) {
+	/*
+		Read string text until the end of the string or, for strings that are not raw, the start of an interpolated
+		expression. The delimiter or opening curly brace is skipped over but not included in the returned text. Returns
+		the text and whether the end of the string was reached.
+
+		Escape sequences are checked here so errors report the correct line number, but they are not decoded. The
+		parser decodes them when creating string nodes.
+	*/
+	startPos := t.currentPos
+	for {
+		if t.hasPrefix(delimiter) {
+			literal := t.source[startPos:t.currentPos]
+			t.currentPos += len(delimiter)
+			return literal, true, nil
+		}
+
+		if t.atEnd() {
+			return "", false, utils.CreateError(startLineNumber, "did not find ending %s while parsing string", delimiter)
+		}
+
+		if isRaw {
+			if t.current() == '\n' {
+				if err := t.checkMultilineString(delimiter, startLineNumber); err != nil {
+					return "", false, err
+				}
+			}
+			t.advance()
+			continue
+		}
+
+		switch t.current() {
+		case '\n':
+			if err := t.checkMultilineString(delimiter, startLineNumber); err != nil {
+				return "", false, err
+			}
+			t.advance()
+
+		case '\\':
+			if t.peek() == 'u' {
+				t.fillUntil('}')
+			}
+			_, size, err := ReadEscapeSequence(t.source[t.currentPos:])
+			if err != nil {
+				return "", false, utils.CreateError(t.currentLineNumber, "%s", err.Error())
+			}
+			t.currentPos += size
+
+		case '{':
+			literal := t.source[startPos:t.currentPos]
+			t.advance()
+			return literal, false, nil
+
+		default:
+			t.advance()
+		}
+	}
+}
+
+func (t *Tokenizer) checkMultilineString(delimiter string, startLineNumber int) error {
+	// Called at each newline in a string. Only strings delimited by three double quotes can span multiple lines.
+	if delimiter == STRING_DELIMITER {
+		return utils.CreateError(
+			startLineNumber,
+			"did not find ending %s while parsing string (use %s for multi-line strings)",
+			delimiter,
+			MULTILINE_STRING_DELIMITER,
+		)
+	}
+	t.newLine()
+	return nil
+}
+
+func (t *Tokenizer) readInterpolationToken() (*Token, error) {
+	/*
+		Handle the characters that have special meaning in an interpolated expression. Returns nil if the current
+		character should be tokenized normally.
+
+		A closing curly brace that does not match an opening curly brace in the expression ends the expression, and
+		the string text after it is returned as a STRING_MIDDLE or STRING_END token. A colon outside of any curly braces
+		starts a format specifier, which continues until the end of the expression.
+	*/
+	current := &t.interpolations[len(t.interpolations)-1]
+	lineNumber := t.currentLineNumber
+
+	switch t.current() {
+	case '{':
+		current.braceDepth += 1
+
+	case '}':
+		if current.braceDepth > 0 {
+			current.braceDepth -= 1
+			return nil, nil
+		}
+		t.advance()
+
+		literal, isEnd, err := t.readStringSegment(current.delimiter, current.startLineNumber, false)
+		if err != nil {
+			return nil, err
+		}
+
+		tokenType := STRING_MIDDLE
+		if isEnd {
+			tokenType = STRING_END
+			t.interpolations = t.interpolations[:len(t.interpolations)-1]
+		}
+		return &Token{Type: tokenType, Literal: literal, LineNumber: lineNumber}, nil
+
+	case ':':
+		if current.braceDepth > 0 {
+			return nil, nil
+		}
 		t.advance()
+
+		startPos := t.currentPos
+		for t.current() != '}' {
+			if t.atEnd() || t.current() == '\n' || t.current() == '{' {
+				return nil, utils.CreateError(lineNumber, "did not find ending } for format specifier")
+			}
+			t.advance()
+		}
+		return &Token{Type: FORMAT_SPEC, Literal: t.source[startPos:t.currentPos], LineNumber: lineNumber}, nil
 	}
-	return t.source[startPos:endPos]
+
+	return nil, nil
+}
+
+func ReadEscapeSequence(source string) (string, int, error) {
+	/*
+		Decode the escape sequence at the start of "source", which must start with a backslash. Returns the decoded
+		text and the number of bytes in the escape sequence. Supported escape sequences:
+			\n, \t, \r, \", \\, \{, \}  newline, tab, carriage return, double quote, backslash, and curly braces
+			\u{XXXX}                   Unicode character with the hexadecimal code point XXXX (1 to 6 digits)
+	*/
+	if len(source) < 2 {
+		return "", 0, fmt.Errorf("unfinished escape sequence at end of string")
+	}
+
+	switch source[1] {
+	case 'n':
+		return "\n", 2, nil
+	case 't':
+		return "\t", 2, nil
+	case 'r':
+		return "\r", 2, nil
+	case '"', '\\', '{', '}':
+		return string(source[1]), 2, nil
+	case 'u':
+		end := strings.IndexByte(source, '}')
+		if !strings.HasPrefix(source[2:], "{") || end == -1 {
+			return "", 0, fmt.Errorf("invalid Unicode escape sequence: expected \\u{XXXX}")
+		}
+
+		hexDigits := source[3:end]
+		codePoint, err := strconv.ParseUint(hexDigits, 16, 32)
+		if err != nil || len(hexDigits) > 6 || !utf8.ValidRune(rune(codePoint)) {
+			return "", 0, fmt.Errorf("invalid Unicode code point %#v in escape sequence", hexDigits)
+		}
+		return string(rune(codePoint)), end + 1, nil
+	}
+
+	char, _ := utf8.DecodeRuneInString(source[1:])
+	return "", 0, fmt.Errorf("invalid escape sequence \\%c", char)
 }
 
 func (t *Tokenizer) Next() (*Token, error) {
+	if !t.encodingChecked {
+		if err := t.checkEncoding(); err != nil {
+			return nil, err
+		}
+	}
+
+	if t.readErr != nil {
+		return nil, t.readErr
+	}
+
+	token, err := t.next()
+
+	// Errors from reading the source are returned instead of anything read from the same part of the source
+	if t.readErr != nil {
+		return nil, t.readErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token.Offset = t.tokenStart
+	token.End = t.offset + t.currentPos
+	token.Column = t.tokenStart - t.tokenLineStart + 1
+	return token, nil
+}
+
+// All returns the remaining tokens, ending with the EOF token.
+func (t *Tokenizer) All() ([]Token, error) {
+	allTokens := []Token{}
+	for {
+		token, err := t.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		allTokens = append(allTokens, *token)
+		if token.Type == EOF {
+			return allTokens, nil
+		}
+	}
+}
+
+func (t *Tokenizer) next() (*Token, error) {
+	if t.reader != nil {
+		t.discardTokenized()
+	}
+
 	t.skipWhitespace()
 
-	if t.current() == EOF_CHAR {
+	// Comments are skipped by calling this method again, so the start of the token is set after them
+	t.tokenStart = t.offset + t.currentPos
+	t.tokenLineStart = t.lineStart
+
+	if len(t.interpolations) > 0 && !t.atEnd() {
+		token, err := t.readInterpolationToken()
+		if err != nil || token != nil {
+			return token, err
+		}
+	}
+
+	if t.atEnd() && len(t.interpolations) > 0 {
+		current := t.interpolations[len(t.interpolations)-1]
+		return nil, utils.CreateError(current.startLineNumber, "did not find ending } for string interpolation")
+
+	} else if t.current() == EOF_CHAR {
 		token := EOF_TOKEN
 		token.LineNumber = t.currentLineNumber
 		return &token, nil
 
+	} else if t.current() == '"' || (t.current() == RAW_STRING_PREFIX && t.peek() == '"') {
+		return t.readString()
+
 	} else if t.isIdentifier(false) {
 		literal := t.readIdentifier()
 
@@ -122,92 +540,110 @@ func (t *Tokenizer) Next() (*Token, error) {
 		}
 
 		return &Token{Type: tokenType, Literal: literal, LineNumber: t.currentLineNumber}, nil
-	}
 
-	token, err := t.getMatchingTokens()
-	if err != nil {
-		return nil, err
+	} else if t.isNumber() {
+		return t.readNumber()
 	}
 
-	return token, nil
+	return t.readSymbol()
 }
 
-func (t *Tokenizer) getMatchingTokens() (*Token, error) {
+func isDigit(char byte) bool {
+	return '0' <= char && char <= '9'
+}
+
+func (t *Tokenizer) isNumber() bool {
+	// Numbers start with a digit, or with a decimal point followed by a digit (e.g., ".5")
+	return isDigit(t.current()) || (t.current() == '.' && isDigit(t.peek()))
+}
+
+func (t *Tokenizer) readNumber() (*Token, error) {
 	/*
-		Get list of tokens where the token literal start with current character. The check for
-		literal values having a length of greater-than 0 ensures tokens without an initial value
-		are not matched (like NUMBER and IDENTIFIER).
+		Read a number literal (see "numbers.go" for the formats). Anything that runs into the end of the literal
+		without a space, like the ".3" in "1.2.3" or the "g" in "0xfg", makes the whole literal invalid.
 	*/
-	// var matchingTokens []Token
-	for _, td := range tokenData {
-		source := t.source[t.currentPos:]
-		pattern := fmt.Sprintf("^%s", td.RegexPattern())
+	startPos := t.currentPos
+	lineNumber := t.currentLineNumber
+	isValid := true
 
-		r, err := regexp.Compile(pattern)
-		if err != nil {
-			panic(err.Error())
-		}
+	if base, ok := numberBases[t.peek()]; ok && t.current() == '0' {
+		t.advance()
+		t.advance()
+		isValid = t.readDigits(base)
 
-		matchStart := -1
-		matchEnd := -1
-		literalStart := -1
-		literalEnd := -1
+	} else {
+		if t.current() != '.' {
+			isValid = t.readDigits(10)
+		}
 
-		matchLocations := r.FindStringSubmatchIndex(source)
-		/*
-			When there are no grouped expressions in the regex, "matchLocation" is 2-elements long: the start and end
-			positions in the searched string. However, if grouped expressions are found in the searched string, they
-			will be added to "matchLocations."
+		// A decimal point can be the last character, but the exponent must have digits
+		if t.current() == '.' {
+			t.advance()
+			if isDigit(t.current()) {
+				isValid = t.readDigits(10) && isValid
+			}
+		}
 
-			For example, if the string is '\"hello, world\"' and the regex is '\"(.*)\"', "matchLocations" will be
-			[0, 15, 1, 14].
+		if t.current() == 'e' || t.current() == 'E' {
+			t.advance()
+			if t.current() == '+' || t.current() == '-' {
+				t.advance()
+			}
+			isValid = t.readDigits(10) && isValid
+		}
+	}
 
-			However, if the string is 'true', and the regex is 'true|false', "matchLocations" will be [0, 5].
+	for t.isIdentifier(true) || t.current() == '.' {
+		_, size := t.currentRune()
+		t.currentPos += size
+		isValid = false
+	}
 
-			Note that end indices will be the index of the character after the last matched character. See documentation
-			for more details: https://pkg.go.dev/regexp#Regexp.FindSubmatchIndex
-		*/
-		if len(matchLocations) == 2 {
-			// No grouped expressions in regex
-			matchStart = matchLocations[0]
-			matchEnd = matchLocations[1]
+	literal := t.source[startPos:t.currentPos]
+	if !isValid {
+		return nil, utils.CreateError(lineNumber, "invalid number %s", literal)
+	}
 
-			literalStart = matchLocations[0]
-			literalEnd = matchLocations[1]
-		}
+	if _, err := NumberValue(literal); err != nil {
+		return nil, utils.CreateError(lineNumber, "%s", err.Error())
+	}
 
-		if len(matchLocations) == 4 {
-			// Grouped expressions in regex
-			matchStart = matchLocations[0]
-			matchEnd = matchLocations[1]
+	token := t.createToken(NUMBER, literal)
+	return &token, nil
+}
 
-			literalStart = matchLocations[2]
-			literalEnd = matchLocations[3]
-		}
+func (t *Tokenizer) readDigits(base int) bool {
+	// Read digits in the given base, with underscores only between two digits. Returns false if there are no digits.
+	if !isDigitInBase(t.current(), base) {
+		return false
+	}
 
-		if matchStart != -1 && matchEnd != -1 && literalStart != -1 && literalEnd != -1 {
-			if td.Type == INLINE_COMMENT {
-				return t.skipInlineComment()
-			}
+	for isDigitInBase(t.current(), base) || (t.current() == '_' && isDigitInBase(t.peek(), base)) {
+		t.advance()
+	}
+	return true
+}
 
-			if td.Type == BLOCK_COMMENT {
-				return t.skipBlockComment()
-			}
+func (t *Tokenizer) readSymbol() (*Token, error) {
+	// Symbols starting with the current character are ordered so the longest symbol is matched first (e.g., "==" before "=")
+	for _, symbol := range symbols[t.current()] {
+		if !t.hasPrefix(symbol.Literal) {
+			continue
+		}
 
-			literal := t.source[t.currentPos+literalStart : t.currentPos+literalEnd]
-			token := t.createToken(td.Type, literal)
-			/*
-				To advance past all the characters matching the regex, skip over the number of characters captured
-				by the full regex match. For example, this ensures the double quotes for strings are skipped. However,
-				when creating string tokens, we only want the text between the quotes, which is why the "literalStart"
-				and "literalEnd" are used for token creation.
-			*/
-			for i := 0; i < (matchEnd - matchStart); i++ {
-				t.advance()
-			}
+		if symbol.Type == INLINE_COMMENT {
+			return t.skipInlineComment()
+		}
 
-			return &token, nil
+		if symbol.Type == BLOCK_COMMENT {
+			return t.skipBlockComment()
 		}
+
+		token := t.createToken(symbol.Type, symbol.Literal)
+		t.currentPos += len(symbol.Literal)
+		return &token, nil
 	}
-	return nil, utils.CreateError(t.currentLineNumber, "invalid character %c", t.current())
+
+	char, _ := t.currentRune()
+	return nil, utils.CreateError(t.currentLineNumber, "invalid character %c", char)
 }
@@ -2,27 +2,22 @@ package tokens
 
 import (
 	"fmt"
-	"regexp"
 )
 
 type TokenMetaData struct {
-	Literal     string
-	Type        string
-	IsRegexChar bool // Any characters in Boomerang that are the same as regex characters need to be escaped.
-	IsKeyword   bool
-}
-
-func (tmd *TokenMetaData) RegexPattern() string {
-	if tmd.IsRegexChar {
-		return regexp.QuoteMeta(tmd.Literal)
-	}
-	return tmd.Literal
+	Literal   string
+	Type      string
+	IsKeyword bool
+	IsSymbol  bool // Symbols are matched by the tokenizer using their literal values (see "symbols")
 }
 
 type Token struct {
 	Literal    string
 	Type       string
 	LineNumber int
+	Column     int // Column of the first character of the token, starting at 1. Columns are counted in bytes.
+	Offset     int // Number of bytes in the source before the first character of the token
+	End        int // Offset of the byte after the last character of the token, including delimiters like quotes
 }
 
 func (t *Token) ErrorDisplay() string {
@@ -73,6 +68,23 @@ const (
 	BREAK                = "BREAK"
 	CONTINUE             = "CONTINUE"
 	RETURN               = "RETURN"
+	IMPORT               = "IMPORT"
+	AS                   = "AS"
+	EXPORT               = "EXPORT"
+	PERIOD               = "PERIOD"
+	RAW_STRING           = "RAW_STRING"
+	COLON                = "COLON"
+	ARROW                = "ARROW"
+
+	/*
+		Interpolated strings are split into segments around the expressions between curly braces. For example,
+		"a{x}b{y:>5}c" is tokenized as STRING_START("a"), IDENTIFIER("x"), STRING_MIDDLE("b"), IDENTIFIER("y"),
+		FORMAT_SPEC(">5"), STRING_END("c").
+	*/
+	STRING_START  = "STRING_START"
+	STRING_MIDDLE = "STRING_MIDDLE"
+	STRING_END    = "STRING_END"
+	FORMAT_SPEC   = "FORMAT_SPEC"
 )
 
 // Tokens
@@ -99,6 +111,9 @@ var (
 	EQ_TOKEN                   = getToken(EQ)
 	NE_TOKEN                   = getToken(NE)
 	LT_TOKEN                   = getToken(LT)
+	PERIOD_TOKEN               = getToken(PERIOD)
+	COLON_TOKEN                = getToken(COLON)
+	ARROW_TOKEN                = getToken(ARROW)
 
 	// Keywords
 	FUNCTION_TOKEN = getToken(FUNCTION)
@@ -116,11 +131,15 @@ var (
 	BREAK_TOKEN    = getToken(BREAK)
 	CONTINUE_TOKEN = getToken(CONTINUE)
 	RETURN_TOKEN   = getToken(RETURN)
+	IMPORT_TOKEN   = getToken(IMPORT)
+	AS_TOKEN       = getToken(AS)
+	EXPORT_TOKEN   = getToken(EXPORT)
 
 	// Data Types
-	NUMBER_TOKEN  = getToken(NUMBER)
-	STRING_TOKEN  = getToken(STRING)
-	BOOLEAN_TOKEN = getToken(BOOLEAN)
+	NUMBER_TOKEN     = getToken(NUMBER)
+	STRING_TOKEN     = getToken(STRING)
+	RAW_STRING_TOKEN = getToken(RAW_STRING)
+	BOOLEAN_TOKEN    = getToken(BOOLEAN)
 
 	// Misc
 	IDENTIFIER_TOKEN = getToken(IDENTIFIER)
@@ -128,9 +147,10 @@ var (
 )
 
 var tokenData = []TokenMetaData{
-	// Data types/misc
-	{Type: NUMBER, Literal: "[0-9]*[.]?[0-9]+"},
+	// Data types/misc. The literals are patterns describing these tokens, which are read by the tokenizer.
+	{Type: NUMBER, Literal: `0[xX][0-9a-fA-F](_?[0-9a-fA-F])*|0[oO][0-7](_?[0-7])*|0[bB][01](_?[01])*|([0-9](_?[0-9])*([.]([0-9](_?[0-9])*)?)?|[.][0-9](_?[0-9])*)([eE][+-]?[0-9](_?[0-9])*)?`},
 	{Type: STRING, Literal: "\"(.*?)\""},
+	{Type: RAW_STRING, Literal: "r\"(.*?)\""},
 	{Type: BOOLEAN, Literal: "(true|false)"},
 
 	// Keywords. Need to be defined before "IDENTIFIER" in this list so they are not misclassified
@@ -149,9 +169,12 @@ var tokenData = []TokenMetaData{
 	{Type: BREAK, Literal: "break", IsKeyword: true},
 	{Type: CONTINUE, Literal: "continue", IsKeyword: true},
 	{Type: RETURN, Literal: "return", IsKeyword: true},
+	{Type: IMPORT, Literal: "import", IsKeyword: true},
+	{Type: AS, Literal: "as", IsKeyword: true},
+	{Type: EXPORT, Literal: "export", IsKeyword: true},
 
 	// Identifier
-	{Type: IDENTIFIER, Literal: "[a-zA-Z]+[a-zA-Z0-9_]*"},
+	{Type: IDENTIFIER, Literal: `[\p{L}_][\p{L}\p{Nd}_]*`},
 
 	/*
 		Symbols
@@ -160,31 +183,36 @@ var tokenData = []TokenMetaData{
 		the substring tokens in this list. For example "==" must come before "=" and "##" must come before "#". Otherwise,
 		the tokenizer would match "==" as two "=" tokens.
 	*/
-	{Type: PLUS, Literal: "+", IsRegexChar: true},
-	{Type: MINUS, Literal: "-"},
-	{Type: ASTERISK, Literal: "*", IsRegexChar: true},
-	{Type: FORWARD_SLASH, Literal: "/", IsRegexChar: true},
-	{Type: MODULO, Literal: "%", IsRegexChar: true},
-	{Type: SEMICOLON, Literal: ";"},
-	{Type: OPEN_PAREN, Literal: "(", IsRegexChar: true},
-	{Type: CLOSED_PAREN, Literal: ")", IsRegexChar: true},
-	{Type: SEND, Literal: "<-"},
-	{Type: EQ, Literal: "=="},
-	{Type: NE, Literal: "!="},
-	{Type: LT, Literal: "<"},
-	{Type: ASSIGN, Literal: "="},
-	{Type: COMMA, Literal: ","},
-	{Type: OPEN_CURLY_BRACKET, Literal: "{", IsRegexChar: true},
-	{Type: CLOSED_CURLY_BRACKET, Literal: "}", IsRegexChar: true},
-	{Type: OPEN_BRACKET, Literal: "[", IsRegexChar: true},
-	{Type: CLOSED_BRACKET, Literal: "]", IsRegexChar: true},
-	{Type: AT, Literal: "@"},
-	{Type: BLOCK_COMMENT, Literal: "##"},
-	{Type: INLINE_COMMENT, Literal: "#"},
+	{Type: PLUS, Literal: "+", IsSymbol: true},
+	{Type: ARROW, Literal: "->", IsSymbol: true},
+	{Type: MINUS, Literal: "-", IsSymbol: true},
+	{Type: ASTERISK, Literal: "*", IsSymbol: true},
+	{Type: FORWARD_SLASH, Literal: "/", IsSymbol: true},
+	{Type: MODULO, Literal: "%", IsSymbol: true},
+	{Type: SEMICOLON, Literal: ";", IsSymbol: true},
+	{Type: OPEN_PAREN, Literal: "(", IsSymbol: true},
+	{Type: CLOSED_PAREN, Literal: ")", IsSymbol: true},
+	{Type: SEND, Literal: "<-", IsSymbol: true},
+	{Type: EQ, Literal: "==", IsSymbol: true},
+	{Type: NE, Literal: "!=", IsSymbol: true},
+	{Type: LT, Literal: "<", IsSymbol: true},
+	{Type: ASSIGN, Literal: "=", IsSymbol: true},
+	{Type: COMMA, Literal: ",", IsSymbol: true},
+	{Type: OPEN_CURLY_BRACKET, Literal: "{", IsSymbol: true},
+	{Type: CLOSED_CURLY_BRACKET, Literal: "}", IsSymbol: true},
+	{Type: OPEN_BRACKET, Literal: "[", IsSymbol: true},
+	{Type: CLOSED_BRACKET, Literal: "]", IsSymbol: true},
+	{Type: AT, Literal: "@", IsSymbol: true},
+	{Type: PERIOD, Literal: ".", IsSymbol: true}, // Numbers are read before symbols, so numbers like ".5" are not split
+	{Type: COLON, Literal: ":", IsSymbol: true},  // Colons in interpolated expressions start format specifiers instead
+	{Type: BLOCK_COMMENT, Literal: "##", IsSymbol: true},
+	{Type: INLINE_COMMENT, Literal: "#", IsSymbol: true},
 }
 
 var keywords = getKeywords()
 
+var symbols = getSymbols()
+
 func getKeywords() map[string]string {
 
 	keywordTokens := map[string]string{}
@@ -197,6 +225,19 @@ func getKeywords() map[string]string {
 	return keywordTokens
 }
 
+func getSymbols() map[byte][]TokenMetaData {
+	// Symbols by their first character, in the same order as "tokenData" so longer symbols are matched first
+	symbolTokens := map[byte][]TokenMetaData{}
+
+	for _, tokenDatum := range tokenData {
+		if tokenDatum.IsSymbol {
+			firstChar := tokenDatum.Literal[0]
+			symbolTokens[firstChar] = append(symbolTokens[firstChar], tokenDatum)
+		}
+	}
+	return symbolTokens
+}
+
 func getToken(name string) Token {
 	for _, token := range tokenData {
 		if token.Type == name {
@@ -0,0 +1,78 @@
+package typechecker
+
+import (
+	"boomerang/evaluator"
+	"boomerang/node"
+)
+
+// Types of the values returned by builtin functions, for builtins whose result does not depend on their arguments
+var builtinReturnTypes = map[string]*Type{
+	evaluator.BUILTIN_LEN:     numberType,
+	evaluator.BUILTIN_RANGE:   listOf(numberType),
+	evaluator.BUILTIN_PRINT:   monadType,
+	evaluator.BUILTIN_INPUT:   stringType,
+	evaluator.BUILTIN_SUCCESS: booleanType,
+
+	evaluator.BUILTIN_STRINGS + "." + evaluator.STRINGS_TO_STRING:   stringType,
+	evaluator.BUILTIN_STRINGS + "." + evaluator.STRINGS_TO_NUMBER:   monadOf(numberType),
+	evaluator.BUILTIN_STRINGS + "." + evaluator.STRINGS_INDEX_OF:    monadOf(numberType),
+	evaluator.BUILTIN_STRINGS + "." + evaluator.STRINGS_SPLIT:       listOf(stringType),
+	evaluator.BUILTIN_STRINGS + "." + evaluator.STRINGS_JOIN:        stringType,
+	evaluator.BUILTIN_STRINGS + "." + evaluator.STRINGS_TRIM:        stringType,
+	evaluator.BUILTIN_STRINGS + "." + evaluator.STRINGS_UPPER:       stringType,
+	evaluator.BUILTIN_STRINGS + "." + evaluator.STRINGS_LOWER:       stringType,
+	evaluator.BUILTIN_STRINGS + "." + evaluator.STRINGS_CONTAINS:    booleanType,
+	evaluator.BUILTIN_STRINGS + "." + evaluator.STRINGS_STARTS_WITH: booleanType,
+	evaluator.BUILTIN_STRINGS + "." + evaluator.STRINGS_ENDS_WITH:   booleanType,
+
+	evaluator.BUILTIN_LISTS + "." + evaluator.LISTS_SUM: numberType,
+}
+
+var builtinVariableTypes = map[string]*Type{
+	evaluator.BUILTIN_PI: numberType,
+}
+
+func (c *checker) checkBuiltinCall(lineNum int, name string, arguments []*Type) *Type {
+	// "arguments" is nil if the arguments are not known (e.g., "f <- values" instead of "f <- (a, b)")
+	switch name {
+	case evaluator.BUILTIN_UNWRAP:
+		if len(arguments) != 2 {
+			break
+		}
+
+		monad, defaultValue := arguments[0], arguments[1]
+		if !compatible(monad, monadType) {
+			c.report(lineNum, MISMATCH, "expected %s, got %s", node.MONAD, monad)
+		} else if !isAny(monad) {
+			return join(monad.Element, defaultValue)
+		}
+
+	case evaluator.BUILTIN_UNWRAP_ALL:
+		if len(arguments) != 2 {
+			break
+		}
+
+		list, defaultValue := arguments[0], arguments[1]
+		if !compatible(list, listType) {
+			c.report(lineNum, MISMATCH, "expected %s, got %s", node.LIST, list)
+		} else if !isAny(list) && !isAny(list.Element) && list.Element.Name == node.MONAD {
+			return listOf(join(list.Element.Element, defaultValue))
+		}
+		return listType
+
+	case evaluator.BUILTIN_LEN:
+		if len(arguments) != 1 {
+			break
+		}
+
+		value := arguments[0]
+		if !compatible(value, listType) && !compatible(value, stringType) && !compatible(value, mapType) {
+			c.report(lineNum, MISMATCH, "type %s does not have a length", value)
+		}
+	}
+
+	if t, ok := builtinReturnTypes[name]; ok {
+		return t
+	}
+	return anyType
+}
@@ -0,0 +1,599 @@
+package typechecker
+
+import (
+	"boomerang/ast"
+	"boomerang/evaluator"
+	"boomerang/node"
+	"boomerang/resolver"
+	"boomerang/tokens"
+	"fmt"
+	"sort"
+)
+
+/*
+The type checker reports type errors (e.g., adding a number and a string) before a program runs. Types are optional:
+assignments, function parameters and function return types can have type annotations, and the types of other values
+are inferred from the values assigned to them. For example:
+
+	func add(a: Number, b: Number) -> Number {
+		return a + b;
+	};
+	total: Number = unwrap <- (add <- (1, 2), 0);
+
+The program and each function body have a scope, like in the evaluator. A variable with an annotation can only be
+assigned values of that type in its scope. The type of a variable without an annotation is the type of the values
+assigned to it, or Any if they have different types. The evaluator scopes names dynamically: when a function is
+called, names it does not assign are found in the scope of its caller, which can have a variable with the same name
+and a different type. So in a function, variables from the scopes around it are Any unless they have an annotation.
+
+Function bodies are checked where the functions are defined, and calls to a function return a monad with the type of
+the values in its "return" statements. Named functions can be called before they are defined, but their return type
+is only known if it is annotated.
+*/
+
+const (
+	// Diagnostic codes
+	MISMATCH           = "type-mismatch"
+	UNKNOWN_TYPE       = "unknown-type"
+	INVALID_ANNOTATION = "invalid-annotation"
+)
+
+type Result struct {
+	Diagnostics []resolver.Diagnostic // Sorted by line number. Every diagnostic is an error.
+	Types       map[ast.Expression]*Type
+}
+
+// HasErrors returns true if any type errors were found.
+func (r *Result) HasErrors() bool {
+	return len(r.Diagnostics) > 0
+}
+
+type binding struct {
+	t        *Type
+	declared bool // Whether the type comes from an annotation, so every value assigned to the name must have that type
+}
+
+type scope struct {
+	parent      *scope
+	bindings    map[string]*binding
+	function    *Signature // Function the scope belongs to; nil for the global scope
+	returnTypes []*Type    // Types of the values returned by the function
+}
+
+func newScope(parent *scope, function *Signature) *scope {
+	return &scope{parent: parent, bindings: map[string]*binding{}, function: function}
+}
+
+type checker struct {
+	scope     *scope
+	result    *Result
+	functions map[*ast.Function]*Type // Types of the functions that have been found, so each function has one type
+}
+
+// Check finds the type errors in a program.
+func Check(statements []ast.Statement) *Result {
+	c := &checker{
+		scope:     newScope(nil, nil),
+		result:    &Result{Diagnostics: []resolver.Diagnostic{}, Types: map[ast.Expression]*Type{}},
+		functions: map[*ast.Function]*Type{},
+	}
+	c.checkStatements(statements)
+
+	sort.SliceStable(c.result.Diagnostics, func(i, j int) bool {
+		return c.result.Diagnostics[i].LineNum < c.result.Diagnostics[j].LineNum
+	})
+	return c.result
+}
+
+func (c *checker) report(lineNum int, code, message string, values ...any) {
+	c.result.Diagnostics = append(c.result.Diagnostics, resolver.Diagnostic{
+		LineNum:  lineNum,
+		Severity: resolver.ERROR,
+		Code:     code,
+		Message:  fmt.Sprintf(message, values...),
+	})
+}
+
+func (c *checker) lookup(name string) *Type {
+	for s := c.scope; s != nil; s = s.parent {
+		if b, ok := s.bindings[name]; ok {
+			/*
+				Functions find names in the scope of their caller when they run, so a name from an outer scope can be
+				a different variable with any type. Only annotated variables keep their type.
+			*/
+			if s == c.scope || b.declared {
+				return b.t
+			}
+			return anyType
+		}
+	}
+	// Undefined names are reported by the resolver
+	return anyType
+}
+
+func (c *checker) assign(identifier *ast.Identifier, t *Type) {
+	b, ok := c.scope.bindings[identifier.Name]
+	if !ok {
+		c.scope.bindings[identifier.Name] = &binding{t: t}
+		return
+	}
+
+	if !b.declared {
+		b.t = join(b.t, t)
+	} else if !compatible(b.t, t) {
+		c.report(identifier.LineNum, MISMATCH, "cannot assign %s to variable %#v of type %s", t, identifier.Name, b.t)
+	}
+}
+
+func (c *checker) declare(identifier *ast.Identifier, t *Type) {
+	if b, ok := c.scope.bindings[identifier.Name]; ok && b.declared && b.t.String() != t.String() {
+		c.report(identifier.LineNum, MISMATCH, "variable %#v is already declared with type %s", identifier.Name, b.t)
+		return
+	}
+	c.scope.bindings[identifier.Name] = &binding{t: t, declared: true}
+}
+
+func (c *checker) functionType(function *ast.Function) *Type {
+	if t, ok := c.functions[function]; ok {
+		return t
+	}
+
+	signature := &Signature{Parameters: []Parameter{}}
+	for _, parameter := range function.Parameters {
+		signature.Parameters = append(signature.Parameters, Parameter{
+			Name:       parameter.Name.Name,
+			Type:       c.typeFromAnnotation(parameter.Type),
+			HasDefault: parameter.Default != nil,
+		})
+	}
+
+	if function.ReturnType != nil {
+		signature.ReturnType = c.typeFromAnnotation(function.ReturnType)
+		signature.declared = true
+	}
+
+	t := &Type{Name: node.FUNCTION, Signature: signature}
+	c.functions[function] = t
+	return t
+}
+
+func (c *checker) declareFunctions(statements []ast.Statement) {
+	// Named functions are declared before the other statements in their block (see "declareFunctions" in the evaluator)
+	for _, statement := range statements {
+		if export, ok := statement.(*ast.Export); ok {
+			statement = export.Statement
+		}
+
+		if function, ok := statement.(*ast.Function); ok && function.Name != "" {
+			c.assign(&ast.Identifier{Position: function.Position, Name: function.Name}, c.functionType(function))
+		}
+	}
+}
+
+func (c *checker) checkStatements(statements []ast.Statement) *Type {
+	// Returns the type of the last statement, or nil if it is not an expression
+	c.declareFunctions(statements)
+
+	var last *Type
+	for _, statement := range statements {
+		last = c.checkStatement(statement)
+	}
+	return last
+}
+
+func (c *checker) checkBlock(block *ast.BlockStatements) *Type {
+	// Blocks return a monad with the value of their last statement (see "evaluateBlockStatements" in the evaluator)
+	return monadOf(c.checkStatements(block.Statements))
+}
+
+func (c *checker) checkStatement(statement ast.Statement) *Type {
+	switch statement := statement.(type) {
+	case *ast.WhileLoop:
+		c.checkExpression(statement.Condition)
+		c.checkBlock(statement.Body)
+
+	case *ast.Return:
+		c.checkReturn(statement)
+
+	case *ast.Import:
+		c.assign(statement.Alias, moduleType)
+
+	case *ast.Export:
+		return c.checkStatement(statement.Statement)
+
+	case *ast.Break, *ast.Continue:
+
+	case ast.Expression:
+		return c.checkExpression(statement)
+
+	default:
+		panic(fmt.Sprintf("invalid statement: %T", statement))
+	}
+	return nil
+}
+
+func (c *checker) checkReturn(statement *ast.Return) {
+	t := c.checkExpression(statement.Value)
+
+	signature := c.scope.function
+	if signature == nil {
+		return
+	}
+
+	if !signature.declared {
+		c.scope.returnTypes = append(c.scope.returnTypes, t)
+	} else if !compatible(signature.ReturnType, t) {
+		c.report(statement.LineNum, MISMATCH, "cannot return %s from function with return type %s", t, signature.ReturnType)
+	}
+}
+
+func (c *checker) checkExpression(expression ast.Expression) *Type {
+	t := c.expressionType(expression)
+	c.result.Types[expression] = t
+	return t
+}
+
+func (c *checker) expressionType(expression ast.Expression) *Type {
+	switch expression := expression.(type) {
+	case *ast.Number:
+		return numberType
+
+	case *ast.Boolean:
+		return booleanType
+
+	case *ast.String:
+		return stringType
+
+	case *ast.InterpolatedString:
+		for _, part := range expression.Parts {
+			if interpolation, ok := part.(*ast.Interpolation); ok {
+				c.checkExpression(interpolation.Expression)
+			}
+		}
+		return stringType
+
+	case *ast.Identifier:
+		return c.lookup(expression.Name)
+
+	case *ast.BuiltinVariable:
+		if t, ok := builtinVariableTypes[expression.Name]; ok {
+			return t
+		}
+		return anyType
+
+	case *ast.BuiltinFunction:
+		return &Type{Name: node.FUNCTION, Builtin: expression.Name}
+
+	case *ast.List:
+		elements := []*Type{}
+		for _, element := range expression.Elements {
+			elements = append(elements, c.checkExpression(element))
+		}
+		return listOf(joinAll(elements))
+
+	case *ast.UnaryExpression:
+		return c.checkUnaryExpression(expression)
+
+	case *ast.BinaryExpression:
+		return c.checkBinaryExpression(expression)
+
+	case *ast.Assignment:
+		return c.checkAssignment(expression)
+
+	case *ast.Function:
+		return c.checkFunction(expression)
+
+	case *ast.FunctionCall:
+		function := c.checkExpression(expression.Function)
+		arguments := []*Type{}
+		for _, argument := range expression.Arguments {
+			arguments = append(arguments, c.checkExpression(argument))
+		}
+		return c.checkCall(expression.LineNum, function, arguments)
+
+	case *ast.When:
+		c.checkExpression(expression.Value)
+		results := []*Type{}
+		for _, whenCase := range expression.Cases {
+			c.checkExpression(whenCase.Value)
+			results = append(results, c.checkBlock(whenCase.Body))
+		}
+		results = append(results, c.checkBlock(expression.Else))
+		return joinAll(results)
+
+	case *ast.ForLoop:
+		return c.checkForLoop(expression)
+
+	default:
+		panic(fmt.Sprintf("invalid expression: %T", expression))
+	}
+}
+
+func (c *checker) checkUnaryExpression(expression *ast.UnaryExpression) *Type {
+	t := c.checkExpression(expression.Expression)
+
+	if expression.Operator.Type == tokens.NOT {
+		if !compatible(t, booleanType) {
+			c.report(expression.LineNum, MISMATCH, "invalid type for bang operator: %s", t)
+		}
+		return booleanType
+	}
+
+	if !compatible(t, numberType) {
+		c.report(expression.LineNum, MISMATCH, "invalid type for minus operator: %s", t)
+	}
+	return numberType
+}
+
+func (c *checker) checkBinaryExpression(expression *ast.BinaryExpression) *Type {
+	lineNum := expression.LineNum
+	left := c.checkExpression(expression.Left)
+
+	// The right side of member access is a name, not an expression, so it is not checked
+	if expression.Operator.Type == tokens.PERIOD {
+		member := expression.Right.(*ast.Identifier).Name
+		if !compatible(left, moduleType) {
+			c.report(lineNum, MISMATCH, "cannot access member %#v on type %s", member, left)
+		}
+
+		// Functions in builtin modules (e.g., "lists.sum") are builtins, so their return types can be known
+		if module, ok := expression.Left.(*ast.BuiltinVariable); ok {
+			name := module.Name + "." + member
+			if evaluator.IsBuiltinOfType(node.BUILTIN_FUNCTION, name) {
+				return &Type{Name: node.FUNCTION, Builtin: name}
+			}
+		}
+		return anyType
+	}
+
+	right := c.checkExpression(expression.Right)
+
+	switch expression.Operator.Type {
+	case tokens.PLUS:
+		// Numbers and strings can be added
+		for _, t := range []*Type{numberType, stringType} {
+			if compatible(left, t) && compatible(right, t) {
+				if isAny(left) && isAny(right) {
+					return anyType
+				}
+				return t
+			}
+		}
+		c.report(lineNum, MISMATCH, "cannot add types %s and %s", left, right)
+		return anyType
+
+	case tokens.MINUS:
+		return c.checkOperands(lineNum, left, right, numberType, numberType, "cannot subtract types %s and %s")
+
+	case tokens.ASTERISK:
+		return c.checkOperands(lineNum, left, right, numberType, numberType, "cannot multiply types %s and %s")
+
+	case tokens.FORWARD_SLASH:
+		return c.checkOperands(lineNum, left, right, numberType, numberType, "cannot divide types %s and %s")
+
+	case tokens.MODULO:
+		return c.checkOperands(lineNum, left, right, numberType, numberType, "cannot use modulus operator on types %s and %s")
+
+	case tokens.LT:
+		return c.checkOperands(lineNum, left, right, numberType, booleanType, "invalid types for less than: %s and %s")
+
+	case tokens.AND:
+		return c.checkOperands(lineNum, left, right, booleanType, booleanType, "invalid types for boolean and. left: %s, right: %s")
+
+	case tokens.OR:
+		return c.checkOperands(lineNum, left, right, booleanType, booleanType, "invalid types for boolean or. left: %s, right: %s")
+
+	case tokens.IN:
+		if !compatible(right, listType) {
+			c.report(lineNum, MISMATCH, "right side of \"in\" must be a list. Actual type: %s", right)
+		}
+		return booleanType
+
+	case tokens.AT:
+		return c.checkIndex(lineNum, left, right)
+
+	case tokens.SEND:
+		return c.checkSend(expression, left, right)
+	}
+
+	// "==" and "!=" can compare values of any type
+	return booleanType
+}
+
+func (c *checker) checkOperands(lineNum int, left, right, operand, result *Type, message string) *Type {
+	if !compatible(left, operand) || !compatible(right, operand) {
+		c.report(lineNum, MISMATCH, message, left, right)
+	}
+	return result
+}
+
+func (c *checker) checkIndex(lineNum int, left, right *Type) *Type {
+	// Lists and strings are indexed by number, and maps are indexed by string
+	switch {
+	case isAny(left):
+		return anyType
+
+	case left.Name == node.LIST && compatible(right, numberType):
+		if left.Element == nil {
+			return anyType
+		}
+		return left.Element
+
+	case left.Name == node.STRING && compatible(right, numberType):
+		return stringType
+
+	case left.Name == node.MAP && compatible(right, stringType):
+		return anyType
+	}
+
+	c.report(lineNum, MISMATCH, "invalid types for index: %s and %s", left, right)
+	return anyType
+}
+
+func (c *checker) checkSend(expression *ast.BinaryExpression, left, right *Type) *Type {
+	// "<-" calls functions with a list of arguments, and adds values to lists
+	switch {
+	case isAny(left):
+		return anyType
+
+	case left.Name == node.LIST:
+		if !isAny(right) && right.Name == node.LIST {
+			return listOf(join(left.Element, right.Element))
+		}
+		return listOf(join(left.Element, right))
+
+	case left.Name == node.FUNCTION && compatible(right, listType):
+		// The types of the arguments are only known if they are in a list literal
+		var arguments []*Type
+		if list, ok := expression.Right.(*ast.List); ok {
+			arguments = []*Type{}
+			for _, element := range list.Elements {
+				arguments = append(arguments, c.result.Types[element])
+			}
+		}
+		return c.checkCall(expression.LineNum, left, arguments)
+	}
+
+	c.report(expression.LineNum, MISMATCH, "cannot use send on types %s and %s", left, right)
+	return anyType
+}
+
+func (c *checker) checkCall(lineNum int, function *Type, arguments []*Type) *Type {
+	// "arguments" is nil if the arguments are not known
+	if !compatible(function, &Type{Name: node.FUNCTION}) {
+		c.report(lineNum, MISMATCH, "cannot make function call on type %s", function)
+		return anyType
+	}
+
+	if isAny(function) {
+		return anyType
+	}
+
+	if function.Builtin != "" {
+		return c.checkBuiltinCall(lineNum, function.Builtin, arguments)
+	}
+
+	signature := function.Signature
+	if signature == nil {
+		return anyType
+	}
+
+	if arguments != nil {
+		if len(arguments) > len(signature.Parameters) {
+			c.report(lineNum, MISMATCH, "expected %d arguments, got %d", len(signature.Parameters), len(arguments))
+		}
+
+		for i, parameter := range signature.Parameters {
+			if i >= len(arguments) {
+				if !parameter.HasDefault {
+					c.report(lineNum, MISMATCH, "parameter %#v does not have a value", parameter.Name)
+				}
+				continue
+			}
+
+			if !compatible(parameter.Type, arguments[i]) {
+				c.report(lineNum, MISMATCH, "cannot pass %s to parameter %#v of type %s", arguments[i], parameter.Name, parameter.Type)
+			}
+		}
+	}
+
+	// Functions always return a monad, which is empty if the function does not return a value
+	return monadOf(signature.ReturnType)
+}
+
+func (c *checker) checkAssignment(assignment *ast.Assignment) *Type {
+	value := c.checkExpression(assignment.Value)
+
+	if assignment.Type != nil {
+		declared := c.typeFromAnnotation(assignment.Type)
+		identifier, ok := assignment.Target.(*ast.Identifier)
+		if !ok {
+			// The parser only allows annotations on variables, but trees can also be built without it
+			c.report(assignment.LineNum, INVALID_ANNOTATION, "type annotations can only be used on variables")
+			c.assignTargets(assignment.Target, value, assignment.Value)
+			return value
+		}
+
+		if !compatible(declared, value) {
+			c.report(assignment.LineNum, MISMATCH, "cannot assign %s to variable %#v of type %s", value, identifier.Name, declared)
+		}
+		c.declare(identifier, declared)
+		return value
+	}
+
+	c.assignTargets(assignment.Target, value, assignment.Value)
+	return value
+}
+
+func (c *checker) assignTargets(target ast.Expression, t *Type, value ast.Expression) {
+	// "value" is the expression assigned to the target, if it is known
+	switch target := target.(type) {
+	case *ast.Identifier:
+		c.assign(target, t)
+
+	case *ast.List:
+		// The types of the values are only known when the same number of values are assigned from a list literal
+		values, ok := value.(*ast.List)
+		if !ok || len(values.Elements) != len(target.Elements) {
+			values = nil
+		}
+
+		for i, element := range target.Elements {
+			if values != nil {
+				c.assignTargets(element, c.result.Types[values.Elements[i]], values.Elements[i])
+			} else {
+				c.assignTargets(element, anyType, nil)
+			}
+		}
+	}
+}
+
+func (c *checker) checkForLoop(forLoop *ast.ForLoop) *Type {
+	values := c.checkExpression(forLoop.Values)
+
+	element := anyType
+	if !compatible(values, listType) {
+		c.report(forLoop.LineNum, MISMATCH, "invalid type for for loop: %s", values)
+	} else if !isAny(values) && values.Element != nil {
+		element = values.Element
+	}
+
+	c.assignTargets(forLoop.Variables, element, nil)
+
+	// For-loops return a list with the value of each iteration
+	return listOf(c.checkBlock(forLoop.Body))
+}
+
+func (c *checker) checkFunction(function *ast.Function) *Type {
+	t := c.functionType(function)
+	signature := t.Signature
+
+	parent := c.scope
+	c.scope = newScope(parent, signature)
+
+	// Named functions can always call themselves
+	if function.Name != "" {
+		c.scope.bindings[function.Name] = &binding{t: t, declared: true}
+	}
+
+	// Default values are evaluated in the function's scope, after the parameters before them are assigned
+	for i, parameter := range function.Parameters {
+		parameterType := signature.Parameters[i].Type
+		if parameter.Default != nil {
+			defaultType := c.checkExpression(parameter.Default)
+			if !compatible(parameterType, defaultType) {
+				c.report(parameter.LineNum, MISMATCH, "cannot use %s as the default value of parameter %#v of type %s", defaultType, parameter.Name.Name, parameterType)
+			}
+		}
+
+		c.scope.bindings[parameter.Name.Name] = &binding{t: parameterType, declared: parameter.Type != nil}
+	}
+
+	c.checkBlock(function.Body)
+	if !signature.declared {
+		signature.ReturnType = joinAll(c.scope.returnTypes)
+	}
+
+	c.scope = parent
+	return t
+}
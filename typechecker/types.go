@@ -0,0 +1,161 @@
+package typechecker
+
+import (
+	"boomerang/ast"
+	"boomerang/node"
+	"fmt"
+)
+
+/*
+Types have the same names as the values the evaluator creates (e.g., "Number" and "List"), plus "Any" for values whose
+type is not known. Any is compatible with every type, so code without annotations is only reported when a mistake is
+certain.
+
+Lists and monads can have the type of their values (e.g., "List[Number]" and "Monad[String]"). Functions created in the
+program have a signature with the types of their parameters and the type of the value in the monad they return.
+*/
+
+const ANY = "Any"
+
+// Types that can be used in annotations
+var annotationTypes = []string{
+	ANY,
+	node.NUMBER,
+	node.STRING,
+	node.BOOLEAN,
+	node.LIST,
+	node.MONAD,
+	node.MAP,
+	node.FUNCTION,
+	node.MODULE,
+}
+
+type Type struct {
+	Name      string
+	Element   *Type      // Type of the values in a list or monad; nil if it is not known
+	Signature *Signature // Parameters and return type of a function; nil if they are not known
+	Builtin   string     // Name of a builtin function
+}
+
+type Parameter struct {
+	Name       string
+	Type       *Type
+	HasDefault bool
+}
+
+type Signature struct {
+	Parameters []Parameter
+	ReturnType *Type // Type of the value in the monad the function returns; nil if it is not known
+	declared   bool  // Whether the return type comes from an annotation
+}
+
+var (
+	anyType     = &Type{Name: ANY}
+	numberType  = &Type{Name: node.NUMBER}
+	stringType  = &Type{Name: node.STRING}
+	booleanType = &Type{Name: node.BOOLEAN}
+	listType    = &Type{Name: node.LIST}
+	monadType   = &Type{Name: node.MONAD}
+	mapType     = &Type{Name: node.MAP}
+	moduleType  = &Type{Name: node.MODULE}
+)
+
+func (t *Type) String() string {
+	if isAny(t) {
+		return ANY
+	}
+
+	if (t.Name == node.LIST || t.Name == node.MONAD) && !isAny(t.Element) {
+		return fmt.Sprintf("%s[%s]", t.Name, t.Element.String())
+	}
+	return t.Name
+}
+
+func isAny(t *Type) bool {
+	return t == nil || t.Name == ANY
+}
+
+func listOf(element *Type) *Type {
+	return &Type{Name: node.LIST, Element: element}
+}
+
+func monadOf(value *Type) *Type {
+	// Monads are not put in other monads (see "node.CreateMonad")
+	if value != nil && value.Name == node.MONAD {
+		return value
+	}
+	return &Type{Name: node.MONAD, Element: value}
+}
+
+// compatible returns true if a value of one type can be used as the other type.
+func compatible(a, b *Type) bool {
+	if isAny(a) || isAny(b) {
+		return true
+	}
+
+	if a.Name != b.Name {
+		return false
+	}
+
+	if a.Name == node.LIST || a.Name == node.MONAD {
+		return compatible(a.Element, b.Element)
+	}
+	return true
+}
+
+// join returns the type of a value that can have either type (e.g., a variable assigned in two places).
+func join(a, b *Type) *Type {
+	if isAny(a) || isAny(b) || a.Name != b.Name {
+		return anyType
+	}
+
+	switch a.Name {
+	case node.LIST, node.MONAD:
+		return &Type{Name: a.Name, Element: join(a.Element, b.Element)}
+
+	case node.FUNCTION:
+		if a.Signature != b.Signature || a.Builtin != b.Builtin {
+			return &Type{Name: node.FUNCTION}
+		}
+	}
+	return a
+}
+
+func joinAll(types []*Type) *Type {
+	// Returns nil for an empty list, because the type is not known
+	var joined *Type
+	for i, t := range types {
+		if i == 0 {
+			joined = t
+		} else {
+			joined = join(joined, t)
+		}
+	}
+	return joined
+}
+
+func (c *checker) typeFromAnnotation(annotation *ast.TypeAnnotation) *Type {
+	// Annotations with errors are reported, and are treated as "Any" so they do not cause other errors
+	if annotation == nil {
+		return anyType
+	}
+
+	found := false
+	for _, name := range annotationTypes {
+		found = found || name == annotation.Name
+	}
+	if !found {
+		c.report(annotation.LineNum, UNKNOWN_TYPE, "unknown type %#v", annotation.Name)
+		return anyType
+	}
+
+	t := &Type{Name: annotation.Name}
+	if annotation.Element != nil {
+		if t.Name != node.LIST && t.Name != node.MONAD {
+			c.report(annotation.LineNum, UNKNOWN_TYPE, "type %s does not have an element type", t.Name)
+			return t
+		}
+		t.Element = c.typeFromAnnotation(annotation.Element)
+	}
+	return t
+}